@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+func HandleGetAudienceLists(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	lists, err := services.SendAudienceLists(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get audience lists")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(lists); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleGetAudienceList(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	listID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	list, err := services.SendAudienceList(db, userID, listID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(list); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleCreateAudienceList(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	name := r.FormValue("name")
+	if strings.TrimSpace(name) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing name")
+		return
+	}
+
+	listID, err := services.CreateAudienceList(db, userID, name, r.Form["users"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(map[string]string{"id": listID}); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleRenameAudienceList(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	listID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	err = services.RenameAudienceList(db, userID, listID, r.FormValue("name"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Audience list renamed")
+}
+
+func HandleDeleteAudienceList(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	listID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	err = services.DeleteAudienceList(db, userID, listID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Audience list deleted")
+}
+
+func HandleAddAudienceMembers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	listID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	if err = r.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	err = services.AddAudienceMembers(db, userID, listID, r.Form["users"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Members added")
+}
+
+func HandleRemoveAudienceMember(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	listID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	user := r.URL.Query().Get("user")
+	if strings.TrimSpace(user) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing user")
+		return
+	}
+
+	err = services.RemoveAudienceMember(db, userID, listID, user)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Member removed")
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+)
+
+func HandleBlockUser(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	targetID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	err = services.BlockUser(db, userID, targetID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "User blocked")
+}
+
+func HandleUnblockUser(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	targetID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	err = services.UnblockUser(db, userID, targetID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "User unblocked")
+}
+
+func HandleGetBlockedUsers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	users, err := services.SendBlockedUsers(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get blocked users")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(users); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
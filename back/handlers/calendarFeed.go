@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+func writeICS(w http.ResponseWriter, filename string, data []byte) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(data)
+}
+
+// HandleGroupEventsICS exporte les événements du groupe {id} au format iCalendar.
+func HandleGroupEventsICS(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	data, err := services.GroupEventsICS(db, userId, r.PathValue("id"))
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, "user is not member of group")
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeICS(w, "events.ics", data)
+}
+
+// HandleCalendarFeed sert le flux personnel /api/calendar/{token}.ics : le jeton tient lieu de session,
+// pour les applications d'agenda qui s'y abonnent.
+func HandleCalendarFeed(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	token, ok := strings.CutSuffix(r.PathValue("file"), ".ics")
+	if !ok || token == "" {
+		utils.ErrorResponse(w, http.StatusNotFound, "calendar feed not found")
+		return
+	}
+
+	data, err := services.UserCalendarICS(db, token)
+	if err == services.ErrCalendarTokenNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeICS(w, "calendar.ics", data)
+}
+
+// HandleCalendarToken : GET renvoie l'adresse du flux personnel, POST la régénère (l'ancienne ne marche plus).
+func HandleCalendarToken(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var token string
+	var err error
+	if r.Method == http.MethodPost {
+		token, err = services.RotateCalendarFeedToken(db, userId)
+	} else {
+		token, err = services.CalendarFeedToken(db, userId)
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	data := map[string]string{"token": token, "url": "/api/calendar/" + token + ".ics"}
+	if err = json.NewEncoder(w).Encode(data); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
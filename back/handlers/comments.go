@@ -22,6 +22,11 @@ func HandleCreateComment(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	}
 
 	content := r.FormValue("content")
+	flags, err := services.NewContentFlags(r.FormValue("content_warning"), r.FormValue("sensitive"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var uuidAvatar string
 	file, image, err := r.FormFile("image")
@@ -43,9 +48,9 @@ func HandleCreateComment(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	err = services.CreateComment(userID, postId, content, uuidAvatar, db)
+	err = services.CreateComment(userID, postId, content, uuidAvatar, flags, db)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusBadRequest, "Post not found")
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -124,6 +129,11 @@ func HandleUpdateComment(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 	content := r.FormValue("content")
+	flags, err := services.NewContentFlags(r.FormValue("content_warning"), r.FormValue("sensitive"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var uuidAvatar string
 	file, image, err := r.FormFile("image")
@@ -145,7 +155,7 @@ func HandleUpdateComment(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	err = services.UpdateComment(db, commentId, content, userID, uuidAvatar)
+	err = services.UpdateComment(db, commentId, content, userID, uuidAvatar, flags)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Comment not found")
 		return
@@ -153,3 +163,31 @@ func HandleUpdateComment(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 
 	utils.SuccessResponse(w, http.StatusOK, "Update comment successfully")
 }
+
+func HandleHideComment(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	commentId, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	hidden := r.FormValue("hidden")
+	if hidden != "true" && hidden != "false" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "hidden must be true or false")
+		return
+	}
+
+	err = services.HideComment(db, userID, commentId, hidden == "true")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Comment visibility updated")
+}
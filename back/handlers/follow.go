@@ -3,10 +3,12 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"social-network/services"
 	"social-network/utils"
+	"strconv"
 	"strings"
 )
 
@@ -99,8 +101,37 @@ func HandleDeclineFollow(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 }
 
 type FollowerResponse struct {
-	Status    string                     `json:"status"`
-	Followers []services.ListOfFollowers `json:"followers"`
+	Status     string                     `json:"status"`
+	Followers  []services.ListOfFollowers `json:"followers"`
+	NextCursor string                     `json:"next_cursor"`
+}
+
+// followListOptions lit les paramètres de pagination et de recherche (limit, cursor, search).
+// Sans limit ni cursor, la liste est renvoyée en entier, comme avant la pagination.
+func followListOptions(r *http.Request) (services.FollowListOptions, error) {
+	opts := services.FollowListOptions{
+		Search: r.URL.Query().Get("search"),
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+	if opts.Cursor != "" {
+		opts.Limit = services.DefaultFollowListLimit
+	}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit <= 0 {
+			return opts, errors.New("Invalid limit")
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+func followListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrPrivateFollowList) {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 }
 
 func HandleListFollowers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
@@ -116,17 +147,22 @@ func HandleListFollowers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		user = userID
 	}
 
-	var followers []services.ListOfFollowers
-
-	followers, err := services.SendListFollower(db, user, userID)
+	opts, err := followListOptions(r)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	page, err := services.SendFollowersPage(db, userID, user, opts)
+	if err != nil {
+		followListError(w, err)
+		return
+	}
+
 	response := FollowerResponse{
-		Status:    "success",
-		Followers: followers,
+		Status:     "success",
+		Followers:  page.Entries,
+		NextCursor: page.NextCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -138,8 +174,9 @@ func HandleListFollowers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 }
 
 type FollowResponse struct {
-	Status    string                  `json:"status"`
-	Followers []services.ListOfFollow `json:"follow"`
+	Status     string                     `json:"status"`
+	Followers  []services.ListOfFollowers `json:"follow"`
+	NextCursor string                     `json:"next_cursor"`
 }
 
 func HandleFollow(w http.ResponseWriter, r *http.Request, db *sql.DB) {
@@ -155,16 +192,22 @@ func HandleFollow(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		targetUserID = userID
 	}
 
-	var follow []services.ListOfFollow
-	follow, err := services.SendListFollow(db, targetUserID, userID)
+	opts, err := followListOptions(r)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	page, err := services.SendFollowingPage(db, userID, targetUserID, opts)
+	if err != nil {
+		followListError(w, err)
+		return
+	}
+
 	response := FollowResponse{
-		Status:    "success",
-		Followers: follow,
+		Status:     "success",
+		Followers:  page.Entries,
+		NextCursor: page.NextCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -175,6 +218,42 @@ func HandleFollow(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	}
 }
 
+func HandleGetMutuals(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	targetUserID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	opts, err := followListOptions(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := services.SendMutualsPage(db, userID, targetUserID, opts)
+	if err != nil {
+		followListError(w, err)
+		return
+	}
+
+	response := FollowerResponse{
+		Status:     "success",
+		Followers:  page.Entries,
+		NextCursor: page.NextCursor,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
 func HandleDeleteFollow(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	userID := utils.GetUserIdByCookie(r, db)
 	if userID == "" {
@@ -217,3 +296,67 @@ func HandleAbortFollow(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 
 	utils.SuccessResponse(w, http.StatusOK, "Abort Follow")
 }
+
+func HandleGetFollowRequests(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	direction := r.URL.Query().Get("direction")
+	if direction == "" {
+		direction = services.FollowRequestsIn
+	}
+
+	requests, err := services.SendFollowRequests(db, userID, direction)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(requests); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleBulkFollowRequests accepte ou refuse plusieurs demandes (champ "users" répété) et renvoie
+// le résultat de chacune.
+func HandleBulkFollowRequests(w http.ResponseWriter, r *http.Request, db *sql.DB, accept bool) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	var results []services.FollowRequestResult
+	var err error
+	if accept {
+		results, err = services.AcceptFollowRequests(db, userID, r.Form["users"])
+	} else {
+		results, err = services.DeclineFollowRequests(db, userID, r.Form["users"])
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count := 0
+	for _, result := range results {
+		if result.Status == services.FollowRequestAccepted || result.Status == services.FollowRequestDeclined {
+			count++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	response := map[string]any{"count": count, "results": results}
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
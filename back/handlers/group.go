@@ -3,10 +3,10 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"social-network/services"
 	"social-network/utils"
+	"strconv"
 	"strings"
 )
 
@@ -43,7 +43,7 @@ func HandleCreateGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	err = services.CreateGroup(db, userId, title, desc, uuidAvatar)
+	err = services.CreateGroup(db, userId, title, desc, uuidAvatar, r.FormValue("visibility"))
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -86,7 +86,7 @@ func HandleModifyGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	err = services.ModifyGroup(db, userId, title, desc, groupId, uuidAvatar)
+	err = services.ModifyGroup(db, userId, title, desc, groupId, uuidAvatar, r.FormValue("visibility"))
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -105,6 +105,14 @@ func HandleDeleteGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	groupId := r.URL.Query().Get("groupId")
 
 	err := services.DeleteGroup(db, userId, groupId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err == services.ErrGroupNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -141,21 +149,26 @@ func HandleInviteGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	utils.SuccessResponse(w, http.StatusOK, "Invitation Send")
 }
 
-func HandleBanMemberGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+// HandleKickGroupMember exclut un membre qui pourra revenir (?groupId=&userId=).
+func HandleKickGroupMember(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	userId := utils.GetUserIdByCookie(r, db)
 	if userId == "" {
 		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
-	userTobBan := r.URL.Query().Get("userId")
+	userToKick := r.URL.Query().Get("userId")
 	groupId := r.URL.Query().Get("groupId")
 
-	err := services.DeleteGroupMember(db, userId, userTobBan, groupId)
+	err := services.DeleteGroupMember(db, userId, userToKick, groupId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	utils.SuccessResponse(w, http.StatusOK, "Member banned")
+	utils.SuccessResponse(w, http.StatusOK, "Member removed")
 }
 
 func HandleAskToJoinGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
@@ -167,11 +180,25 @@ func HandleAskToJoinGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 
 	groupId := r.URL.Query().Get("groupId")
 
-	err := services.AskToJoinGroup(db, groupId, userId)
+	// Réponses aux questions du groupe (answers=..., dans l'ordre) ; le corps est facultatif
+	if err := r.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	joined, err := services.AskToJoinGroup(db, groupId, userId, r.Form["answers"])
+	if err == services.ErrGroupBanned {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if joined {
+		utils.SuccessResponse(w, http.StatusOK, "Group joined")
+		return
+	}
 	utils.SuccessResponse(w, http.StatusOK, "Asked to join group")
 }
 
@@ -222,29 +249,19 @@ func HandleCreateEvent(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
-	event := r.URL.Query().Get("event")
-	optionA := r.URL.Query().Get("optionA")
-	optionB := r.URL.Query().Get("optionB")
 	groupId := r.URL.Query().Get("groupId")
-	title := r.URL.Query().Get("title")
-	date := r.URL.Query().Get("date")
-
-	requiredParams := map[string]string{
-		"event":   event,
-		"optionA": optionA,
-		"optionB": optionB,
-		"groupId": groupId,
-		"title":   title,
-		"date":    date,
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing value for groupId")
+		return
 	}
 
-	for key, value := range requiredParams {
-		if strings.TrimSpace(value) == "" {
-			utils.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Missing value for %s", key))
-			return
-		}
+	event, err := eventInputFromRequest(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	idEvent, err := services.CreateEventGroup(db, userId, groupId, event, optionA, optionB, title, date)
+
+	idEvent, err := services.CreateEventGroup(db, userId, groupId, event)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -253,10 +270,12 @@ func HandleCreateEvent(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	utils.SuccessResponse(w, http.StatusOK, "Event Created")
 
 	go func() {
-		services.SendEventGroupNotif(idEvent, groupId, userId, db)
+		services.SendEventGroupNotif(idEvent, "", groupId, userId, "EVENT_GROUP", db)
 	}()
 }
 
+// HandleResponseEvent : response vaut going, maybe ou not_going, occurrence désigne l'occurrence
+// d'un événement récurrent ; renvoie le statut obtenu (waitlist si complet).
 func HandleResponseEvent(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	userId := utils.GetUserIdByCookie(r, db)
 	if userId == "" {
@@ -266,18 +285,30 @@ func HandleResponseEvent(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	eventId := r.URL.Query().Get("eventId")
 	groupId := r.URL.Query().Get("groupId")
 	response := r.URL.Query().Get("response")
-	if strings.TrimSpace(eventId) == "" || strings.TrimSpace(groupId) == "" || (response != "A" && response != "B") {
+	if strings.TrimSpace(eventId) == "" || strings.TrimSpace(groupId) == "" || strings.TrimSpace(response) == "" {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Missing values")
 		return
 	}
 
-	err := services.ResponseEvent(db, userId, groupId, eventId, response)
+	// Ancien front : A pour participer, B pour décliner
+	switch response {
+	case "A":
+		response = services.RSVPGoing
+	case "B":
+		response = services.RSVPNotGoing
+	}
+
+	occurrence := r.URL.Query().Get("occurrence")
+	status, err := services.ResponseEvent(db, userId, groupId, eventId, occurrence, response)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	utils.SuccessResponse(w, http.StatusOK, "Event Response")
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(map[string]string{"event_id": eventId, "occurrence": occurrence, "status": status}); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
 }
 
 func HandleGetGroupInfo(w http.ResponseWriter, r *http.Request, db *sql.DB) {
@@ -345,9 +376,24 @@ func HandleGetGroupMembers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 
-	info, err := services.GetGroupMember(db, userId, groupId)
+	opts := services.GroupMemberListOptions{
+		Search: r.URL.Query().Get("search"),
+		Role:   r.URL.Query().Get("role"),
+		Cursor: r.URL.Query().Get("cursor"),
+		Limit:  services.DefaultGroupMemberLimit,
+	}
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		opts.Limit = limit
+	}
+
+	info, err := services.GetGroupMember(db, userId, groupId, opts)
 	if err != nil {
-		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -372,7 +418,13 @@ func HandleGetEventGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 
-	info, err := services.SendEventInfos(db, userId, groupId)
+	from, to, err := eventWindowFromRequest(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	info, err := services.SendEventInfos(db, userId, groupId, from, to)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+// HandleGetGroupAnnouncements renvoie les annonces du groupe (?groupId=).
+func HandleGetGroupAnnouncements(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	announcements, err := services.ListGroupAnnouncements(db, userId, groupId)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(announcements); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleCreateGroupAnnouncement publie une annonce (?groupId=, texte dans content).
+func HandleCreateGroupAnnouncement(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	announcement, err := services.CreateGroupAnnouncement(db, userId, groupId, r.FormValue("content"))
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(announcement); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleDeleteGroupAnnouncement supprime une annonce (?id=).
+func HandleDeleteGroupAnnouncement(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if strings.TrimSpace(id) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	err := services.DeleteGroupAnnouncement(db, userId, id)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err == services.ErrAnnouncementNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Announcement deleted")
+}
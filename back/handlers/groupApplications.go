@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+func HandleGetGroupQuestions(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	questions, err := services.SendGroupQuestions(db, userId, groupId)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(questions); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleSetGroupQuestions remplace les questions du groupe (questions=..., 5 au plus).
+func HandleSetGroupQuestions(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	err := services.SetGroupQuestions(db, userId, groupId, r.Form["questions"])
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Questions updated")
+}
+
+// HandleDeclineAskToJoinGroup refuse une demande (?groupId=&userId=), avec reason et block facultatifs.
+func HandleDeclineAskToJoinGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	asker := r.URL.Query().Get("userId")
+	if strings.TrimSpace(groupId) == "" || strings.TrimSpace(asker) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or userId")
+		return
+	}
+
+	err := services.DeclineAskerToJoinGroup(db, groupId, userId, asker, r.FormValue("reason"), r.FormValue("block"))
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Request declined")
+}
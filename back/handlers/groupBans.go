@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+// HandleGetGroupBans renvoie les bannissements actifs du groupe (?groupId=).
+func HandleGetGroupBans(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	bans, err := services.ListGroupBans(db, userId, groupId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(bans); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleBanGroupMember bannit un utilisateur (?groupId=&userId= ; duration=1d|1w|1m|1y et reason facultatifs).
+func HandleBanGroupMember(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	targetId := r.URL.Query().Get("userId")
+	if strings.TrimSpace(groupId) == "" || strings.TrimSpace(targetId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or userId")
+		return
+	}
+
+	err := services.BanGroupMember(db, userId, groupId, targetId, r.FormValue("duration"), r.FormValue("reason"))
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Member banned")
+}
+
+// HandleUnbanGroupMember lève un bannissement (?groupId=&userId=).
+func HandleUnbanGroupMember(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	targetId := r.URL.Query().Get("userId")
+	if strings.TrimSpace(groupId) == "" || strings.TrimSpace(targetId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or userId")
+		return
+	}
+
+	err := services.UnbanGroupMember(db, userId, groupId, targetId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Member unbanned")
+}
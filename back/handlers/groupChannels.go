@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+// groupChannelError renvoie le code HTTP d'une erreur des salons.
+func groupChannelError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrGroupPermission:
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case services.ErrGroupChannelNotFound:
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// groupChannelRolesForm lit les rôles autorisés (roles, répétable ou séparé par des virgules).
+// Renvoie nil si le champ est absent.
+func groupChannelRolesForm(r *http.Request) []string {
+	values, ok := r.Form["roles"]
+	if !ok {
+		return nil
+	}
+	roles := []string{}
+	for _, v := range values {
+		for _, role := range strings.Split(v, ",") {
+			if strings.TrimSpace(role) != "" {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
+
+// HandleGetGroupChannels renvoie les salons du groupe accessibles à l'utilisateur (?groupId=).
+func HandleGetGroupChannels(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	channels, err := services.ListGroupChannels(db, userId, groupId)
+	if err != nil {
+		groupChannelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(channels); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleCreateGroupChannel crée un salon (?groupId= ; name, description et roles facultatifs).
+func HandleCreateGroupChannel(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	channel, err := services.CreateGroupChannel(db, userId, groupId, r.FormValue("name"), r.FormValue("description"), groupChannelRolesForm(r))
+	if err != nil {
+		groupChannelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(channel); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleUpdateGroupChannel modifie un salon (?id=). Un champ absent reste inchangé ; roles vide
+// rouvre le salon à tous les membres.
+func HandleUpdateGroupChannel(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if strings.TrimSpace(id) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	var description *string
+	if values, ok := r.Form["description"]; ok {
+		description = &values[0]
+	}
+
+	channel, err := services.UpdateGroupChannel(db, userId, id, r.FormValue("name"), description, groupChannelRolesForm(r))
+	if err != nil {
+		groupChannelError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(channel); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleDeleteGroupChannel supprime un salon et ses messages (?id=).
+func HandleDeleteGroupChannel(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if strings.TrimSpace(id) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	if err := services.DeleteGroupChannel(db, userId, id); err != nil {
+		groupChannelError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Channel deleted")
+}
+
+// HandleGroupChannelSubscription abonne (subscribe = true) ou désabonne l'utilisateur d'un salon (?id=).
+func HandleGroupChannelSubscription(w http.ResponseWriter, r *http.Request, db *sql.DB, subscribe bool) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if strings.TrimSpace(id) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	if err := services.SetGroupChannelSubscription(db, userId, id, subscribe); err != nil {
+		groupChannelError(w, err)
+		return
+	}
+
+	if subscribe {
+		utils.SuccessResponse(w, http.StatusOK, "Subscribed to channel")
+		return
+	}
+	utils.SuccessResponse(w, http.StatusOK, "Unsubscribed from channel")
+}
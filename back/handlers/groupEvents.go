@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventInputFromRequest lit l'événement dans la query ou le formulaire : title, description, start, end,
+// timeZone, location, capacity, reminders (minutes, répétable ; reminders= vide pour aucun rappel)
+// et recurrence (RRULE, ex. FREQ=WEEKLY;BYDAY=MO;COUNT=10).
+func eventInputFromRequest(r *http.Request) (services.EventInput, error) {
+	in := services.EventInput{
+		Title:       r.FormValue("title"),
+		Description: r.FormValue("description"),
+		Start:       r.FormValue("start"),
+		End:         r.FormValue("end"),
+		TimeZone:    r.FormValue("timeZone"),
+		Location:    r.FormValue("location"),
+		Recurrence:  r.FormValue("recurrence"),
+	}
+	// Anciens paramètres du front (event, date au format JJ/MM/AAAA)
+	if in.Description == "" {
+		in.Description = r.FormValue("event")
+	}
+	if in.Start == "" {
+		in.Start = legacyEventDate(r.FormValue("date"))
+	}
+	if strings.TrimSpace(in.Start) == "" {
+		return in, errors.New("Missing value for start")
+	}
+
+	if c := r.FormValue("capacity"); c != "" {
+		capacity, err := strconv.Atoi(c)
+		if err != nil {
+			return in, errors.New("Invalid capacity")
+		}
+		in.Capacity = capacity
+	}
+
+	if values, ok := r.Form["reminders"]; ok {
+		in.Reminders = []int{}
+		for _, v := range values {
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			m, err := strconv.Atoi(v)
+			if err != nil {
+				return in, errors.New("Invalid reminders")
+			}
+			in.Reminders = append(in.Reminders, m)
+		}
+	}
+
+	return in, nil
+}
+
+// legacyEventDate convertit une date JJ/MM/AAAA de l'ancien formulaire en début d'événement à minuit.
+// Les autres formats sont renvoyés tels quels.
+func legacyEventDate(value string) string {
+	t, err := time.Parse("02/01/2006", strings.TrimSpace(value))
+	if err != nil {
+		return value
+	}
+	return t.Format("2006-01-02T15:04")
+}
+
+// Fenêtre par défaut de la liste des événements, et fenêtre maximale demandable
+const (
+	defaultEventsPast   = 30 * 24 * time.Hour
+	defaultEventsFuture = 90 * 24 * time.Hour
+	maxEventsWindow     = 366 * 24 * time.Hour
+)
+
+// eventWindowFromRequest lit la fenêtre from/to (RFC 3339 ou AAAA-MM-JJ en UTC) des occurrences à renvoyer.
+func eventWindowFromRequest(r *http.Request) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	from, to := now.Add(-defaultEventsPast), now.Add(defaultEventsFuture)
+
+	parse := func(name string, value *time.Time) error {
+		v := strings.TrimSpace(r.URL.Query().Get(name))
+		if v == "" {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", v)
+			if err != nil {
+				return errors.New("Invalid " + name)
+			}
+		}
+		*value = t
+		return nil
+	}
+	if err := parse("from", &from); err != nil {
+		return from, to, err
+	}
+	if err := parse("to", &to); err != nil {
+		return from, to, err
+	}
+	if !to.After(from) {
+		return from, to, errors.New("to must be after from")
+	}
+	if to.Sub(from) > maxEventsWindow {
+		return from, to, errors.New("date window cannot exceed 366 days")
+	}
+
+	return from, to, nil
+}
+
+// HandleUpdateEvent remplace les informations de l'événement (?eventId=), ou d'une seule de ses occurrences
+// avec ?occurrence= ; réservé à l'organisateur et aux admins.
+func HandleUpdateEvent(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	eventId := r.URL.Query().Get("eventId")
+	if strings.TrimSpace(eventId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing eventId")
+		return
+	}
+
+	event, err := eventInputFromRequest(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var groupId, occurrence string
+	if r.URL.Query().Get("occurrence") != "" {
+		groupId, occurrence, err = services.UpdateEventOccurrence(db, userId, eventId, r.URL.Query().Get("occurrence"), event)
+	} else {
+		groupId, err = services.UpdateEventGroup(db, userId, eventId, event)
+	}
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Event Updated")
+
+	go func() {
+		services.SendEventGroupNotif(eventId, occurrence, groupId, userId, "EVENT_UPDATED", db)
+	}()
+}
+
+// HandleCancelEvent annule l'événement (?eventId=), ou une seule de ses occurrences avec ?occurrence=.
+func HandleCancelEvent(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	eventId := r.URL.Query().Get("eventId")
+	if strings.TrimSpace(eventId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing eventId")
+		return
+	}
+
+	var groupId, occurrence string
+	var err error
+	if r.URL.Query().Get("occurrence") != "" {
+		groupId, occurrence, err = services.CancelEventOccurrence(db, userId, eventId, r.URL.Query().Get("occurrence"))
+	} else {
+		groupId, err = services.CancelEventGroup(db, userId, eventId)
+	}
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Event Cancelled")
+
+	go func() {
+		services.SendEventGroupNotif(eventId, occurrence, groupId, userId, "EVENT_CANCELLED", db)
+	}()
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"social-network/services"
+	"social-network/utils"
+	"strconv"
+	"strings"
+)
+
+// groupFileError renvoie le code HTTP d'une erreur de la bibliothèque de fichiers.
+func groupFileError(w http.ResponseWriter, err error) {
+	switch err {
+	case services.ErrGroupPermission:
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case services.ErrGroupFileNotFound, services.ErrGroupFolderNotFound, services.ErrGroupNotFound:
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case services.ErrGroupFileQuota:
+		utils.ErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// HandleGetGroupFiles renvoie le contenu d'un dossier de la bibliothèque (?groupId= ; folderId, vide : racine).
+func HandleGetGroupFiles(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	listing, err := services.ListGroupFiles(db, userId, groupId, r.URL.Query().Get("folderId"))
+	if err != nil {
+		groupFileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(listing); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleUploadGroupFile ajoute un fichier à la bibliothèque (?groupId= ; folderId facultatif, fichier dans file).
+func HandleUploadGroupFile(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	folderId := r.URL.Query().Get("folderId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	// Marge pour les autres champs du formulaire multipart
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxGroupFileSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing file or file too large")
+		return
+	}
+	defer file.Close()
+
+	allowed, err := services.CheckGroupFileUpload(db, userId, groupId, folderId, header.Size)
+	if err != nil {
+		groupFileError(w, err)
+		return
+	}
+
+	storedName, mimeType, err := utils.SaveFile(services.GroupFilesDir, file, header, allowed)
+	if err == utils.ErrUnsupportedFileType {
+		utils.ErrorResponse(w, http.StatusUnsupportedMediaType, "file type not allowed in this group: "+mimeType)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save file")
+		return
+	}
+
+	groupFile, err := services.AddGroupFile(db, userId, groupId, folderId, filepath.Base(header.Filename), storedName, mimeType, header.Size)
+	if err != nil {
+		if rmErr := utils.RemoveImage("groupFiles", storedName); rmErr != nil {
+			log.Printf("Impossible de supprimer le fichier de groupe %s : %v", storedName, rmErr)
+		}
+		groupFileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(groupFile); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleDeleteGroupFile supprime un fichier de la bibliothèque (?id=).
+func HandleDeleteGroupFile(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if strings.TrimSpace(id) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	if err := services.DeleteGroupFile(db, userId, id); err != nil {
+		groupFileError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "File deleted")
+}
+
+// HandleCreateGroupFolder crée un dossier (?groupId= ; parentId facultatif, nom dans name).
+func HandleCreateGroupFolder(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	folder, err := services.CreateGroupFolder(db, userId, groupId, r.URL.Query().Get("parentId"), r.FormValue("name"))
+	if err != nil {
+		groupFileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(folder); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleDeleteGroupFolder supprime un dossier vide (?id=).
+func HandleDeleteGroupFolder(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if strings.TrimSpace(id) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	if err := services.DeleteGroupFolder(db, userId, id); err != nil {
+		groupFileError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Folder deleted")
+}
+
+// HandleSetGroupFileSettings modifie le quota (quota, en octets) et les types acceptés (types, répétable)
+// de la bibliothèque (?groupId=). Un champ absent reste inchangé.
+func HandleSetGroupFileSettings(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	var quota int64
+	if q := r.FormValue("quota"); q != "" {
+		var err error
+		quota, err = strconv.ParseInt(q, 10, 64)
+		if err != nil || quota <= 0 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid quota")
+			return
+		}
+	}
+
+	var types []string
+	if values, ok := r.Form["types"]; ok {
+		types = []string{}
+		for _, v := range values {
+			for _, t := range strings.Split(v, ",") {
+				if strings.TrimSpace(t) != "" {
+					types = append(types, t)
+				}
+			}
+		}
+	}
+
+	settings, err := services.SetGroupFileSettings(db, userId, groupId, quota, types)
+	if err != nil {
+		groupFileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(settings); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleGroupFile sert un fichier de la bibliothèque aux membres du groupe, en pièce jointe
+// sous son nom d'origine et avec le type détecté à l'envoi.
+func HandleGroupFile(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	name := filepath.Base(r.PathValue("name"))
+	groupFile, err := services.CanPassGroupFile(db, userId, name)
+	if err == services.ErrGroupFileNotFound {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	file, err := os.Open(filepath.Join(services.GroupFilesDir, name))
+	if err != nil {
+		log.Printf("Group file not found: %s", name)
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", groupFile.MimeType)
+	w.Header().Set("Content-Length", strconv.FormatInt(groupFile.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": groupFile.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if _, err = io.Copy(w, file); err != nil {
+		log.Printf("Failed to send group file %s: %v", name, err)
+	}
+}
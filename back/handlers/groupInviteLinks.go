@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strconv"
+	"strings"
+)
+
+// HandleCreateGroupInviteLink crée un lien (?groupId=, expires=1h|1d|1w|1m et maxUses facultatifs).
+func HandleCreateGroupInviteLink(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	maxUses := 0
+	if m := r.FormValue("maxUses"); m != "" {
+		var err error
+		maxUses, err = strconv.Atoi(m)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid maxUses")
+			return
+		}
+	}
+
+	link, err := services.CreateGroupInviteLink(db, userId, groupId, r.FormValue("expires"), maxUses)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(link); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleGetGroupInviteLinks(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	links, err := services.ListGroupInviteLinks(db, userId, groupId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(links); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleRevokeGroupInviteLink(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	linkId := r.URL.Query().Get("linkId")
+	if strings.TrimSpace(linkId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing linkId")
+		return
+	}
+
+	err := services.RevokeGroupInviteLink(db, userId, linkId)
+	switch err {
+	case nil:
+	case services.ErrGroupPermission:
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	case services.ErrInviteLinkNotFound:
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Invite link revoked")
+}
+
+// HandleGroupInviteCode : GET présente le groupe du lien, POST le rejoint.
+func HandleGroupInviteCode(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	code := r.PathValue("code")
+
+	var data any
+	var err error
+	if r.Method == http.MethodPost {
+		var groupId string
+		groupId, err = services.JoinGroupByInviteLink(db, userId, code)
+		data = map[string]string{"group_id": groupId}
+	} else {
+		data, err = services.PreviewGroupInviteLink(db, userId, code)
+	}
+	if err == services.ErrInviteLinkNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err == services.ErrGroupBanned {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(data); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+func HandleLeaveGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	if err := services.LeaveGroup(db, userId, groupId); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Group left")
+}
+
+func HandleTransferGroup(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	target := r.URL.Query().Get("userId")
+	if strings.TrimSpace(groupId) == "" || strings.TrimSpace(target) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or userId")
+		return
+	}
+
+	err := services.TransferGroupOwnership(db, userId, groupId, target)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Transfer proposed")
+}
+
+// HandleAnswerGroupTransfer accepte le transfert en attente, ou l'annule (refus du destinataire ou retrait du propriétaire).
+func HandleAnswerGroupTransfer(w http.ResponseWriter, r *http.Request, db *sql.DB, accept bool) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	var err error
+	if accept {
+		err = services.AcceptGroupTransfer(db, userId, groupId)
+	} else {
+		err = services.CancelGroupTransfer(db, userId, groupId)
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if accept {
+		utils.SuccessResponse(w, http.StatusOK, "Transfer accepted")
+	} else {
+		utils.SuccessResponse(w, http.StatusOK, "Transfer cancelled")
+	}
+}
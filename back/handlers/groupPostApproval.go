@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+// HandleGetPendingGroupPosts renvoie la file de validation du groupe (?groupId=).
+func HandleGetPendingGroupPosts(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	posts, err := services.ListPendingGroupPosts(db, userId, groupId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(posts); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleApproveGroupPost publie un post en attente (?postId=).
+func HandleApproveGroupPost(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	postId := r.URL.Query().Get("postId")
+	if strings.TrimSpace(postId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing postId")
+		return
+	}
+
+	writeGroupPostReview(w, services.ApproveGroupPost(db, userId, postId), "Post approved")
+}
+
+// HandleRejectGroupPost refuse un post en attente (?postId=, motif dans reason).
+func HandleRejectGroupPost(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	postId := r.URL.Query().Get("postId")
+	if strings.TrimSpace(postId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing postId")
+		return
+	}
+
+	writeGroupPostReview(w, services.RejectGroupPost(db, userId, postId, r.FormValue("reason")), "Post rejected")
+}
+
+func writeGroupPostReview(w http.ResponseWriter, err error, msg string) {
+	switch err {
+	case nil:
+		utils.SuccessResponse(w, http.StatusOK, msg)
+	case services.ErrGroupPermission:
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case services.ErrPostNotFound:
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// HandleSetGroupPostApproval active ou désactive la validation des posts (?groupId=&enabled=true|false).
+func HandleSetGroupPostApproval(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	enabled := r.URL.Query().Get("enabled")
+	if strings.TrimSpace(groupId) == "" || (enabled != "true" && enabled != "false") {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or invalid enabled")
+		return
+	}
+
+	err := services.SetGroupPostApproval(db, userId, groupId, enabled == "true")
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Post approval updated")
+}
+
+// HandleSetGroupMemberTrusted marque ou non un membre de confiance (?groupId=&userId=&trusted=true|false).
+func HandleSetGroupMemberTrusted(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	targetId := r.URL.Query().Get("userId")
+	trusted := r.URL.Query().Get("trusted")
+	if strings.TrimSpace(groupId) == "" || strings.TrimSpace(targetId) == "" || (trusted != "true" && trusted != "false") {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId, userId or invalid trusted")
+		return
+	}
+
+	err := services.SetGroupMemberTrusted(db, userId, groupId, targetId, trusted == "true")
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Member updated")
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+// HandleChangeGroupRole promeut (promote = true) ou rétrograde un membre du groupe.
+// Le paramètre role est optionnel : sans lui, le membre change d'un rang.
+func HandleChangeGroupRole(w http.ResponseWriter, r *http.Request, db *sql.DB, promote bool) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	target := r.URL.Query().Get("userId")
+	if strings.TrimSpace(groupId) == "" || strings.TrimSpace(target) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or userId")
+		return
+	}
+	role := r.URL.Query().Get("role")
+
+	var err error
+	if promote {
+		role, err = services.PromoteGroupMember(db, userId, groupId, target, role)
+	} else {
+		role, err = services.DemoteGroupMember(db, userId, groupId, target, role)
+	}
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(map[string]string{"user_id": target, "role": role}); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleDeleteGroupMessage(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	messageId := r.URL.Query().Get("messageId")
+	if strings.TrimSpace(messageId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing messageId")
+		return
+	}
+
+	err := services.DeleteGroupMessage(db, userId, messageId)
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Message deleted")
+}
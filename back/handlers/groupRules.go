@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strconv"
+	"strings"
+)
+
+// HandleGetGroupRules renvoie le règlement en vigueur (?groupId=), ou toutes ses versions avec history=true.
+func HandleGetGroupRules(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	var rules any
+	var err error
+	if r.URL.Query().Get("history") == "true" {
+		rules, err = services.GroupRulesHistory(db, userId, groupId)
+	} else {
+		rules, err = services.GetGroupRules(db, userId, groupId)
+	}
+	if err == services.ErrGroupNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(rules); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleSetGroupRules publie une nouvelle version du règlement (?groupId=, texte dans content).
+func HandleSetGroupRules(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	if strings.TrimSpace(groupId) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId")
+		return
+	}
+
+	rules, err := services.SetGroupRules(db, userId, groupId, r.FormValue("content"))
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(rules); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleAcceptGroupRules enregistre l'acceptation de la version lue (?groupId=&version=).
+func HandleAcceptGroupRules(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	groupId := r.URL.Query().Get("groupId")
+	version, err := strconv.Atoi(r.URL.Query().Get("version"))
+	if strings.TrimSpace(groupId) == "" || err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupId or invalid version")
+		return
+	}
+
+	err = services.AcceptGroupRules(db, userId, groupId, version)
+	if err == services.ErrGroupRulesChanged {
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Rules accepted")
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strconv"
+)
+
+// groupStatsFromRequest calcule les statistiques du groupe {id} sur la période ?from=&to= (AAAA-MM-JJ).
+// Renvoie false si une réponse d'erreur a déjà été écrite.
+func groupStatsFromRequest(w http.ResponseWriter, r *http.Request, db *sql.DB) (services.GroupStats, bool) {
+	userId := utils.GetUserIdByCookie(r, db)
+	if userId == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
+		return services.GroupStats{}, false
+	}
+
+	stats, err := services.SendGroupStats(db, userId, r.PathValue("id"), r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err == services.ErrGroupPermission {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return stats, false
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return stats, false
+	}
+	return stats, true
+}
+
+// HandleGetGroupStats renvoie l'activité du groupe jour par jour, pour les propriétaires et admins.
+func HandleGetGroupStats(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	stats, ok := groupStatsFromRequest(w, r, db)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+// HandleGroupStatsCSV exporte les mêmes séries au format CSV, une ligne par jour.
+func HandleGroupStatsCSV(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	stats, ok := groupStatsFromRequest(w, r, db)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="group-stats-`+stats.From+`-`+stats.To+`.csv"`)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "active_members", "posts", "comments", "messages", "rsvps",
+		"requests_accepted", "requests_declined", "new_members", "members"})
+	for _, d := range stats.Days {
+		cw.Write([]string{d.Date, strconv.Itoa(d.ActiveMembers), strconv.Itoa(d.Posts), strconv.Itoa(d.Comments),
+			strconv.Itoa(d.Messages), strconv.Itoa(d.RSVPs), strconv.Itoa(d.RequestsAccepted),
+			strconv.Itoa(d.RequestsDeclined), strconv.Itoa(d.NewMembers), strconv.Itoa(d.Members)})
+	}
+	cw.Flush()
+}
@@ -57,7 +57,19 @@ func HandleImages(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 			return
 		}
 
-	} else if typeImg != "avatars" && typeImg != "groupImages" {
+	} else if typeImg == "storyImages" {
+		err := services.CanPassStoryImage(db, userID, id)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+	} else if typeImg == "groupImages" {
+		err := services.CanPassGroupImage(db, userID, id)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+	} else if typeImg != "avatars" {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Invalid image type")
 		return
 	}
@@ -74,6 +86,26 @@ func HandleImages(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	// Construire un chemin sécurisé (empêche les `../`)
 	imagePath := filepath.Join("Images", typeImg, id)
 
+	// Contenu signalé : variante floutée tant que l'utilisateur n'a pas demandé à l'afficher (?reveal=true)
+	if r.URL.Query().Get("reveal") != "true" {
+		blurred, err := services.ShouldBlurImage(db, userID, typeImg, id)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check image flags")
+			return
+		}
+		if blurred {
+			imagePath, err = utils.BlurredImage(typeImg, id)
+			if err != nil {
+				log.Printf("Failed to blur image %s: %v", id, err)
+				http.Error(w, "Image not found", http.StatusNotFound)
+				return
+			}
+			ext = filepath.Ext(imagePath)
+			w.Header().Set("X-Content-Blurred", "true")
+			w.Header().Set("Cache-Control", "no-store")
+		}
+	}
+
 	// Ouvrir le fichier
 	file, err := os.Open(imagePath)
 	if err != nil {
@@ -35,6 +35,12 @@ func HandleSendMessage(w http.ResponseWriter, r *http.Request, db *sql.DB, h *we
 		return
 	}
 
+	flags, err := services.NewContentFlags(r.FormValue("content_warning"), r.FormValue("sensitive"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	file, image, err := r.FormFile("image")
 	hasImage := err == nil
 	hasText := content != ""
@@ -66,7 +72,7 @@ func HandleSendMessage(w http.ResponseWriter, r *http.Request, db *sql.DB, h *we
 
 	members := append(receivers, userID)
 
-	convID, msgID, err := services.AddMessage(db, members, userID, conversationID, content, typeMessage)
+	convID, msgID, err := services.AddMessage(db, members, userID, conversationID, content, typeMessage, flags)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -134,11 +140,18 @@ func HandleMessageGroups(w http.ResponseWriter, r *http.Request, db *sql.DB, h *
 
 	content := strings.TrimSpace(r.FormValue("content"))
 	groupID := strings.TrimSpace(r.FormValue("groupID"))
+	channelID := strings.TrimSpace(r.FormValue("channelID")) // facultatif : salon par défaut
 	if groupID == "" {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Missing groupID field")
 		return
 	}
 
+	flags, err := services.NewContentFlags(r.FormValue("content_warning"), r.FormValue("sensitive"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	file, image, err := r.FormFile("image")
 	hasImage := err == nil
 	hasText := content != ""
@@ -171,14 +184,18 @@ func HandleMessageGroups(w http.ResponseWriter, r *http.Request, db *sql.DB, h *
 	}
 
 	// Enregistrement du message dans la base de données
-	convID, msgID, err := services.SendGroupMessage(db, userID, groupID, content, typeMessage)
+	convID, msgID, channelID, err := services.SendGroupMessage(db, userID, groupID, channelID, content, typeMessage, flags)
+	if err == services.ErrGroupChannelNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to send group message")
 		return
 	}
 
-	// Broadcast WebSocket
-	err = h.SendGroupMessage(groupID, content, userID, convID, msgID, typeMessage, db)
+	// Broadcast WebSocket aux abonnés du salon
+	err = h.SendGroupMessage(groupID, channelID, content, userID, convID, msgID, typeMessage, db)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to broadcast message")
 		return
@@ -201,7 +218,11 @@ func HandleGetMessageGroups(w http.ResponseWriter, r *http.Request, db *sql.DB)
 		return
 	}
 
-	listMessage, err := services.SendMessageGroup(db, userID, groupID)
+	listMessage, err := services.SendMessageGroup(db, userID, groupID, strings.TrimSpace(r.URL.Query().Get("channelID")))
+	if err == services.ErrGroupChannelNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get group messages: "+err.Error())
 		return
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+func HandleGetMutes(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	mutes, err := services.SendMutes(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get mutes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(mutes); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleMute(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetID := r.FormValue("id")
+	if strings.TrimSpace(targetID) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing id")
+		return
+	}
+
+	err := services.MuteTarget(db, userID, r.FormValue("type"), targetID, r.FormValue("duration"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Muted")
+}
+
+func HandleUnmute(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetType := r.URL.Query().Get("type")
+	targetID := r.URL.Query().Get("id")
+	if strings.TrimSpace(targetType) == "" || strings.TrimSpace(targetID) == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing type or id")
+		return
+	}
+
+	err := services.UnmuteTarget(db, userID, targetType, targetID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Unmuted")
+}
@@ -24,13 +24,20 @@ func CreatePostHandler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	}
 
 	users := r.Form["users"]
+	lists := r.Form["lists"]
 	content := r.FormValue("content")
 	tag := r.FormValue("tags")
 	privacy := r.FormValue("privacy")
+	commentPolicy := r.FormValue("comment_policy")
 	if strings.TrimSpace(content) == "" {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Missing content or tag")
 		return
 	}
+	flags, err := services.NewContentFlags(r.FormValue("content_warning"), r.FormValue("sensitive"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	var uuidAvatar string
 	file, image, err := r.FormFile("image")
 	if err == nil {
@@ -53,12 +60,20 @@ func CreatePostHandler(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 
 	groupId := r.FormValue("groupId")
 
-	err = services.CreatePost(content, userID, uuidAvatar, tag, groupId, privacy, users, db)
+	status, err := services.CreatePost(content, userID, uuidAvatar, tag, groupId, privacy, commentPolicy, flags, users, lists, db)
+	if err == services.ErrGroupRulesNotAccepted {
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	if status == services.PostStatusPending {
+		utils.SuccessResponse(w, http.StatusAccepted, "Post pending approval")
+		return
+	}
 	utils.SuccessResponse(w, http.StatusOK, "Post created")
 }
 
@@ -136,6 +151,11 @@ func HandleUpdatePost(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Missing content or tags")
 		return
 	}
+	flags, err := services.NewContentFlagsUpdate(r.Form)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var uuidAvatar string
 	file, image, err := r.FormFile("image")
@@ -157,7 +177,7 @@ func HandleUpdatePost(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	err = services.UpdatePost(db, userID, postID, content, tags, uuidAvatar)
+	err = services.UpdatePost(db, userID, postID, content, tags, uuidAvatar, flags)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Post not found")
 		log.Println(err)
@@ -242,3 +262,55 @@ func HandleDeletePrivateMember(w http.ResponseWriter, r *http.Request, db *sql.D
 	utils.SuccessResponse(w, http.StatusOK, "user deleted")
 
 }
+
+func HandleCommentSettings(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	postID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	policy := r.FormValue("comment_policy")
+	locked := r.FormValue("locked")
+	if policy == "" && locked == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing comment_policy or locked")
+		return
+	}
+
+	err = services.UpdateCommentSettings(db, userID, postID, policy, locked)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Comment settings updated")
+}
+
+func HandleGetCommentModeration(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	postID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	logs, err := services.GetCommentModeration(db, userID, postID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(logs); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
@@ -22,7 +22,7 @@ func HandleSearchBar(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		return
 	}
 
-	result, err := services.SendSearch(db, param)
+	result, err := services.SendSearch(db, userID, param)
 	if err != nil {
 		utils.ErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"social-network/services"
+	"social-network/utils"
+	"strings"
+)
+
+func HandleCreateStory(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	err := r.ParseMultipartForm(10 << 20)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Failed to parse form data")
+		return
+	}
+
+	users := r.Form["users"]
+	content := r.FormValue("content")
+	privacy := r.FormValue("privacy")
+
+	var uuidImage string
+	file, image, err := r.FormFile("image")
+	if err == nil {
+		defer file.Close()
+
+		// Vérification de la taille du fichier
+		const maxFileSize = 4 * 1024 * 1024
+		if image.Size > maxFileSize {
+			utils.ErrorResponse(w, http.StatusBadRequest, "File too large (max 4MB)")
+			return
+		}
+
+		// Sauvegarde du fichier
+		uuidImage, err = utils.SaveImage("Images/storyImages/", file, image)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Invalid image")
+			return
+		}
+	}
+
+	if strings.TrimSpace(content) == "" && uuidImage == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Missing content or image")
+		return
+	}
+
+	storyId, err := services.CreateStory(db, userID, content, uuidImage, privacy, users)
+	if err != nil {
+		if uuidImage != "" {
+			if rmErr := utils.RemoveImage("storyImages", uuidImage); rmErr != nil {
+				log.Printf("Impossible de supprimer l'image de story %s : %v", uuidImage, rmErr)
+			}
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(map[string]string{"id": storyId}); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleStoryTray(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tray, err := services.SendStoryTray(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get stories")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(tray); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleGetUserStories(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	authorID := r.URL.Query().Get("user")
+	if authorID == "" {
+		authorID = userID
+	}
+
+	stories, err := services.SendUserStories(db, userID, authorID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to get stories")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(stories); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleViewStory(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	storyID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	err = services.ViewStory(db, userID, storyID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Story viewed")
+}
+
+func HandleGetStoryViewers(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	storyID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	viewers, err := services.SendStoryViewers(db, userID, storyID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(viewers); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to encode JSON")
+	}
+}
+
+func HandleDeleteStory(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	storyID, err := utils.ParseUrl(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid URL")
+		return
+	}
+
+	err = services.DeleteStory(db, userID, storyID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Story deleted")
+}
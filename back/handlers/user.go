@@ -76,6 +76,22 @@ func HandleSwitchPublicStatus(w http.ResponseWriter, r *http.Request, db *sql.DB
 
 }
 
+func HandleSensitivePreference(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	userID := utils.GetUserIdByCookie(r, db)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	err := services.UpdateSensitivePreference(db, userID, r.FormValue("sensitive_content"))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Preference updated")
+}
+
 func HandleUpdateUserInfo(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	userID := utils.GetUserIdByCookie(r, db)
 	if userID == "" {
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"golang.org/x/time/rate"
 	"log"
+	"net"
 	"net/http"
 	"social-network/utils"
+	"strings"
 	"sync"
 	"time"
 )
@@ -48,6 +50,28 @@ func getLimiter(userID string) *rate.Limiter {
 	return limiter
 }
 
+// clientIP renvoie l'adresse du client. Derrière le reverse proxy (adresse locale ou privée),
+// c'est la dernière entrée de X-Forwarded-For, ajoutée par le proxy lui-même.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !(ip.IsLoopback() || ip.IsPrivate()) {
+		return host
+	}
+	forwarded := r.Header.Values("X-Forwarded-For")
+	if len(forwarded) == 0 {
+		return host
+	}
+	hops := strings.Split(forwarded[len(forwarded)-1], ",")
+	if last := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(last) != nil {
+		return last
+	}
+	return host
+}
+
 func RateLimitMiddleware(next http.Handler, db *sql.DB) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/login" || r.URL.Path == "/api/register" || r.URL.Path == "/" || r.URL.Path == "/api/check/username" || r.URL.Path == "/api/check/email" {
@@ -59,6 +83,17 @@ func RateLimitMiddleware(next http.Handler, db *sql.DB) http.Handler {
 			return
 		}
 
+		// Flux iCalendar personnel : le jeton du chemin remplace la session, limité par adresse IP
+		// pour qu'un jeton deviné au hasard ne donne pas un nouveau quota
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/calendar/") && strings.HasSuffix(r.URL.Path, ".ics") {
+			if !getLimiter("ip:" + clientIP(r)).Allow() {
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		userId, pass := AuthMiddleware(r, db)
 		if !pass {
 			utils.ErrorResponse(w, http.StatusUnauthorized, "unauthorized")
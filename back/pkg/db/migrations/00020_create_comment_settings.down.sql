@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS COMMENT_MODERATION;
+DROP TABLE IF EXISTS POST_MENTIONS;
+ALTER TABLE COMMENT DROP COLUMN HIDDEN;
+ALTER TABLE POSTS DROP COLUMN COMMENTS_LOCKED;
+ALTER TABLE POSTS DROP COLUMN COMMENT_POLICY;
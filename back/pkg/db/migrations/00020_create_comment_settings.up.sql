@@ -0,0 +1,26 @@
+ALTER TABLE POSTS ADD COLUMN COMMENT_POLICY TEXT NOT NULL DEFAULT 'everyone' CHECK ( COMMENT_POLICY IN ('everyone', 'off', 'followers', 'mentioned') );
+ALTER TABLE POSTS ADD COLUMN COMMENTS_LOCKED INT NOT NULL DEFAULT 0 CHECK ( COMMENTS_LOCKED IN (0, 1) );
+
+ALTER TABLE COMMENT ADD COLUMN HIDDEN INT NOT NULL DEFAULT 0 CHECK ( HIDDEN IN (0, 1) );
+
+CREATE TABLE IF NOT EXISTS POST_MENTIONS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    POST_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL, -- L'utilisateur mentionné (@username) dans le contenu du post
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (POST_ID) REFERENCES POSTS(ID) ON DELETE CASCADE,
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+CREATE TABLE IF NOT EXISTS COMMENT_MODERATION (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    POST_ID TEXT NOT NULL,
+    MODERATOR_ID TEXT NOT NULL,
+    ACTION TEXT NOT NULL CHECK ( ACTION IN ('hide', 'unhide', 'delete', 'lock', 'unlock', 'policy') ),
+    COMMENT_ID TEXT NULL, -- NULL pour les actions sur le fil (lock / unlock / policy)
+    COMMENT_AUTHOR_ID TEXT NULL,
+    DETAILS TEXT NULL, -- contenu du commentaire supprimé ou nouvelle politique
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (POST_ID) REFERENCES POSTS(ID) ON DELETE CASCADE,
+    FOREIGN KEY (MODERATOR_ID) REFERENCES USER(ID)
+);
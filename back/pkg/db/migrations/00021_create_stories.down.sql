@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS STORY_VIEWS;
+DROP TABLE IF EXISTS LIST_PRIVATE_STORY;
+DROP INDEX IF EXISTS IDX_STORIES_EXPIRE_AT;
+DROP TABLE IF EXISTS STORIES;
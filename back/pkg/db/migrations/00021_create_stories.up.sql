@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS STORIES (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    USER_ID TEXT NOT NULL,
+    CONTENT TEXT NULL,
+    IMAGE TEXT NULL UNIQUE,
+    PRIVACY INT DEFAULT 1, -- 0 LISTE PRIVÉE / 1 FOLLOWERS / 2 PUBLIC, comme POSTS
+    CREATED_AT TEXT NOT NULL,
+    EXPIRE_AT TEXT NOT NULL,
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_STORIES_EXPIRE_AT ON STORIES (EXPIRE_AT);
+
+CREATE TABLE IF NOT EXISTS LIST_PRIVATE_STORY (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    STORY_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (STORY_ID) REFERENCES STORIES(ID) ON DELETE CASCADE,
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+CREATE TABLE IF NOT EXISTS STORY_VIEWS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    STORY_ID TEXT NOT NULL,
+    VIEWER_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (STORY_ID, VIEWER_ID),
+    FOREIGN KEY (STORY_ID) REFERENCES STORIES(ID) ON DELETE CASCADE,
+    FOREIGN KEY (VIEWER_ID) REFERENCES USER(ID)
+);
@@ -0,0 +1,10 @@
+ALTER TABLE USER DROP COLUMN SENSITIVE_CONTENT;
+
+ALTER TABLE MESSAGES DROP COLUMN SENSITIVE;
+ALTER TABLE MESSAGES DROP COLUMN CONTENT_WARNING;
+
+ALTER TABLE COMMENT DROP COLUMN SENSITIVE;
+ALTER TABLE COMMENT DROP COLUMN CONTENT_WARNING;
+
+ALTER TABLE POSTS DROP COLUMN SENSITIVE;
+ALTER TABLE POSTS DROP COLUMN CONTENT_WARNING;
@@ -0,0 +1,11 @@
+ALTER TABLE POSTS ADD COLUMN CONTENT_WARNING TEXT NULL;
+ALTER TABLE POSTS ADD COLUMN SENSITIVE INT NOT NULL DEFAULT 0 CHECK ( SENSITIVE IN (0, 1) );
+
+ALTER TABLE COMMENT ADD COLUMN CONTENT_WARNING TEXT NULL;
+ALTER TABLE COMMENT ADD COLUMN SENSITIVE INT NOT NULL DEFAULT 0 CHECK ( SENSITIVE IN (0, 1) );
+
+ALTER TABLE MESSAGES ADD COLUMN CONTENT_WARNING TEXT NULL;
+ALTER TABLE MESSAGES ADD COLUMN SENSITIVE INT NOT NULL DEFAULT 0 CHECK ( SENSITIVE IN (0, 1) );
+
+-- Préférence d'affichage du contenu signalé : 'collapse' (masqué par défaut) ou 'expand'
+ALTER TABLE USER ADD COLUMN SENSITIVE_CONTENT TEXT NOT NULL DEFAULT 'collapse' CHECK ( SENSITIVE_CONTENT IN ('collapse', 'expand') );
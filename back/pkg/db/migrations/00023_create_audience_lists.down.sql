@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS IDX_AUDIENCE_LIST_MEMBERS_USER;
+DROP TABLE IF EXISTS POST_AUDIENCE_LISTS;
+DROP TABLE IF EXISTS AUDIENCE_LIST_MEMBERS;
+DROP TABLE IF EXISTS AUDIENCE_LISTS;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS AUDIENCE_LISTS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    OWNER_ID TEXT NOT NULL,
+    NAME TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UPDATED_AT TEXT NOT NULL,
+    UNIQUE (OWNER_ID, NAME),
+    FOREIGN KEY (OWNER_ID) REFERENCES USER(ID)
+);
+
+CREATE TABLE IF NOT EXISTS AUDIENCE_LIST_MEMBERS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    LIST_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (LIST_ID, USER_ID),
+    FOREIGN KEY (LIST_ID) REFERENCES AUDIENCE_LISTS(ID) ON DELETE CASCADE,
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+-- Listes ciblées par un post privé (PRIVACY = 0), évaluées à la lecture
+CREATE TABLE IF NOT EXISTS POST_AUDIENCE_LISTS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    POST_ID TEXT NOT NULL,
+    LIST_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (POST_ID, LIST_ID),
+    FOREIGN KEY (POST_ID) REFERENCES POSTS(ID) ON DELETE CASCADE,
+    FOREIGN KEY (LIST_ID) REFERENCES AUDIENCE_LISTS(ID) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS IDX_AUDIENCE_LIST_MEMBERS_USER ON AUDIENCE_LIST_MEMBERS(USER_ID);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS IDX_FOLLOWERS_USER_ID;
+DROP INDEX IF EXISTS IDX_POST_VIEWS_DAY;
+DROP TABLE IF EXISTS POST_VIEWS;
@@ -0,0 +1,13 @@
+-- Une vue par utilisateur, par post et par jour (les impressions sont dédupliquées)
+CREATE TABLE IF NOT EXISTS POST_VIEWS (
+    POST_ID TEXT NOT NULL,
+    VIEWER_ID TEXT NOT NULL,
+    DAY TEXT NOT NULL, -- YYYY-MM-DD
+    CREATED_AT TEXT NOT NULL,
+    PRIMARY KEY (POST_ID, VIEWER_ID, DAY),
+    FOREIGN KEY (POST_ID) REFERENCES POSTS(ID) ON DELETE CASCADE,
+    FOREIGN KEY (VIEWER_ID) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_POST_VIEWS_DAY ON POST_VIEWS(DAY);
+CREATE INDEX IF NOT EXISTS IDX_FOLLOWERS_USER_ID ON FOLLOWERS(USER_ID);
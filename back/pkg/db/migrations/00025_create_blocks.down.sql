@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS IDX_BLOCKS_BLOCKED_ID;
+DROP TABLE IF EXISTS BLOCKS;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS BLOCKS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    BLOCKER_ID TEXT NOT NULL,
+    BLOCKED_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (BLOCKER_ID, BLOCKED_ID),
+    FOREIGN KEY (BLOCKER_ID) REFERENCES USER(ID),
+    FOREIGN KEY (BLOCKED_ID) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_BLOCKS_BLOCKED_ID ON BLOCKS(BLOCKED_ID);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS IDX_MUTES_TARGET;
+DROP TABLE IF EXISTS MUTES;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS MUTES (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    USER_ID TEXT NOT NULL,
+    TARGET_TYPE TEXT NOT NULL CHECK (TARGET_TYPE IN ('user', 'conversation', 'group')),
+    TARGET_ID TEXT NOT NULL,
+    EXPIRES_AT TEXT NULL, -- NULL : muet pour toujours
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (USER_ID, TARGET_TYPE, TARGET_ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_MUTES_TARGET ON MUTES(TARGET_TYPE, TARGET_ID);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS IDX_TAGS_POST_ID;
+DROP INDEX IF EXISTS IDX_GROUPS_MEMBERS_USER_ID;
+DROP TABLE IF EXISTS SUGGESTION_DISMISSALS;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS SUGGESTION_DISMISSALS (
+    USER_ID TEXT NOT NULL,
+    DISMISSED_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    PRIMARY KEY (USER_ID, DISMISSED_ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID),
+    FOREIGN KEY (DISMISSED_ID) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_GROUPS_MEMBERS_USER_ID ON GROUPS_MEMBERS(USER_ID);
+CREATE INDEX IF NOT EXISTS IDX_TAGS_POST_ID ON TAGS(POST_ID);
@@ -0,0 +1,19 @@
+-- Ajout du type FOLLOW_ACCEPTED (ID_TYPE = ID de REQUEST_FOLLOW) : SQLite ne permet pas de modifier un CHECK
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
+
+CREATE INDEX IF NOT EXISTS IDX_REQUEST_FOLLOW_RECEIVER ON REQUEST_FOLLOW(RECEIVER_ID, STATUS);
+CREATE INDEX IF NOT EXISTS IDX_REQUEST_FOLLOW_ASKER ON REQUEST_FOLLOW(ASKER_ID, STATUS);
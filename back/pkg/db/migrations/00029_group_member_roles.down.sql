@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS IDX_GROUPS_MEMBERS_GROUP_ID;
+ALTER TABLE GROUPS_MEMBERS DROP COLUMN ROLE;
@@ -0,0 +1,6 @@
+ALTER TABLE GROUPS_MEMBERS ADD COLUMN ROLE TEXT NOT NULL DEFAULT 'member' CHECK (ROLE IN ('owner', 'admin', 'moderator', 'member'));
+
+UPDATE GROUPS_MEMBERS SET ROLE = 'owner'
+WHERE EXISTS (SELECT 1 FROM ALL_GROUPS g WHERE g.ID = GROUPS_MEMBERS.GROUP_ID AND g.OWNER = GROUPS_MEMBERS.USER_ID);
+
+CREATE INDEX IF NOT EXISTS IDX_GROUPS_MEMBERS_GROUP_ID ON GROUPS_MEMBERS(GROUP_ID, USER_ID);
@@ -0,0 +1,17 @@
+CREATE TABLE NOTIFICATIONS_OLD (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_OLD (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS WHERE TYPE != 'TRANSFER_GROUP';
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_OLD RENAME TO NOTIFICATIONS;
+
+DROP TABLE IF EXISTS GROUP_TRANSFERS;
@@ -0,0 +1,28 @@
+-- Transfert de propriété en attente d'acceptation : un seul par groupe
+CREATE TABLE IF NOT EXISTS GROUP_TRANSFERS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    GROUP_ID TEXT NOT NULL UNIQUE,
+    FROM_ID TEXT NOT NULL,
+    TO_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (FROM_ID) REFERENCES USER(ID),
+    FOREIGN KEY (TO_ID) REFERENCES USER(ID)
+);
+
+-- Ajout du type TRANSFER_GROUP (ID_TYPE = ID de GROUP_TRANSFERS)
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
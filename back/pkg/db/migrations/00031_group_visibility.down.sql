@@ -0,0 +1 @@
+ALTER TABLE ALL_GROUPS DROP COLUMN VISIBILITY;
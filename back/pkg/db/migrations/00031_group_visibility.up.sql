@@ -0,0 +1,3 @@
+-- public : adhésion immédiate, posts lisibles par tous ; closed : visible, adhésion sur demande ;
+-- secret : invisible hors membres et invités, adhésion sur invitation uniquement
+ALTER TABLE ALL_GROUPS ADD COLUMN VISIBILITY TEXT NOT NULL DEFAULT 'closed' CHECK (VISIBILITY IN ('public', 'closed', 'secret'));
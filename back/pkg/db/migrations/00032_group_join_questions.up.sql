@@ -0,0 +1,54 @@
+-- Questions posées aux candidats d'un groupe (5 au plus, ordonnées par POSITION)
+CREATE TABLE IF NOT EXISTS GROUP_QUESTIONS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    GROUP_ID TEXT NOT NULL,
+    POSITION INT NOT NULL,
+    QUESTION TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (GROUP_ID, POSITION),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID)
+);
+
+-- Réponses d'une demande (ASK_GROUP) ; la question est recopiée pour rester lisible si elle change
+CREATE TABLE IF NOT EXISTS GROUP_ANSWERS (
+    ASK_ID TEXT NOT NULL,
+    POSITION INT NOT NULL,
+    QUESTION TEXT NOT NULL,
+    ANSWER TEXT NOT NULL,
+    PRIMARY KEY (ASK_ID, POSITION),
+    FOREIGN KEY (ASK_ID) REFERENCES ASK_GROUP(ID)
+);
+
+-- Décision sur une demande d'adhésion ; BLOCKED_UNTIL interdit une nouvelle demande jusqu'à cette date
+CREATE TABLE IF NOT EXISTS GROUP_JOIN_DECISIONS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    GROUP_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    DECIDED_BY TEXT NOT NULL,
+    ACCEPTED INT NOT NULL CHECK ( ACCEPTED IN (0, 1) ),
+    REASON TEXT NULL,
+    BLOCKED_UNTIL TEXT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID),
+    FOREIGN KEY (DECIDED_BY) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_GROUP_JOIN_DECISIONS_USER ON GROUP_JOIN_DECISIONS(GROUP_ID, USER_ID);
+
+-- Ajout des types GROUP_REQUEST_ACCEPTED et GROUP_REQUEST_DECLINED (ID_TYPE = ID de GROUP_JOIN_DECISIONS)
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
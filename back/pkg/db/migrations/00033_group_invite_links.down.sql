@@ -0,0 +1,4 @@
+ALTER TABLE GROUPS_MEMBERS DROP COLUMN INVITE_LINK_ID;
+
+DROP INDEX IF EXISTS IDX_GROUP_INVITE_LINKS_GROUP_ID;
+DROP TABLE IF EXISTS GROUP_INVITE_LINKS;
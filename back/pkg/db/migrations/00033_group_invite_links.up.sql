@@ -0,0 +1,19 @@
+-- Liens d'invitation partageables : expiration et nombre d'utilisations facultatifs, révocables
+CREATE TABLE IF NOT EXISTS GROUP_INVITE_LINKS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    GROUP_ID TEXT NOT NULL,
+    CODE TEXT NOT NULL UNIQUE,
+    CREATED_BY TEXT NOT NULL,
+    MAX_USES INTEGER,
+    USES INTEGER NOT NULL DEFAULT 0,
+    EXPIRES_AT TEXT,
+    REVOKED_AT TEXT,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (CREATED_BY) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_GROUP_INVITE_LINKS_GROUP_ID ON GROUP_INVITE_LINKS(GROUP_ID);
+
+-- Lien par lequel le membre a rejoint le groupe (NULL : demande, invitation ou création)
+ALTER TABLE GROUPS_MEMBERS ADD COLUMN INVITE_LINK_ID TEXT REFERENCES GROUP_INVITE_LINKS(ID);
@@ -0,0 +1,56 @@
+CREATE TABLE NOTIFICATIONS_OLD (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_OLD (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS
+WHERE TYPE NOT IN ('EVENT_UPDATED', 'EVENT_CANCELLED', 'EVENT_REMINDER', 'EVENT_WAITLIST_PROMOTED');
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_OLD RENAME TO NOTIFICATIONS;
+
+DROP TABLE IF EXISTS EVENT_REMINDERS;
+
+CREATE TABLE GROUPS_EVENT_OLD (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE ,
+    GROUP_ID TEXT NOT NULL,
+    SENDER TEXT NOT NULL,
+    TITLE TEXT NOT NULL,
+    DESCRIPTION TEXT NOT NULL,
+    OPTION_A TEXT NOT NULL,
+    OPTION_B TEXT NOT NULL,
+    DATE_TIME TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (SENDER) REFERENCES USER(ID),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID)
+);
+
+INSERT INTO GROUPS_EVENT_OLD (ID, GROUP_ID, SENDER, TITLE, DESCRIPTION, OPTION_A, OPTION_B, DATE_TIME, CREATED_AT)
+SELECT ID, GROUP_ID, SENDER, TITLE, DESCRIPTION, 'Going', 'Not going', strftime('%d/%m/%Y', START_AT), CREATED_AT FROM GROUPS_EVENT;
+
+CREATE TABLE RESPONSE_EVENT (
+    ID TEXT NOT NULL PRIMARY KEY,
+    USER_ID TEXT NOT NULL,
+    RESPONSE INT NOT NULL CHECK (RESPONSE = 1 OR RESPONSE = 2),
+    EVENT_ID TEXT NOT NULL,
+    GROUP_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID)
+);
+
+INSERT INTO RESPONSE_EVENT (ID, USER_ID, RESPONSE, EVENT_ID, GROUP_ID, CREATED_AT)
+SELECT r.ID, r.USER_ID, CASE r.STATUS WHEN 'going' THEN 1 ELSE 2 END, r.EVENT_ID, e.GROUP_ID, r.CREATED_AT
+FROM EVENT_RSVPS r JOIN GROUPS_EVENT e ON e.ID = r.EVENT_ID
+WHERE r.STATUS IN ('going', 'not_going');
+
+DROP TABLE EVENT_RSVPS;
+DROP TABLE GROUPS_EVENT;
+ALTER TABLE GROUPS_EVENT_OLD RENAME TO GROUPS_EVENT;
@@ -0,0 +1,77 @@
+-- Événements complets : début/fin en UTC avec le fuseau de l'organisateur, lieu, capacité, annulation.
+-- OPTION_A / OPTION_B sont remplacées par les réponses going / maybe / not_going.
+CREATE TABLE GROUPS_EVENT_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    GROUP_ID TEXT NOT NULL,
+    SENDER TEXT NOT NULL,
+    TITLE TEXT NOT NULL,
+    DESCRIPTION TEXT NOT NULL,
+    START_AT TEXT NOT NULL, -- UTC, 'YYYY-MM-DD HH:MM:SS'
+    END_AT TEXT,
+    TIME_ZONE TEXT NOT NULL DEFAULT 'UTC',
+    LOCATION TEXT,
+    CAPACITY INTEGER CHECK (CAPACITY IS NULL OR CAPACITY > 0), -- NULL : illimité
+    CANCELLED_AT TEXT,
+    UPDATED_AT TEXT,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (SENDER) REFERENCES USER(ID),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID)
+);
+
+-- DATE_TIME était saisi en JJ/MM/AAAA (minuit UTC) : repli sur la date de création s'il n'est pas lisible
+INSERT INTO GROUPS_EVENT_NEW (ID, GROUP_ID, SENDER, TITLE, DESCRIPTION, START_AT, CREATED_AT)
+SELECT ID, GROUP_ID, SENDER, TITLE, DESCRIPTION,
+       COALESCE(datetime(substr(DATE_TIME, 7, 4) || '-' || substr(DATE_TIME, 4, 2) || '-' || substr(DATE_TIME, 1, 2)),
+                datetime(DATE_TIME), CREATED_AT),
+       CREATED_AT
+FROM GROUPS_EVENT;
+
+-- waitlist : réponse going au-delà de la capacité, promue dans l'ordre d'arrivée
+CREATE TABLE IF NOT EXISTS EVENT_RSVPS (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    EVENT_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    STATUS TEXT NOT NULL CHECK (STATUS IN ('going', 'maybe', 'not_going', 'waitlist')),
+    CREATED_AT TEXT NOT NULL,
+    UPDATED_AT TEXT NOT NULL,
+    UNIQUE (EVENT_ID, USER_ID),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO EVENT_RSVPS (ID, EVENT_ID, USER_ID, STATUS, CREATED_AT, UPDATED_AT)
+SELECT ID, EVENT_ID, USER_ID, CASE RESPONSE WHEN 1 THEN 'going' ELSE 'not_going' END, CREATED_AT, CREATED_AT
+FROM RESPONSE_EVENT;
+
+DROP TABLE RESPONSE_EVENT;
+DROP TABLE GROUPS_EVENT;
+ALTER TABLE GROUPS_EVENT_NEW RENAME TO GROUPS_EVENT;
+
+CREATE INDEX IF NOT EXISTS IDX_GROUPS_EVENT_GROUP_ID ON GROUPS_EVENT(GROUP_ID);
+CREATE INDEX IF NOT EXISTS IDX_EVENT_RSVPS_EVENT_STATUS ON EVENT_RSVPS(EVENT_ID, STATUS);
+
+-- Rappels envoyés OFFSET_MINUTES avant le début aux participants (going / maybe)
+CREATE TABLE IF NOT EXISTS EVENT_REMINDERS (
+    EVENT_ID TEXT NOT NULL,
+    OFFSET_MINUTES INTEGER NOT NULL CHECK (OFFSET_MINUTES > 0),
+    SENT_AT TEXT,
+    PRIMARY KEY (EVENT_ID, OFFSET_MINUTES),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID)
+);
+
+-- Ajout des types EVENT_UPDATED, EVENT_CANCELLED, EVENT_REMINDER et EVENT_WAITLIST_PROMOTED (ID_TYPE = ID de l'événement)
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED', 'EVENT_UPDATED', 'EVENT_CANCELLED', 'EVENT_REMINDER', 'EVENT_WAITLIST_PROMOTED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
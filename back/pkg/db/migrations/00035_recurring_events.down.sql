@@ -0,0 +1,31 @@
+ALTER TABLE EVENT_REMINDERS ADD COLUMN SENT_AT TEXT;
+UPDATE EVENT_REMINDERS SET SENT_AT = (
+    SELECT s.SENT_AT FROM EVENT_REMINDERS_SENT s
+    WHERE s.EVENT_ID = EVENT_REMINDERS.EVENT_ID AND s.OCCURRENCE_AT = '' AND s.OFFSET_MINUTES = EVENT_REMINDERS.OFFSET_MINUTES);
+DROP TABLE IF EXISTS EVENT_REMINDERS_SENT;
+
+-- Seules les réponses aux événements uniques sont conservées
+CREATE TABLE EVENT_RSVPS_OLD (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    EVENT_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    STATUS TEXT NOT NULL CHECK (STATUS IN ('going', 'maybe', 'not_going', 'waitlist')),
+    CREATED_AT TEXT NOT NULL,
+    UPDATED_AT TEXT NOT NULL,
+    UNIQUE (EVENT_ID, USER_ID),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO EVENT_RSVPS_OLD (ID, EVENT_ID, USER_ID, STATUS, CREATED_AT, UPDATED_AT)
+SELECT ID, EVENT_ID, USER_ID, STATUS, CREATED_AT, UPDATED_AT FROM EVENT_RSVPS WHERE OCCURRENCE_AT = '';
+
+DROP INDEX IF EXISTS IDX_EVENT_RSVPS_EVENT_STATUS;
+DROP TABLE EVENT_RSVPS;
+ALTER TABLE EVENT_RSVPS_OLD RENAME TO EVENT_RSVPS;
+CREATE INDEX IF NOT EXISTS IDX_EVENT_RSVPS_EVENT_STATUS ON EVENT_RSVPS(EVENT_ID, STATUS);
+
+DROP TABLE IF EXISTS EVENT_OCCURRENCES;
+
+ALTER TABLE GROUPS_EVENT DROP COLUMN LAST_START_AT;
+ALTER TABLE GROUPS_EVENT DROP COLUMN RRULE;
@@ -0,0 +1,58 @@
+-- Récurrence (sous-ensemble RRULE normalisé, toujours bornée par UNTIL ou COUNT).
+-- LAST_START_AT : début de la dernière occurrence, pour filtrer les séries par période.
+ALTER TABLE GROUPS_EVENT ADD COLUMN RRULE TEXT;
+ALTER TABLE GROUPS_EVENT ADD COLUMN LAST_START_AT TEXT;
+UPDATE GROUPS_EVENT SET LAST_START_AT = START_AT;
+
+-- Occurrence modifiée ou annulée ; OCCURRENCE_AT est le début prévu par la règle (UTC).
+-- Les colonnes NULL reprennent les valeurs de la série.
+CREATE TABLE IF NOT EXISTS EVENT_OCCURRENCES (
+    EVENT_ID TEXT NOT NULL,
+    OCCURRENCE_AT TEXT NOT NULL,
+    TITLE TEXT,
+    DESCRIPTION TEXT,
+    START_AT TEXT,
+    END_AT TEXT,
+    LOCATION TEXT,
+    CANCELLED_AT TEXT,
+    UPDATED_AT TEXT NOT NULL,
+    PRIMARY KEY (EVENT_ID, OCCURRENCE_AT),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID)
+);
+
+-- Réponses par occurrence ('' pour un événement unique)
+CREATE TABLE EVENT_RSVPS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    EVENT_ID TEXT NOT NULL,
+    OCCURRENCE_AT TEXT NOT NULL DEFAULT '',
+    USER_ID TEXT NOT NULL,
+    STATUS TEXT NOT NULL CHECK (STATUS IN ('going', 'maybe', 'not_going', 'waitlist')),
+    CREATED_AT TEXT NOT NULL,
+    UPDATED_AT TEXT NOT NULL,
+    UNIQUE (EVENT_ID, OCCURRENCE_AT, USER_ID),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO EVENT_RSVPS_NEW (ID, EVENT_ID, USER_ID, STATUS, CREATED_AT, UPDATED_AT)
+SELECT ID, EVENT_ID, USER_ID, STATUS, CREATED_AT, UPDATED_AT FROM EVENT_RSVPS;
+
+DROP INDEX IF EXISTS IDX_EVENT_RSVPS_EVENT_STATUS;
+DROP TABLE EVENT_RSVPS;
+ALTER TABLE EVENT_RSVPS_NEW RENAME TO EVENT_RSVPS;
+CREATE INDEX IF NOT EXISTS IDX_EVENT_RSVPS_EVENT_STATUS ON EVENT_RSVPS(EVENT_ID, OCCURRENCE_AT, STATUS);
+
+-- Rappels envoyés, par occurrence ; EVENT_REMINDERS ne garde que les délais choisis
+CREATE TABLE IF NOT EXISTS EVENT_REMINDERS_SENT (
+    EVENT_ID TEXT NOT NULL,
+    OCCURRENCE_AT TEXT NOT NULL DEFAULT '',
+    OFFSET_MINUTES INTEGER NOT NULL,
+    SENT_AT TEXT NOT NULL,
+    PRIMARY KEY (EVENT_ID, OCCURRENCE_AT, OFFSET_MINUTES),
+    FOREIGN KEY (EVENT_ID) REFERENCES GROUPS_EVENT(ID)
+);
+
+INSERT INTO EVENT_REMINDERS_SENT (EVENT_ID, OFFSET_MINUTES, SENT_AT)
+SELECT EVENT_ID, OFFSET_MINUTES, SENT_AT FROM EVENT_REMINDERS WHERE SENT_AT IS NOT NULL;
+
+ALTER TABLE EVENT_REMINDERS DROP COLUMN SENT_AT;
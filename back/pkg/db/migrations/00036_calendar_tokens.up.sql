@@ -0,0 +1,7 @@
+-- Jeton secret du flux iCalendar personnel (abonnement sans cookie), régénérable
+CREATE TABLE IF NOT EXISTS CALENDAR_TOKENS (
+    USER_ID TEXT NOT NULL PRIMARY KEY,
+    TOKEN TEXT NOT NULL UNIQUE,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
@@ -0,0 +1,30 @@
+CREATE TABLE NOTIFICATIONS_OLD (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED', 'EVENT_UPDATED', 'EVENT_CANCELLED', 'EVENT_REMINDER', 'EVENT_WAITLIST_PROMOTED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_OLD (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS
+WHERE TYPE NOT IN ('GROUP_POST_APPROVED', 'GROUP_POST_REJECTED');
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_OLD RENAME TO NOTIFICATIONS;
+
+-- Les posts jamais validés disparaissent avec la file (ils n'ont ni réaction ni commentaire)
+DELETE FROM TAGS WHERE POST_ID IN (SELECT ID FROM POSTS WHERE STATUS <> 'published');
+DELETE FROM POST_MENTIONS WHERE POST_ID IN (SELECT ID FROM POSTS WHERE STATUS <> 'published');
+DELETE FROM POSTS WHERE STATUS <> 'published';
+
+DROP INDEX IF EXISTS IDX_POSTS_GROUP_STATUS;
+ALTER TABLE POSTS DROP COLUMN REJECT_REASON;
+ALTER TABLE POSTS DROP COLUMN REVIEWED_AT;
+ALTER TABLE POSTS DROP COLUMN REVIEWED_BY;
+ALTER TABLE POSTS DROP COLUMN STATUS;
+
+ALTER TABLE GROUPS_MEMBERS DROP COLUMN TRUSTED;
+ALTER TABLE ALL_GROUPS DROP COLUMN POST_APPROVAL;
@@ -0,0 +1,27 @@
+-- File de validation des posts de groupe : activable par groupe, contournée par les membres de confiance
+ALTER TABLE ALL_GROUPS ADD COLUMN POST_APPROVAL INTEGER NOT NULL DEFAULT 0 CHECK (POST_APPROVAL IN (0, 1));
+ALTER TABLE GROUPS_MEMBERS ADD COLUMN TRUSTED INTEGER NOT NULL DEFAULT 0 CHECK (TRUSTED IN (0, 1));
+
+-- Un post en attente ou refusé n'est visible que de son auteur et des modérateurs du groupe
+ALTER TABLE POSTS ADD COLUMN STATUS TEXT NOT NULL DEFAULT 'published' CHECK (STATUS IN ('published', 'pending', 'rejected'));
+ALTER TABLE POSTS ADD COLUMN REVIEWED_BY TEXT REFERENCES USER(ID);
+ALTER TABLE POSTS ADD COLUMN REVIEWED_AT TEXT;
+ALTER TABLE POSTS ADD COLUMN REJECT_REASON TEXT;
+
+CREATE INDEX IF NOT EXISTS IDX_POSTS_GROUP_STATUS ON POSTS(GROUP_ID, STATUS);
+
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED', 'EVENT_UPDATED', 'EVENT_CANCELLED', 'EVENT_REMINDER', 'EVENT_WAITLIST_PROMOTED', 'GROUP_POST_APPROVED', 'GROUP_POST_REJECTED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
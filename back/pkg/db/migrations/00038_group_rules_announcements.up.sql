@@ -0,0 +1,44 @@
+-- Règlement versionné du groupe : chaque modification crée une nouvelle version à accepter
+CREATE TABLE IF NOT EXISTS GROUP_RULES (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    VERSION INTEGER NOT NULL,
+    CONTENT TEXT NOT NULL, -- vide : règlement retiré
+    CREATED_BY TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (GROUP_ID, VERSION),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (CREATED_BY) REFERENCES USER(ID)
+);
+
+-- Dernière version du règlement acceptée par le membre (0 : aucune)
+ALTER TABLE GROUPS_MEMBERS ADD COLUMN RULES_VERSION INTEGER NOT NULL DEFAULT 0;
+
+-- Annonces du groupe, publiées par les administrateurs
+CREATE TABLE IF NOT EXISTS GROUP_ANNOUNCEMENTS (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    CONTENT TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_GROUP_ANNOUNCEMENTS_GROUP ON GROUP_ANNOUNCEMENTS(GROUP_ID, CREATED_AT);
+
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED', 'EVENT_UPDATED', 'EVENT_CANCELLED', 'EVENT_REMINDER', 'EVENT_WAITLIST_PROMOTED', 'GROUP_POST_APPROVED', 'GROUP_POST_REJECTED', 'GROUP_ANNOUNCEMENT', 'GROUP_RULES_UPDATED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
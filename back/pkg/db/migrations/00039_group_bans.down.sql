@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS IDX_GROUPS_MEMBERS_JOINED;
+DROP TABLE IF EXISTS GROUP_BANS;
@@ -0,0 +1,17 @@
+-- Bannissement d'un groupe : contrairement à une exclusion, empêche de revenir jusqu'à la levée ou l'expiration
+CREATE TABLE IF NOT EXISTS GROUP_BANS (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    BANNED_BY TEXT NOT NULL,
+    REASON TEXT,
+    EXPIRES_AT TEXT, -- NULL : jusqu'à la levée du bannissement
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (GROUP_ID, USER_ID),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID),
+    FOREIGN KEY (BANNED_BY) REFERENCES USER(ID)
+);
+
+-- Liste des membres paginée par date d'adhésion
+CREATE INDEX IF NOT EXISTS IDX_GROUPS_MEMBERS_JOINED ON GROUPS_MEMBERS(GROUP_ID, CREATED_AT, ID);
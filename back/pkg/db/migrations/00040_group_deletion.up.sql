@@ -0,0 +1,28 @@
+-- Archive des groupes supprimés : sert de référence aux notifications GROUP_DELETED une fois le groupe effacé
+CREATE TABLE GROUP_DELETIONS (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    TITLE TEXT NOT NULL,
+    OWNER TEXT NOT NULL,
+    MEMBER_COUNT INTEGER NOT NULL,
+    DELETED_BY TEXT NOT NULL,
+    DELETED_AT TEXT NOT NULL,
+    FOREIGN KEY (OWNER) REFERENCES USER(ID),
+    FOREIGN KEY (DELETED_BY) REFERENCES USER(ID)
+);
+
+CREATE TABLE NOTIFICATIONS_NEW (
+    ID TEXT NOT NULL PRIMARY KEY UNIQUE,
+    TYPE TEXT NOT NULL CHECK(TYPE IN ('LIKE', 'DISLIKE', 'COMMENT', 'COMMENT_LIKE', 'COMMENT_DISLIKE', 'ASK_FOLLOW', 'ASK_GROUP', 'INVITE_GROUP','EVENT_GROUP', 'FOLLOW_ACCEPTED', 'TRANSFER_GROUP', 'GROUP_REQUEST_ACCEPTED', 'GROUP_REQUEST_DECLINED', 'EVENT_UPDATED', 'EVENT_CANCELLED', 'EVENT_REMINDER', 'EVENT_WAITLIST_PROMOTED', 'GROUP_POST_APPROVED', 'GROUP_POST_REJECTED', 'GROUP_ANNOUNCEMENT', 'GROUP_RULES_UPDATED', 'GROUP_DELETED')),
+    USER_ID TEXT NOT NULL, -- La personne a qui envoyer la notif
+    ID_TYPE TEXT NOT NULL,
+    READ INT DEFAULT 0 NOT NULL CHECK ( READ IN (0,1)),
+    CREATED_AT TEXT NOT NULL DEFAULT (DATETIME('now')),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+INSERT INTO NOTIFICATIONS_NEW (ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT)
+SELECT ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT FROM NOTIFICATIONS;
+
+DROP TABLE NOTIFICATIONS;
+ALTER TABLE NOTIFICATIONS_NEW RENAME TO NOTIFICATIONS;
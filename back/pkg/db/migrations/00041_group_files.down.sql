@@ -0,0 +1,6 @@
+ALTER TABLE ALL_GROUPS DROP COLUMN FILE_TYPES;
+ALTER TABLE ALL_GROUPS DROP COLUMN FILE_QUOTA;
+DROP INDEX IF EXISTS IDX_GROUP_FILES_FOLDER;
+DROP INDEX IF EXISTS IDX_GROUP_FILE_FOLDERS_PARENT;
+DROP TABLE IF EXISTS GROUP_FILES;
+DROP TABLE IF EXISTS GROUP_FILE_FOLDERS;
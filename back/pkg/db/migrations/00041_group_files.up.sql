@@ -0,0 +1,34 @@
+-- Bibliothèque de fichiers partagés des groupes
+CREATE TABLE IF NOT EXISTS GROUP_FILE_FOLDERS (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    PARENT_ID TEXT, -- NULL : racine de la bibliothèque
+    NAME TEXT NOT NULL,
+    CREATED_BY TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (PARENT_ID) REFERENCES GROUP_FILE_FOLDERS(ID),
+    FOREIGN KEY (CREATED_BY) REFERENCES USER(ID)
+);
+
+CREATE TABLE IF NOT EXISTS GROUP_FILES (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    FOLDER_ID TEXT, -- NULL : racine de la bibliothèque
+    NAME TEXT NOT NULL, -- nom d'origine, renvoyé au téléchargement
+    STORED_NAME TEXT NOT NULL UNIQUE, -- nom du fichier dans Images/groupFiles
+    MIME_TYPE TEXT NOT NULL, -- type détecté à l'envoi
+    SIZE INTEGER NOT NULL,
+    UPLOADED_BY TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (FOLDER_ID) REFERENCES GROUP_FILE_FOLDERS(ID),
+    FOREIGN KEY (UPLOADED_BY) REFERENCES USER(ID)
+);
+
+CREATE INDEX IF NOT EXISTS IDX_GROUP_FILE_FOLDERS_PARENT ON GROUP_FILE_FOLDERS(GROUP_ID, PARENT_ID);
+CREATE INDEX IF NOT EXISTS IDX_GROUP_FILES_FOLDER ON GROUP_FILES(GROUP_ID, FOLDER_ID);
+
+-- Quota en octets et types MIME acceptés (liste séparée par des virgules, NULL : tous les types pris en charge)
+ALTER TABLE ALL_GROUPS ADD COLUMN FILE_QUOTA INTEGER NOT NULL DEFAULT 104857600;
+ALTER TABLE ALL_GROUPS ADD COLUMN FILE_TYPES TEXT;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS IDX_MESSAGES_CHANNEL;
+ALTER TABLE MESSAGES DROP COLUMN CHANNEL_ID;
+DROP TABLE IF EXISTS GROUP_CHANNEL_UNSUBSCRIBED;
+DROP TABLE IF EXISTS GROUP_CHANNELS;
@@ -0,0 +1,38 @@
+-- Salons de discussion des groupes (#general, #events...), chacun avec son propre fil de messages
+CREATE TABLE IF NOT EXISTS GROUP_CHANNELS (
+    ID TEXT NOT NULL PRIMARY KEY,
+    GROUP_ID TEXT NOT NULL,
+    NAME TEXT NOT NULL,
+    DESCRIPTION TEXT NOT NULL DEFAULT '',
+    ROLES TEXT, -- rôles autorisés séparés par des virgules, NULL : tous les membres
+    IS_DEFAULT INT NOT NULL DEFAULT 0 CHECK ( IS_DEFAULT IN (0, 1) ),
+    CREATED_BY TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    UNIQUE (GROUP_ID, NAME),
+    FOREIGN KEY (GROUP_ID) REFERENCES ALL_GROUPS(ID),
+    FOREIGN KEY (CREATED_BY) REFERENCES USER(ID)
+);
+
+-- Les membres sont abonnés à tous les salons accessibles, sauf ceux listés ici
+CREATE TABLE IF NOT EXISTS GROUP_CHANNEL_UNSUBSCRIBED (
+    CHANNEL_ID TEXT NOT NULL,
+    USER_ID TEXT NOT NULL,
+    CREATED_AT TEXT NOT NULL,
+    PRIMARY KEY (CHANNEL_ID, USER_ID),
+    FOREIGN KEY (CHANNEL_ID) REFERENCES GROUP_CHANNELS(ID),
+    FOREIGN KEY (USER_ID) REFERENCES USER(ID)
+);
+
+ALTER TABLE MESSAGES ADD COLUMN CHANNEL_ID TEXT NULL REFERENCES GROUP_CHANNELS(ID);
+CREATE INDEX IF NOT EXISTS IDX_MESSAGES_CHANNEL ON MESSAGES(CHANNEL_ID, CREATED_AT);
+
+-- Chaque groupe existant reçoit son salon #general, qui reprend l'historique du chat
+INSERT INTO GROUP_CHANNELS(ID, GROUP_ID, NAME, IS_DEFAULT, CREATED_BY, CREATED_AT)
+SELECT lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' ||
+             hex(randomblob(2)) || '-' || hex(randomblob(6))),
+       ID, 'general', 1, OWNER, datetime('now')
+FROM ALL_GROUPS;
+
+UPDATE MESSAGES SET CHANNEL_ID = (
+    SELECT c.ID FROM GROUP_CHANNELS c WHERE c.GROUP_ID = MESSAGES.GROUP_ID AND c.IS_DEFAULT = 1
+) WHERE IFNULL(GROUP_ID, '') <> '';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS IDX_GROUPS_MEMBERS_UNIQUE;
@@ -0,0 +1,11 @@
+-- Un utilisateur n'est membre qu'une fois d'un groupe : on garde la ligne au rôle le plus élevé, puis la plus ancienne
+DELETE FROM GROUPS_MEMBERS WHERE rowid NOT IN (
+    SELECT rowid FROM (
+        SELECT rowid, ROW_NUMBER() OVER (
+            PARTITION BY GROUP_ID, USER_ID
+            ORDER BY CASE ROLE WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 WHEN 'moderator' THEN 2 ELSE 3 END, CREATED_AT, ID
+        ) AS RN FROM GROUPS_MEMBERS
+    ) WHERE RN = 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS IDX_GROUPS_MEMBERS_UNIQUE ON GROUPS_MEMBERS(GROUP_ID, USER_ID);
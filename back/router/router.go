@@ -44,6 +44,14 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("GET /api/groupImages/", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleImages(w, r, db)
 	})
+	// Image Story
+	mux.HandleFunc("GET /api/storyImages/", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleImages(w, r, db)
+	})
+	// Group library file (members only)
+	mux.HandleFunc("GET /api/groupFiles/{name}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGroupFile(w, r, db)
+	})
 
 	// HOME
 	mux.HandleFunc("GET /api/home/post", func(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +82,18 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("PATCH /api/post/", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleUpdatePost(w, r, db)
 	})
+	// comment settings (policy / lock)
+	mux.HandleFunc("PATCH /api/post/{id}/comments", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCommentSettings(w, r, db)
+	})
+	// comment moderation log
+	mux.HandleFunc("GET /api/post/{id}/moderation", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetCommentModeration(w, r, db)
+	})
+	// author insights (?days=30&postId=)
+	mux.HandleFunc("GET /api/insights", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetInsights(w, r, db)
+	})
 	// get private member post
 	mux.HandleFunc("GET /api/privateMember", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleGetPrivateMember(w, r, db)
@@ -83,6 +103,56 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 		handlers.HandleDeletePrivateMember(w, r, db)
 	})
 
+	// STORIES
+	// create a story
+	mux.HandleFunc("POST /api/story", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateStory(w, r, db)
+	})
+	// stories tray (authors with active stories)
+	mux.HandleFunc("GET /api/story/tray", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleStoryTray(w, r, db)
+	})
+	// active stories of a user (?user=)
+	mux.HandleFunc("GET /api/story", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetUserStories(w, r, db)
+	})
+	// mark a story as viewed
+	mux.HandleFunc("POST /api/story/{id}/view", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleViewStory(w, r, db)
+	})
+	// story viewers (author only)
+	mux.HandleFunc("GET /api/story/{id}/viewers", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetStoryViewers(w, r, db)
+	})
+	// delete a story
+	mux.HandleFunc("DELETE /api/story/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteStory(w, r, db)
+	})
+
+	// AUDIENCE LISTS (close friends, family...)
+	mux.HandleFunc("GET /api/audience", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetAudienceLists(w, r, db)
+	})
+	mux.HandleFunc("POST /api/audience", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateAudienceList(w, r, db)
+	})
+	mux.HandleFunc("GET /api/audience/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetAudienceList(w, r, db)
+	})
+	mux.HandleFunc("PATCH /api/audience/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRenameAudienceList(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/audience/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteAudienceList(w, r, db)
+	})
+	// add members (users=...) / remove a member (?user=)
+	mux.HandleFunc("POST /api/audience/{id}/members", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleAddAudienceMembers(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/audience/{id}/members", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRemoveAudienceMember(w, r, db)
+	})
+
 	// COMMENT
 	// get comment donne déja dans /api/post?=
 	mux.HandleFunc("GET /api/comment/", func(w http.ResponseWriter, r *http.Request) {
@@ -104,6 +174,10 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("PATCH /api/comment/", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleUpdateComment(w, r, db)
 	})
+	// hide / unhide comment (post owner)
+	mux.HandleFunc("PATCH /api/comment/{id}/hide", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleHideComment(w, r, db)
+	})
 
 	//FOLLOWER
 	// ask to follow
@@ -134,10 +208,62 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("GET /api/user/listfollow", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleFollow(w, r, db)
 	})
+	// followers of target that the user follows
+	mux.HandleFunc("GET /api/user/{id}/mutuals", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetMutuals(w, r, db)
+	})
 	// abort follow
-	mux.HandleFunc("GET /api/user/abort", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("DELETE /api/user/abort", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleAbortFollow(w, r, db)
 	})
+	// list pending follow requests (direction=in|out)
+	mux.HandleFunc("GET /api/user/requests", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetFollowRequests(w, r, db)
+	})
+	// bulk accept follow requests
+	mux.HandleFunc("POST /api/user/requests/accept", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBulkFollowRequests(w, r, db, true)
+	})
+	// bulk decline follow requests
+	mux.HandleFunc("POST /api/user/requests/decline", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBulkFollowRequests(w, r, db, false)
+	})
+
+	// block user
+	mux.HandleFunc("POST /api/user/{id}/block", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBlockUser(w, r, db)
+	})
+	// unblock user
+	mux.HandleFunc("DELETE /api/user/{id}/block", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUnblockUser(w, r, db)
+	})
+	// list blocked users
+	mux.HandleFunc("GET /api/user/blocked", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetBlockedUsers(w, r, db)
+	})
+
+	// follow suggestions
+	mux.HandleFunc("GET /api/user/suggestions", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetSuggestions(w, r, db)
+	})
+	// dismiss a suggestion
+	mux.HandleFunc("DELETE /api/user/suggestions", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDismissSuggestion(w, r, db)
+	})
+
+	// MUTE
+	// list active mutes
+	mux.HandleFunc("GET /api/mute", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetMutes(w, r, db)
+	})
+	// mute user / conversation / group
+	mux.HandleFunc("POST /api/mute", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleMute(w, r, db)
+	})
+	// unmute
+	mux.HandleFunc("DELETE /api/mute", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUnmute(w, r, db)
+	})
 
 	//USER
 	// get personal infos
@@ -152,6 +278,10 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("PATCH /api/user/public", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleSwitchPublicStatus(w, r, db)
 	})
+	// display preference for flagged content (collapse / expand)
+	mux.HandleFunc("PATCH /api/user/sensitive", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSensitivePreference(w, r, db)
+	})
 	// update user data --
 	mux.HandleFunc("PATCH /api/user/update", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleUpdateUserInfo(w, r, db)
@@ -197,7 +327,7 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 		handlers.HandleGetEventGroup(w, r, db)
 	})
 
-	// get all members
+	// get members (?groupId= ; search, role, cursor, limit)
 	mux.HandleFunc("GET /api/group/members", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleGetGroupMembers(w, r, db)
 	})
@@ -214,19 +344,29 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("DELETE /api/group/delete", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleDeleteGroup(w, r, db)
 	})
-	// delete member X
+	// kick member X (can ask to join again)
 	mux.HandleFunc("DELETE /api/group/member", func(w http.ResponseWriter, r *http.Request) {
-		handlers.HandleBanMemberGroup(w, r, db)
+		handlers.HandleKickGroupMember(w, r, db)
+	})
+	// bans (?groupId=&userId= ; duration=1d|1w|1m|1y, reason=...)
+	mux.HandleFunc("GET /api/group/bans", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupBans(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/ban", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleBanGroupMember(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/group/ban", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUnbanGroupMember(w, r, db)
 	})
 	// ask to join X
 	mux.HandleFunc("POST /api/group/ask", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleAskToJoinGroup(w, r, db)
 	})
-	// send message group -- à vérifier
+	// send message group (channelID facultatif : salon par défaut) -- à vérifier
 	mux.HandleFunc("POST /api/group/message", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleMessageGroups(w, r, db, hub)
 	})
-	// get message group -- à vérifier
+	// get message group (?groupID=&channelID=) -- à vérifier
 	mux.HandleFunc("GET /api/group/message", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleGetMessageGroups(w, r, db)
 	})
@@ -242,10 +382,47 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("POST /api/group/invite", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleInviteGroup(w, r, db)
 	})
+	// invite links (?groupId= ; expires=1h|1d|1w|1m, maxUses ; revoke ?linkId=)
+	mux.HandleFunc("GET /api/group/inviteLinks", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupInviteLinks(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/inviteLinks", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateGroupInviteLink(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/group/inviteLinks", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRevokeGroupInviteLink(w, r, db)
+	})
+	// preview (GET) or join (POST) through an invite link
+	mux.HandleFunc("GET /api/group/invite/{code}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGroupInviteCode(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/invite/{code}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGroupInviteCode(w, r, db)
+	})
 	// create event
 	mux.HandleFunc("POST /api/group/event", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleCreateEvent(w, r, db)
 	})
+	// update event (?eventId=, same fields as create) / cancel event
+	mux.HandleFunc("PUT /api/group/event", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUpdateEvent(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/event/cancel", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCancelEvent(w, r, db)
+	})
+	// group resources by id: events.ics, stats, stats.csv (?from=&to=) (un motif /api/group/{id}/events.ics serait en conflit avec /api/group/invite/{code})
+	mux.HandleFunc("GET /api/group/{id}/{resource}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("resource") {
+		case "events.ics":
+			handlers.HandleGroupEventsICS(w, r, db)
+		case "stats":
+			handlers.HandleGetGroupStats(w, r, db)
+		case "stats.csv":
+			handlers.HandleGroupStatsCSV(w, r, db)
+		default:
+			http.NotFound(w, r)
+		}
+	})
 	// response event
 	mux.HandleFunc("POST /api/group/response", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleResponseEvent(w, r, db)
@@ -259,6 +436,116 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 	mux.HandleFunc("POST /api/group/acceptAsk", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleAcceptAskToJoinGroup(w, r, db)
 	})
+	// decline ask to join (?groupId=&userId=, reason / block=1w|1m|3m|1y)
+	mux.HandleFunc("POST /api/group/declineAsk", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeclineAskToJoinGroup(w, r, db)
+	})
+	// join questions (questions=..., max 5)
+	mux.HandleFunc("GET /api/group/questions", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupQuestions(w, r, db)
+	})
+	mux.HandleFunc("PUT /api/group/questions", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSetGroupQuestions(w, r, db)
+	})
+	// promote / demote member (?groupId=&userId=&role=)
+	mux.HandleFunc("POST /api/group/member/promote", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleChangeGroupRole(w, r, db, true)
+	})
+	mux.HandleFunc("POST /api/group/member/demote", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleChangeGroupRole(w, r, db, false)
+	})
+	// post approval queue (?groupId= / ?postId=, reason=... pour un refus)
+	mux.HandleFunc("PUT /api/group/postApproval", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSetGroupPostApproval(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/member/trust", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSetGroupMemberTrusted(w, r, db)
+	})
+	mux.HandleFunc("GET /api/group/post/pending", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetPendingGroupPosts(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/post/approve", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleApproveGroupPost(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/post/reject", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleRejectGroupPost(w, r, db)
+	})
+	// versioned rules (?groupId=, history=true / content=... / version=N)
+	mux.HandleFunc("GET /api/group/rules", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupRules(w, r, db)
+	})
+	mux.HandleFunc("PUT /api/group/rules", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSetGroupRules(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/rules/accept", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleAcceptGroupRules(w, r, db)
+	})
+	// announcements (?groupId= / ?id=), admins only, notifications ignore group mutes
+	mux.HandleFunc("GET /api/group/announcements", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupAnnouncements(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/announcements", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateGroupAnnouncement(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/group/announcements", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteGroupAnnouncement(w, r, db)
+	})
+	// leave group (owner: succession to the oldest admin, else the oldest member)
+	mux.HandleFunc("POST /api/group/leave", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleLeaveGroup(w, r, db)
+	})
+	// ownership transfer (?groupId=&userId=), accepted or declined by the new owner
+	mux.HandleFunc("POST /api/group/transfer", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleTransferGroup(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/transfer/accept", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleAnswerGroupTransfer(w, r, db, true)
+	})
+	mux.HandleFunc("POST /api/group/transfer/decline", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleAnswerGroupTransfer(w, r, db, false)
+	})
+	// delete group message (author or moderator)
+	mux.HandleFunc("DELETE /api/group/message", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteGroupMessage(w, r, db)
+	})
+	// shared file library (?groupId=&folderId= / ?id=), members only ; settings: quota, types (owner/admin)
+	mux.HandleFunc("GET /api/group/files", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupFiles(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/files", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUploadGroupFile(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/group/files", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteGroupFile(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/files/folder", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateGroupFolder(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/group/files/folder", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteGroupFolder(w, r, db)
+	})
+	mux.HandleFunc("PUT /api/group/files/settings", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleSetGroupFileSettings(w, r, db)
+	})
+	// chat channels (?groupId= / ?id=), optionally restricted to roles ; managed by owner/admin
+	mux.HandleFunc("GET /api/group/channels", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGetGroupChannels(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/channels", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCreateGroupChannel(w, r, db)
+	})
+	mux.HandleFunc("PUT /api/group/channels", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleUpdateGroupChannel(w, r, db)
+	})
+	mux.HandleFunc("DELETE /api/group/channels", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleDeleteGroupChannel(w, r, db)
+	})
+	mux.HandleFunc("POST /api/group/channels/subscribe", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGroupChannelSubscription(w, r, db, true)
+	})
+	mux.HandleFunc("DELETE /api/group/channels/subscribe", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleGroupChannelSubscription(w, r, db, false)
+	})
 
 	// CHECK
 	mux.HandleFunc("GET /api/check/username", func(w http.ResponseWriter, r *http.Request) {
@@ -280,6 +567,17 @@ func Handlers(mux *http.ServeMux, db *sql.DB, hub *websocketFile.Hub) {
 		handlers.HandleReadNotifications(w, r, db)
 	})
 
+	// Calendar : adresse du flux personnel (POST la régénère), flux /api/calendar/{token}.ics sans cookie
+	mux.HandleFunc("GET /api/calendar/token", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCalendarToken(w, r, db)
+	})
+	mux.HandleFunc("POST /api/calendar/token", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCalendarToken(w, r, db)
+	})
+	mux.HandleFunc("GET /api/calendar/{file}", func(w http.ResponseWriter, r *http.Request) {
+		handlers.HandleCalendarFeed(w, r, db)
+	})
+
 	// Tags
 	mux.HandleFunc("GET /api/tag", func(w http.ResponseWriter, r *http.Request) {
 		handlers.HandleSendPostWithTags(w, r, db)
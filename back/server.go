@@ -2,11 +2,13 @@ package main
 
 import (
 	"database/sql"
+	"flag"
 	"log"
 	"net/http"
 	"social-network/middlewares"
 	"social-network/pkg/db/sqlite"
 	"social-network/router"
+	"social-network/services"
 	"social-network/websocketFile"
 	"time"
 )
@@ -31,6 +33,9 @@ func applyMiddlewares(handler http.Handler, middlewares []interface{}, db *sql.D
 }
 
 func main() {
+	reconcileGroups := flag.Bool("reconcile-groups", false, "supprime les données des groupes déjà effacés puis quitte")
+	flag.Parse()
+
 	// Applique les migrations
 	sqlite.StartMigration()
 	log.Println("Migrations terminées.")
@@ -46,8 +51,36 @@ func main() {
 		}
 	}()
 
+	// Nettoyage ponctuel des données orphelines, sans lancer le serveur
+	if *reconcileGroups {
+		report, err := services.ReconcileGroupOrphans(db)
+		if err != nil {
+			log.Fatalf("Erreur lors du nettoyage des groupes orphelins : %v", err)
+		}
+		log.Printf("%d groupes orphelins nettoyés : %d lignes et %d images supprimées", report.Groups, report.Rows, report.Files)
+		return
+	}
+
 	hub := websocketFile.NewHub(db)
 
+	// Suppression périodique des stories expirées
+	services.StartStoryCleanup(db, 10*time.Minute)
+
+	// Suppression périodique des sourdines expirées
+	services.StartMuteCleanup(db, 10*time.Minute)
+
+	// Expiration des demandes d'abonnement restées en attente
+	services.StartFollowRequestExpiry(db, time.Hour)
+
+	// Recalcul périodique des suggestions d'abonnement en cache
+	services.StartSuggestionsRefresh(db, 30*time.Minute)
+
+	// Écriture par lots des vues de posts
+	services.StartPostViewFlusher(db, 30*time.Second)
+
+	// Rappels des événements de groupe
+	services.StartEventReminders(db, time.Minute)
+
 	// Utilisation NewServeMux pour les handlers
 	mux := http.NewServeMux()
 	router.Handlers(mux, db, hub)
@@ -3,82 +3,168 @@ package services
 import (
 	"database/sql"
 	"github.com/pkg/errors"
+	"sort"
+	"time"
 )
 
 type EventInfos struct {
-	Id       string `json:"id"`
-	GroupId  string `json:"group_id"`
-	Sender   User   `json:"sender"`
-	Title    string `json:"title"`
-	Desc     string `json:"desc"`
-	OptionA  string `json:"option_a"`
-	OptionB  string `json:"option_b"`
-	Created  string `json:"created_at"`
-	DateTime string `json:"date_time"`
-	Choice   int    `json:"choice"`
-	CountA   int    `json:"count_a"`
-	CountB   int    `json:"count_b"`
+	Id         string `json:"id"`
+	GroupId    string `json:"group_id"`
+	Occurrence string `json:"occurrence"` // début prévu de l'occurrence (RFC 3339 UTC), vide pour un événement unique
+	Recurrence string `json:"recurrence"` // règle RRULE de la série, vide pour un événement unique
+	Modified   bool   `json:"modified"`   // occurrence modifiée individuellement
+	Sender     User   `json:"sender"`
+	Title      string `json:"title"`
+	Desc       string `json:"desc"`
+	StartAt    string `json:"start_at"` // RFC 3339 dans le fuseau de l'événement
+	EndAt      string `json:"end_at"`
+	TimeZone   string `json:"time_zone"`
+	Location   string `json:"location"`
+	Capacity   int    `json:"capacity"` // 0 : illimité
+	Cancelled  bool   `json:"cancelled"`
+	Created    string `json:"created_at"`
+	Updated    string `json:"updated_at"`
+	Reminders  []int  `json:"reminders"` // minutes avant le début
+	CanEdit    bool   `json:"can_edit"`
+	Choice     string `json:"choice"` // réponse de l'utilisateur, vide si aucune
+	// Rang dans la liste d'attente (à partir de 1), 0 hors liste d'attente
+	WaitlistPosition int `json:"waitlist_position"`
+	CountGoing       int `json:"count_going"`
+	CountMaybe       int `json:"count_maybe"`
+	CountNotGoing    int `json:"count_not_going"`
+	CountWaitlist    int `json:"count_waitlist"`
 }
 
-func SendEventInfos(db *sql.DB, userId, groupId string) ([]EventInfos, error) {
+// SendEventInfos renvoie les occurrences des événements du groupe qui commencent dans [from, to),
+// triées par début. Les séries récurrentes sont développées à la volée, rien n'est stocké par occurrence
+// hormis les réponses et les modifications.
+func SendEventInfos(db *sql.DB, userId, groupId string, from, to time.Time) ([]EventInfos, error) {
 	var eventInfos []EventInfos
-	var isMember bool
 
-	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-	err := db.QueryRow(query, userId, groupId).Scan(&isMember)
+	role, err := groupRole(db, userId, groupId)
 	if err != nil {
 		return eventInfos, err
 	}
-
-	if !isMember {
+	if role == "" {
 		return eventInfos, errors.New("user is not member of group")
 	}
 
-	queryRows := `SELECT ID, SENDER, DESCRIPTION, OPTION_A, OPTION_B, CREATED_AT, TITLE, DATE_TIME FROM GROUPS_EVENT WHERE GROUP_ID = ?`
-	rows, err := db.Query(queryRows, groupId)
+	// Séries ayant au moins une occurrence prévue dans la fenêtre, ou une occurrence déplacée dedans
+	fromSQL, toSQL := from.UTC().Format(eventSQLTimeLayout), to.UTC().Format(eventSQLTimeLayout)
+	queryRows := eventSeriesSelect + ` WHERE e.GROUP_ID = ?1 AND ((e.START_AT < ?3 AND e.LAST_START_AT >= ?2)
+	              OR e.ID IN (SELECT EVENT_ID FROM EVENT_OCCURRENCES WHERE START_AT >= ?2 AND START_AT < ?3))`
+	rows, err := db.Query(queryRows, groupId, fromSQL, toSQL)
 	if err != nil {
 		return eventInfos, err
 	}
-	defer rows.Close()
-
+	var series []eventSeries
 	for rows.Next() {
-		var e EventInfos
-		e.GroupId = groupId
-
-		err = rows.Scan(&e.Id, &e.Sender.ID, &e.Desc, &e.OptionA, &e.OptionB, &e.Created, &e.Title, &e.DateTime)
+		s, err := scanEventSeries(rows)
 		if err != nil {
+			rows.Close()
 			return eventInfos, err
 		}
+		series = append(series, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return eventInfos, err
+	}
 
-		var imgUser, username sql.NullString
-		_ = db.QueryRow(`SELECT LASTNAME, FIRSTNAME, USERNAME, IMAGE FROM USER WHERE ID = ?`, e.Sender.ID).
-			Scan(&e.Sender.Lastname, &e.Sender.Firstname, &username, &imgUser)
-
-		if imgUser.Valid {
-			e.Sender.ProfilePic = imgUser.String
+	for _, s := range series {
+		sender, err := getUserByID(db, s.sender)
+		if err != nil {
+			return eventInfos, err
 		}
-		if username.Valid {
-			e.Sender.Username = username.String
+		reminders, err := eventReminders(db, s.id)
+		if err != nil {
+			return eventInfos, err
 		}
-
-		// ✅ Choix de l'utilisateur
-		queryChoice := `SELECT RESPONSE FROM RESPONSE_EVENT WHERE USER_ID = ? AND EVENT_ID = ?`
-		err = db.QueryRow(queryChoice, userId, e.Id).Scan(&e.Choice)
+		overrides, err := eventOverrides(db, s.id)
+		if err != nil {
+			return eventInfos, err
+		}
+		occurrences, err := s.occurrences(from, to, overrides)
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				e.Choice = -1 // Aucun choix encore
-				err = nil
-			} else {
+			return eventInfos, err
+		}
+
+		for _, o := range occurrences {
+			e := EventInfos{
+				Id:         s.id,
+				GroupId:    groupId,
+				Occurrence: formatOccurrenceKey(o.key),
+				Modified:   o.modified,
+				Sender:     sender,
+				Title:      o.title,
+				Desc:       o.description,
+				StartAt:    o.start.In(s.loc).Format(time.RFC3339),
+				TimeZone:   s.timeZone,
+				Location:   o.location,
+				Capacity:   s.capacity,
+				Cancelled:  o.cancelled,
+				Created:    s.created,
+				Updated:    o.updated,
+				Reminders:  reminders,
+			}
+			if s.rule != nil {
+				e.Recurrence = s.rule.String()
+			}
+			if !o.end.IsZero() {
+				e.EndAt = o.end.In(s.loc).Format(time.RFC3339)
+			}
+			e.CanEdit = !e.Cancelled && (s.sender == userId || hasGroupPermission(role, PermEditGroup))
+
+			// Réponse de l'utilisateur et rang dans la liste d'attente
+			queryChoice := `SELECT r.STATUS, CASE WHEN r.STATUS = 'waitlist' THEN (
+			                    SELECT COUNT(*) FROM EVENT_RSVPS w WHERE w.EVENT_ID = r.EVENT_ID
+			                    AND w.OCCURRENCE_AT = r.OCCURRENCE_AT AND w.STATUS = 'waitlist'
+			                    AND (w.UPDATED_AT < r.UPDATED_AT OR (w.UPDATED_AT = r.UPDATED_AT AND w.ID <= r.ID))) ELSE 0 END
+			                FROM EVENT_RSVPS r WHERE r.USER_ID = ? AND r.EVENT_ID = ? AND r.OCCURRENCE_AT = ?`
+			err = db.QueryRow(queryChoice, userId, s.id, o.key).Scan(&e.Choice, &e.WaitlistPosition)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
 				return eventInfos, err
 			}
-		}
 
-		// ✅ Comptage des votes pour Option A (0) et Option B (1)
-		_ = db.QueryRow(`SELECT COUNT(*) FROM RESPONSE_EVENT WHERE EVENT_ID = ? AND RESPONSE = 1`, e.Id).Scan(&e.CountA)
-		_ = db.QueryRow(`SELECT COUNT(*) FROM RESPONSE_EVENT WHERE EVENT_ID = ? AND RESPONSE = 2`, e.Id).Scan(&e.CountB)
+			// Comptage des réponses par statut
+			queryCount := `SELECT IFNULL(SUM(STATUS = 'going'), 0), IFNULL(SUM(STATUS = 'maybe'), 0),
+			                      IFNULL(SUM(STATUS = 'not_going'), 0), IFNULL(SUM(STATUS = 'waitlist'), 0)
+			               FROM EVENT_RSVPS WHERE EVENT_ID = ? AND OCCURRENCE_AT = ?`
+			err = db.QueryRow(queryCount, s.id, o.key).Scan(&e.CountGoing, &e.CountMaybe, &e.CountNotGoing, &e.CountWaitlist)
+			if err != nil {
+				return eventInfos, err
+			}
 
-		eventInfos = append(eventInfos, e)
+			eventInfos = append(eventInfos, e)
+		}
 	}
 
+	// Les heures RFC 3339 n'ont pas toutes le même fuseau : on trie sur l'instant
+	sort.SliceStable(eventInfos, func(i, j int) bool {
+		a, _ := time.Parse(time.RFC3339, eventInfos[i].StartAt)
+		b, _ := time.Parse(time.RFC3339, eventInfos[j].StartAt)
+		return a.Before(b)
+	})
+
 	return eventInfos, nil
 }
+
+func eventReminders(db *sql.DB, eventId string) ([]int, error) {
+	reminders := []int{}
+
+	rows, err := db.Query(`SELECT OFFSET_MINUTES FROM EVENT_REMINDERS WHERE EVENT_ID = ? ORDER BY OFFSET_MINUTES DESC`, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m int
+		if err = rows.Scan(&m); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, m)
+	}
+
+	return reminders, rows.Err()
+}
@@ -6,22 +6,24 @@ import (
 )
 
 func AbortFollow(db *sql.DB, userID, targetID string) error {
-	var isOnRequest bool
-	query := `SELECT EXISTS ( SELECT 1 FROM REQUEST_FOLLOW WHERE RECEIVER_ID =? AND ASKER_ID =?)`
-	err := db.QueryRow(query, targetID, userID).Scan(&isOnRequest)
+	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return errors.Wrap(err, "transaction begin failed")
 	}
+	defer tx.Rollback()
 
-	if !isOnRequest {
+	requestId, err := pendingFollowRequestId(tx, targetID, userID)
+	if err != nil {
 		return errors.New("User is not on request")
 	}
 
-	query = `DELETE FROM REQUEST_FOLLOW WHERE RECEIVER_ID =? AND ASKER_ID =?`
-	_, err = db.Exec(query, targetID, userID)
-	if err != nil {
+	if err = deleteFollowRequest(tx, requestId); err != nil {
 		return err
 	}
 
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
 	return nil
 }
@@ -6,22 +6,41 @@ import (
 	"github.com/pkg/errors"
 )
 
-func AcceptAskerToJoinGroup(db *sql.DB, groupId, ownerId, askerId string) error {
-	// Vérifier si l'utilisateur est bien le propriétaire du groupe
-	var isOwner bool
-	query := `SELECT EXISTS (SELECT 1 FROM ALL_GROUPS WHERE ID = ? AND OWNER = ?)`
-	err := db.QueryRow(query, groupId, ownerId).Scan(&isOwner)
+func AcceptAskerToJoinGroup(db *sql.DB, groupId, userId, askerId string) error {
+	// Vérifier si l'utilisateur peut gérer les demandes d'adhésion (propriétaire ou admin)
+	_, err := checkGroupPermission(db, userId, groupId, PermManageRequests)
 	if err != nil {
-		return errors.Wrap(err, "failed to verify group ownership")
+		return err
 	}
-	if !isOwner {
-		return errors.New("you are not the owner of this group")
+
+	banned, err := isBannedFromGroup(db, askerId, groupId)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return errors.New("this user is banned from the group")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	// Un membre entré entre-temps (groupe devenu public, lien ou invitation) n'est pas ajouté une seconde fois
+	var isMember bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE GROUP_ID = ? AND USER_ID = ?)`
+	if err = tx.QueryRow(query, groupId, askerId).Scan(&isMember); err != nil {
+		return errors.Wrap(err, "failed to check membership")
+	}
+	if isMember {
+		return errors.New("this user is already a member of the group")
 	}
 
 	// Vérifier si l'utilisateur a fait une demande non encore acceptée
 	var isOnAsk bool
 	query = `SELECT EXISTS(SELECT 1 FROM ASK_GROUP WHERE GROUP_ID = ? AND ASKER = ? AND ACCEPTED = 0)`
-	err = db.QueryRow(query, groupId, askerId).Scan(&isOnAsk)
+	err = tx.QueryRow(query, groupId, askerId).Scan(&isOnAsk)
 	if err != nil {
 		return errors.Wrap(err, "failed to check ask request")
 	}
@@ -31,7 +50,7 @@ func AcceptAskerToJoinGroup(db *sql.DB, groupId, ownerId, askerId string) error
 
 	// Mise à jour de la demande pour l'accepter
 	query = `UPDATE ASK_GROUP SET ACCEPTED = 1 WHERE GROUP_ID = ? AND ASKER = ? AND ACCEPTED = 0`
-	result, err := db.Exec(query, groupId, askerId)
+	result, err := tx.Exec(query, groupId, askerId)
 	if err != nil {
 		return errors.Wrap(err, "failed to accept the request")
 	}
@@ -46,10 +65,19 @@ func AcceptAskerToJoinGroup(db *sql.DB, groupId, ownerId, askerId string) error
 
 	id := uuid.New().String()
 	query = `INSERT INTO GROUPS_MEMBERS (ID, USER_ID, GROUP_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
-	_, err = db.Exec(query, id, askerId, groupId)
+	_, err = tx.Exec(query, id, askerId, groupId)
 	if err != nil {
 		return errors.Wrap(err, "failed to accept the request")
 	}
 
+	// Le candidat est notifié de l'acceptation
+	if err = addJoinDecision(tx, groupId, askerId, userId, true, "", sql.NullString{}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
 	return nil
 }
@@ -2,24 +2,35 @@ package services
 
 import (
 	"database/sql"
-	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
+// AcceptGroupInvite fait entrer l'utilisateur dans le groupe sur une invitation en attente.
+// Vérification, acceptation et ajout se font dans une même transaction.
 func AcceptGroupInvite(db *sql.DB, userId, groupID string) error {
 	var isMember, existInvitation bool
 
-	query1 := `SELECT EXISTS (SELECT 1 FROM ASK_GROUP WHERE RECEIVER = ?  AND GROUP_ID = ?)`
-	err1 := db.QueryRow(query1, userId, groupID).Scan(&existInvitation)
-	if err1 != nil {
-		return err1
+	if err := checkNotBannedFromGroup(db, userId, groupID); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query1 := `SELECT EXISTS (SELECT 1 FROM ASK_GROUP WHERE RECEIVER = ? AND GROUP_ID = ? AND ACCEPTED = 0)`
+	err = tx.QueryRow(query1, userId, groupID).Scan(&existInvitation)
+	if err != nil {
+		return err
 	}
 	if !existInvitation {
 		return errors.New("invitation does not exist")
 	}
 
-	query := `SELECT EXISTS (SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND ID = ?)`
-	err := db.QueryRow(query, userId, groupID).Scan(&isMember)
+	query := `SELECT EXISTS (SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
+	err = tx.QueryRow(query, userId, groupID).Scan(&isMember)
 	if err != nil {
 		return err
 	}
@@ -27,18 +38,14 @@ func AcceptGroupInvite(db *sql.DB, userId, groupID string) error {
 		return errors.New("you are already a member of this group")
 	}
 
-	queryUpdate := `UPDATE ASK_GROUP SET ACCEPTED = ?  WHERE RECEIVER = ? AND GROUP_ID = ?`
-	_, err = db.Exec(queryUpdate, 1, userId, groupID)
-	if err != nil {
+	// Ajoute le membre et marque acceptées toutes ses invitations en attente
+	if err = addJoinedMember(tx, groupID, userId, sql.NullString{}); err != nil {
 		return err
 	}
 
-	id := uuid.New().String()
-	addGroupQuery := `INSERT INTO GROUPS_MEMBERS(ID, USER_ID, GROUP_ID, CREATED_AT) VALUES (?,?,?,datetime('now'))`
-	_, err = db.Exec(addGroupQuery, id, userId, groupID)
-	if err != nil {
-		return err
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
 	}
-	
+
 	return nil
 }
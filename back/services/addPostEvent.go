@@ -16,6 +16,9 @@ func AddPostEvent(db *sql.DB, userId, postID, event string) error {
 	if errOwner != nil {
 		return errors.New("post not found")
 	}
+	if err := checkPostPublished(db, postID); err != nil {
+		return err
+	}
 
 	query := `SELECT LIKED FROM POST_EVENT WHERE POST_ID = ? AND USER_ID = ?`
 	err := db.QueryRow(query, postID, userId).Scan(&existLike)
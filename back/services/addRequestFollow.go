@@ -7,9 +7,13 @@ import (
 )
 
 func AddRequestFollowHandler(db *sql.DB, userID, receiver string) error {
+	if err := checkNotBlocked(db, userID, receiver); err != nil {
+		return err
+	}
+
 	// Vérifie si une demande existe déjà
 	var requestID string
-	query := `SELECT ID FROM REQUEST_FOLLOW WHERE RECEIVER_ID = ? AND ASKER_ID = ?`
+	query := `SELECT ID FROM REQUEST_FOLLOW WHERE RECEIVER_ID = ? AND ASKER_ID = ? AND STATUS = 'pending'`
 	err := db.QueryRow(query, receiver, userID).Scan(&requestID)
 	if err == nil {
 		return errors.New("request already exists")
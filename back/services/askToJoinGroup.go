@@ -6,31 +6,58 @@ import (
 	"github.com/pkg/errors"
 )
 
-func AskToJoinGroup(db *sql.DB, groupID, userID string) error {
-	var isOwner bool
+// AskToJoinGroup applique le mode du groupe : adhésion immédiate pour un groupe public (renvoie true),
+// demande à valider pour un groupe fermé, refus pour un groupe secret (invitation uniquement).
+// answers contient une réponse par question du groupe, dans l'ordre.
+func AskToJoinGroup(db *sql.DB, groupID, userID string, answers []string) (bool, error) {
+	// Vérifie si l'utilisateur est déjà membre (propriétaire compris)
+	role, err := groupRole(db, userID, groupID)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to check group membership")
+	}
+	if role == RoleOwner {
+		return false, errors.New("you cannot ask to join your own group")
+	}
+	if role != "" {
+		return false, errors.New("you are already a member of this group")
+	}
 
-	// Vérifie si l'utilisateur est le propriétaire du groupe
-	query := `SELECT EXISTS(SELECT 1 FROM ALL_GROUPS WHERE OWNER = ? AND ID = ?)`
-	err := db.QueryRow(query, userID, groupID).Scan(&isOwner)
+	if err = checkNotBannedFromGroup(db, userID, groupID); err != nil {
+		return false, err
+	}
+	if err = checkReapplyBlock(db, userID, groupID); err != nil {
+		return false, err
+	}
+
+	visibility, err := groupVisibility(db, groupID)
 	if err != nil {
-		return errors.Wrap(err, "failed to check group ownership")
+		return false, err
 	}
-	if isOwner {
-		return errors.New("you cannot ask to join your own group")
+	switch visibility {
+	case GroupPublic:
+		if err = joinPublicGroup(db, groupID, userID); err != nil {
+			return false, err
+		}
+		return true, nil
+	case GroupSecret:
+		if err = canSeeGroup(db, userID, groupID); err != nil {
+			return false, err
+		}
+		return false, errors.New("this group is invite only: accept your invitation to join")
 	}
 
 	// Vérifie si une demande existe déjà
 	var alreadyAsked bool
-	query = `SELECT EXISTS(
+	query := `SELECT EXISTS(
 		SELECT 1 FROM ASK_GROUP 
 		WHERE ASKER = ? AND GROUP_ID = ? AND ACCEPTED = 0
 	)`
 	err = db.QueryRow(query, userID, groupID).Scan(&alreadyAsked)
 	if err != nil {
-		return errors.Wrap(err, "failed to check if ask already exists")
+		return false, errors.Wrap(err, "failed to check if ask already exists")
 	}
 	if alreadyAsked {
-		return errors.New("you have already requested to join this group")
+		return false, errors.New("you have already requested to join this group")
 	}
 
 	// Récupère le propriétaire du groupe
@@ -38,13 +65,13 @@ func AskToJoinGroup(db *sql.DB, groupID, userID string) error {
 	query = `SELECT OWNER FROM ALL_GROUPS WHERE ID = ?`
 	err = db.QueryRow(query, groupID).Scan(&owner)
 	if err != nil {
-		return errors.Wrap(err, "failed to get group owner")
+		return false, errors.Wrap(err, "failed to get group owner")
 	}
 
 	// Démarre une transaction
 	tx, err := db.Begin()
 	if err != nil {
-		return errors.Wrap(err, "transaction begin failed")
+		return false, errors.Wrap(err, "transaction begin failed")
 	}
 	defer tx.Rollback()
 
@@ -56,7 +83,11 @@ func AskToJoinGroup(db *sql.DB, groupID, userID string) error {
 	         VALUES (?, ?, ?, ?, 0, datetime('now'))`
 	_, err = tx.Exec(query, askId, userID, owner, groupID)
 	if err != nil {
-		return errors.Wrap(err, "failed to insert into ASK_GROUP")
+		return false, errors.Wrap(err, "failed to insert into ASK_GROUP")
+	}
+
+	if err = saveGroupAnswers(db, tx, askId, groupID, answers); err != nil {
+		return false, err
 	}
 
 	// Insertion dans NOTIFICATIONS
@@ -64,13 +95,59 @@ func AskToJoinGroup(db *sql.DB, groupID, userID string) error {
 	         VALUES (?, ?, ?, ?)`
 	_, err = tx.Exec(query, notifId, "ASK_GROUP", userID, askId)
 	if err != nil {
-		return errors.Wrap(err, "failed to insert into NOTIFICATIONS")
+		return false, errors.Wrap(err, "failed to insert into NOTIFICATIONS")
 	}
 
 	// Commit de la transaction
 	if err := tx.Commit(); err != nil {
+		return false, errors.Wrap(err, "transaction commit failed")
+	}
+
+	return false, nil
+}
+
+// joinPublicGroup ajoute directement l'utilisateur à un groupe public.
+// Une invitation en attente est marquée acceptée, une demande en attente est supprimée.
+func joinPublicGroup(db *sql.DB, groupID, userID string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	if err = addJoinedMember(tx, groupID, userID, sql.NullString{}); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
 		return errors.Wrap(err, "transaction commit failed")
 	}
 
 	return nil
 }
+
+// addJoinedMember ajoute le membre, avec le lien d'invitation utilisé le cas échéant,
+// marque acceptées ses invitations en attente et supprime sa demande d'adhésion devenue sans objet.
+func addJoinedMember(tx *sql.Tx, groupID, userID string, inviteLink sql.NullString) error {
+	query := `INSERT INTO GROUPS_MEMBERS(ID, USER_ID, GROUP_ID, INVITE_LINK_ID, CREATED_AT) VALUES (?, ?, ?, ?, datetime('now'))`
+	_, err := tx.Exec(query, uuid.New().String(), userID, groupID, inviteLink)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert group member")
+	}
+
+	_, err = tx.Exec(`UPDATE ASK_GROUP SET ACCEPTED = 1 WHERE RECEIVER = ? AND GROUP_ID = ? AND ACCEPTED = 0`, userID, groupID)
+	if err != nil {
+		return errors.Wrap(err, "failed to update group invites")
+	}
+
+	var askId string
+	err = tx.QueryRow(`SELECT ID FROM ASK_GROUP WHERE ASKER = ? AND GROUP_ID = ? AND ACCEPTED = 0`, userID, groupID).Scan(&askId)
+	if err != nil && err != sql.ErrNoRows {
+		return errors.Wrap(err, "failed to check ask request")
+	}
+	if err == nil {
+		return deleteGroupAsk(tx, askId)
+	}
+
+	return nil
+}
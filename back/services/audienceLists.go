@@ -0,0 +1,334 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxAudienceListName = 50
+
+type AudienceList struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	MemberCount int    `json:"member_count"`
+	Members     []User `json:"members,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// hasPrivatePostAccess vérifie l'accès à un post privé (PRIVACY = 0) : l'utilisateur a été
+// sélectionné individuellement, ou il est membre d'une liste ciblée par le post et suit toujours l'auteur.
+// Les listes sont évaluées à la lecture : ajouter quelqu'un à une liste lui ouvre les anciens posts.
+func hasPrivatePostAccess(db *sql.DB, userId, postId string) (bool, error) {
+	var access bool
+	query := `
+		SELECT EXISTS(SELECT 1 FROM LIST_PRIVATE_POST WHERE POST_ID = ? AND USER_ID = ?)
+		    OR EXISTS(
+				SELECT 1 FROM POST_AUDIENCE_LISTS pal
+				JOIN AUDIENCE_LISTS l ON l.ID = pal.LIST_ID
+				JOIN AUDIENCE_LIST_MEMBERS m ON m.LIST_ID = l.ID
+				JOIN FOLLOWERS f ON f.USER_ID = l.OWNER_ID AND f.FOLLOWERS = m.USER_ID
+				WHERE pal.POST_ID = ? AND m.USER_ID = ?
+			)
+	`
+	err := db.QueryRow(query, postId, userId, postId, userId).Scan(&access)
+	return access, err
+}
+
+// sendPostAudienceLists renvoie les listes ciblées par un post privé.
+func sendPostAudienceLists(db *sql.DB, postId string) ([]AudienceList, error) {
+	var lists []AudienceList
+
+	query := `
+		SELECT l.ID, l.NAME, l.CREATED_AT, l.UPDATED_AT,
+		       (SELECT COUNT(*) FROM AUDIENCE_LIST_MEMBERS m WHERE m.LIST_ID = l.ID)
+		FROM POST_AUDIENCE_LISTS pal
+		JOIN AUDIENCE_LISTS l ON l.ID = pal.LIST_ID
+		WHERE pal.POST_ID = ?
+		ORDER BY l.NAME COLLATE NOCASE
+	`
+	rows, err := db.Query(query, postId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l AudienceList
+		if err = rows.Scan(&l.Id, &l.Name, &l.CreatedAt, &l.UpdatedAt, &l.MemberCount); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, rows.Err()
+}
+
+// checkFollowers vérifie que tous les utilisateurs suivent ownerId.
+func checkFollowers(db *sql.DB, ownerId string, users []string) error {
+	for _, user := range users {
+		var isFollowing bool
+		query := `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE FOLLOWERS = ? AND USER_ID = ?)`
+		err := db.QueryRow(query, user, ownerId).Scan(&isFollowing)
+		if err != nil {
+			return err
+		}
+		if !isFollowing {
+			return errors.New("One or more users are not your followers")
+		}
+	}
+	return nil
+}
+
+func checkAudienceListOwner(db *sql.DB, userId, listId string) error {
+	var owner string
+	err := db.QueryRow(`SELECT OWNER_ID FROM AUDIENCE_LISTS WHERE ID = ?`, listId).Scan(&owner)
+	if err == sql.ErrNoRows {
+		return errors.New("audience list not found")
+	}
+	if err != nil {
+		return err
+	}
+	if owner != userId {
+		return errors.New("you are not the owner of this audience list")
+	}
+	return nil
+}
+
+func validAudienceListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("list name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > maxAudienceListName {
+		return "", errors.New("list name too long (max 50 characters)")
+	}
+	return name, nil
+}
+
+// CreateAudienceList crée une liste nommée (ex. "Amis proches") avec ses premiers membres.
+func CreateAudienceList(db *sql.DB, userId, name string, users []string) (string, error) {
+	name, err := validAudienceListName(name)
+	if err != nil {
+		return "", err
+	}
+	if err = checkFollowers(db, userId, users); err != nil {
+		return "", err
+	}
+
+	var exists bool
+	err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM AUDIENCE_LISTS WHERE OWNER_ID = ? AND NAME = ?)`, userId, name).Scan(&exists)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", errors.New("a list with this name already exists")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	id := uuid.New().String()
+	query := `INSERT INTO AUDIENCE_LISTS(ID, OWNER_ID, NAME, CREATED_AT, UPDATED_AT) VALUES (?, ?, ?, datetime('now'), datetime('now'))`
+	_, err = tx.Exec(query, id, userId, name)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to insert audience list")
+	}
+
+	for _, user := range users {
+		query = `INSERT OR IGNORE INTO AUDIENCE_LIST_MEMBERS(ID, LIST_ID, USER_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+		_, err = tx.Exec(query, uuid.New().String(), id, user)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to insert audience list member")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "transaction commit failed")
+	}
+
+	return id, nil
+}
+
+// SendAudienceLists renvoie les listes de l'utilisateur avec leur nombre de membres.
+func SendAudienceLists(db *sql.DB, userId string) ([]AudienceList, error) {
+	var lists []AudienceList
+
+	query := `
+		SELECT l.ID, l.NAME, l.CREATED_AT, l.UPDATED_AT,
+		       (SELECT COUNT(*) FROM AUDIENCE_LIST_MEMBERS m WHERE m.LIST_ID = l.ID)
+		FROM AUDIENCE_LISTS l
+		WHERE l.OWNER_ID = ?
+		ORDER BY l.NAME COLLATE NOCASE
+	`
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l AudienceList
+		if err = rows.Scan(&l.Id, &l.Name, &l.CreatedAt, &l.UpdatedAt, &l.MemberCount); err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+
+	return lists, rows.Err()
+}
+
+// SendAudienceList renvoie une liste de l'utilisateur avec ses membres.
+func SendAudienceList(db *sql.DB, userId, listId string) (AudienceList, error) {
+	var l AudienceList
+
+	if err := checkAudienceListOwner(db, userId, listId); err != nil {
+		return l, err
+	}
+
+	query := `SELECT ID, NAME, CREATED_AT, UPDATED_AT FROM AUDIENCE_LISTS WHERE ID = ?`
+	err := db.QueryRow(query, listId).Scan(&l.Id, &l.Name, &l.CreatedAt, &l.UpdatedAt)
+	if err != nil {
+		return l, err
+	}
+
+	rows, err := db.Query(`SELECT USER_ID FROM AUDIENCE_LIST_MEMBERS WHERE LIST_ID = ? ORDER BY CREATED_AT`, listId)
+	if err != nil {
+		return l, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var memberId string
+		if err = rows.Scan(&memberId); err != nil {
+			return l, err
+		}
+		member, err := getUserByID(db, memberId)
+		if err != nil {
+			return l, err
+		}
+		l.Members = append(l.Members, member)
+	}
+	l.MemberCount = len(l.Members)
+
+	return l, rows.Err()
+}
+
+// RenameAudienceList modifie le nom d'une liste.
+func RenameAudienceList(db *sql.DB, userId, listId, name string) error {
+	if err := checkAudienceListOwner(db, userId, listId); err != nil {
+		return err
+	}
+	name, err := validAudienceListName(name)
+	if err != nil {
+		return err
+	}
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM AUDIENCE_LISTS WHERE OWNER_ID = ? AND NAME = ? AND ID != ?)`
+	err = db.QueryRow(query, userId, name, listId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.New("a list with this name already exists")
+	}
+
+	_, err = db.Exec(`UPDATE AUDIENCE_LISTS SET NAME = ?, UPDATED_AT = datetime('now') WHERE ID = ?`, name, listId)
+	if err != nil {
+		return errors.Wrap(err, "failed to rename audience list")
+	}
+
+	return nil
+}
+
+// DeleteAudienceList supprime une liste. Les posts qui la ciblaient ne sont plus visibles par ses membres.
+func DeleteAudienceList(db *sql.DB, userId, listId string) error {
+	if err := checkAudienceListOwner(db, userId, listId); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM POST_AUDIENCE_LISTS WHERE LIST_ID = ?`, listId); err != nil {
+		return errors.Wrap(err, "failed to delete post audience links")
+	}
+	if _, err = tx.Exec(`DELETE FROM AUDIENCE_LIST_MEMBERS WHERE LIST_ID = ?`, listId); err != nil {
+		return errors.Wrap(err, "failed to delete audience list members")
+	}
+	if _, err = tx.Exec(`DELETE FROM AUDIENCE_LISTS WHERE ID = ?`, listId); err != nil {
+		return errors.Wrap(err, "failed to delete audience list")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// AddAudienceMembers ajoute des abonnés à une liste. Ils accèdent aussi aux anciens posts qui la ciblent.
+func AddAudienceMembers(db *sql.DB, userId, listId string, users []string) error {
+	if err := checkAudienceListOwner(db, userId, listId); err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return errors.New("no users to add")
+	}
+	if err := checkFollowers(db, userId, users); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	for _, user := range users {
+		query := `INSERT OR IGNORE INTO AUDIENCE_LIST_MEMBERS(ID, LIST_ID, USER_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+		_, err = tx.Exec(query, uuid.New().String(), listId, user)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert audience list member")
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE AUDIENCE_LISTS SET UPDATED_AT = datetime('now') WHERE ID = ?`, listId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update audience list")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// RemoveAudienceMember retire un membre d'une liste.
+func RemoveAudienceMember(db *sql.DB, userId, listId, memberId string) error {
+	if err := checkAudienceListOwner(db, userId, listId); err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`DELETE FROM AUDIENCE_LIST_MEMBERS WHERE LIST_ID = ? AND USER_ID = ?`, listId, memberId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete audience list member")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("user is not a member of this list")
+	}
+
+	_, err = db.Exec(`UPDATE AUDIENCE_LISTS SET UPDATED_AT = datetime('now') WHERE ID = ?`, listId)
+	return err
+}
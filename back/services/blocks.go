@@ -0,0 +1,184 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var ErrBlocked = errors.New("action impossible : l'un des utilisateurs a bloqué l'autre")
+
+// isBlocked indique si l'un des deux utilisateurs a bloqué l'autre.
+func isBlocked(db *sql.DB, userA, userB string) (bool, error) {
+	if userA == "" || userB == "" || userA == userB {
+		return false, nil
+	}
+
+	var blocked bool
+	query := `SELECT EXISTS(SELECT 1 FROM BLOCKS WHERE (BLOCKER_ID = ? AND BLOCKED_ID = ?) OR (BLOCKER_ID = ? AND BLOCKED_ID = ?))`
+	err := db.QueryRow(query, userA, userB, userB, userA).Scan(&blocked)
+	return blocked, err
+}
+
+// checkNotBlocked renvoie ErrBlocked si l'un des deux utilisateurs a bloqué l'autre.
+func checkNotBlocked(db *sql.DB, userA, userB string) error {
+	blocked, err := isBlocked(db, userA, userB)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// Nombre de commentaires visibles d'un post (?1), sans ceux des utilisateurs bloqués par le lecteur (?2)
+// ou qui l'ont bloqué, comme dans la liste des commentaires.
+const visibleCommentCount = `SELECT COUNT(*) FROM COMMENT WHERE POST_ID = ?1 AND HIDDEN = 0 AND USER_ID NOT IN (
+	SELECT BLOCKED_ID FROM BLOCKS WHERE BLOCKER_ID = ?2 UNION SELECT BLOCKER_ID FROM BLOCKS WHERE BLOCKED_ID = ?2)`
+
+// blockedUserIds renvoie les utilisateurs que userId a bloqués ou qui l'ont bloqué.
+// Utilisé pour filtrer les fils et les recherches avec une seule requête.
+func blockedUserIds(db *sql.DB, userId string) (map[string]bool, error) {
+	blocked := make(map[string]bool)
+
+	query := `
+		SELECT BLOCKED_ID FROM BLOCKS WHERE BLOCKER_ID = ?
+		UNION
+		SELECT BLOCKER_ID FROM BLOCKS WHERE BLOCKED_ID = ?
+	`
+	rows, err := db.Query(query, userId, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		blocked[id] = true
+	}
+
+	return blocked, rows.Err()
+}
+
+// BlockUser bloque targetId : les abonnements dans les deux sens, les demandes d'abonnement
+// et les invitations de groupe en attente entre les deux utilisateurs sont supprimés.
+func BlockUser(db *sql.DB, userId, targetId string) error {
+	if userId == targetId {
+		return errors.New("you cannot block yourself")
+	}
+
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM USER WHERE ID = ?)`, targetId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `INSERT OR IGNORE INTO BLOCKS(ID, BLOCKER_ID, BLOCKED_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+	_, err = tx.Exec(query, uuid.New().String(), userId, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert block")
+	}
+
+	query = `DELETE FROM FOLLOWERS WHERE (USER_ID = ? AND FOLLOWERS = ?) OR (USER_ID = ? AND FOLLOWERS = ?)`
+	_, err = tx.Exec(query, userId, targetId, targetId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete follows")
+	}
+
+	query = `DELETE FROM NOTIFICATIONS WHERE TYPE = 'ASK_FOLLOW' AND ID_TYPE IN (
+	             SELECT ID FROM REQUEST_FOLLOW WHERE (ASKER_ID = ? AND RECEIVER_ID = ?) OR (ASKER_ID = ? AND RECEIVER_ID = ?))`
+	_, err = tx.Exec(query, userId, targetId, targetId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete follow request notifications")
+	}
+
+	query = `DELETE FROM REQUEST_FOLLOW WHERE (ASKER_ID = ? AND RECEIVER_ID = ?) OR (ASKER_ID = ? AND RECEIVER_ID = ?)`
+	_, err = tx.Exec(query, userId, targetId, targetId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete follow requests")
+	}
+
+	query = `DELETE FROM NOTIFICATIONS WHERE TYPE IN ('INVITE_GROUP', 'ASK_GROUP') AND ID_TYPE IN (
+	             SELECT ID FROM ASK_GROUP WHERE ACCEPTED = 0 AND ((ASKER = ? AND RECEIVER = ?) OR (ASKER = ? AND RECEIVER = ?)))`
+	_, err = tx.Exec(query, userId, targetId, targetId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group invite notifications")
+	}
+
+	query = `DELETE FROM GROUP_ANSWERS WHERE ASK_ID IN (
+	             SELECT ID FROM ASK_GROUP WHERE ACCEPTED = 0 AND ((ASKER = ? AND RECEIVER = ?) OR (ASKER = ? AND RECEIVER = ?)))`
+	_, err = tx.Exec(query, userId, targetId, targetId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group answers")
+	}
+
+	query = `DELETE FROM ASK_GROUP WHERE ACCEPTED = 0 AND ((ASKER = ? AND RECEIVER = ?) OR (ASKER = ? AND RECEIVER = ?))`
+	_, err = tx.Exec(query, userId, targetId, targetId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group invites")
+	}
+
+	query = `DELETE FROM AUDIENCE_LIST_MEMBERS WHERE
+	             (USER_ID = ? AND LIST_ID IN (SELECT ID FROM AUDIENCE_LISTS WHERE OWNER_ID = ?))
+	          OR (USER_ID = ? AND LIST_ID IN (SELECT ID FROM AUDIENCE_LISTS WHERE OWNER_ID = ?))`
+	_, err = tx.Exec(query, targetId, userId, userId, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete audience list members")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// UnblockUser lève le blocage. Les abonnements supprimés ne sont pas restaurés.
+func UnblockUser(db *sql.DB, userId, targetId string) error {
+	res, err := db.Exec(`DELETE FROM BLOCKS WHERE BLOCKER_ID = ? AND BLOCKED_ID = ?`, userId, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete block")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("user is not blocked")
+	}
+	return nil
+}
+
+// SendBlockedUsers renvoie les utilisateurs bloqués par userId.
+func SendBlockedUsers(db *sql.DB, userId string) ([]User, error) {
+	var users []User
+
+	rows, err := db.Query(`SELECT BLOCKED_ID FROM BLOCKS WHERE BLOCKER_ID = ? ORDER BY CREATED_AT DESC`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		user, err := getUserByID(db, id)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
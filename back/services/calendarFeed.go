@@ -0,0 +1,251 @@
+package services
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"github.com/pkg/errors"
+	"social-network/utils"
+	"sort"
+	"time"
+)
+
+// Jeton inconnu ou régénéré : le flux n'existe plus (404)
+var ErrCalendarTokenNotFound = errors.New("calendar feed not found")
+
+// GroupEventsICS exporte les événements du groupe : une série récurrente donne un VEVENT avec RRULE,
+// ses occurrences annulées des EXDATE et ses occurrences modifiées un VEVENT avec RECURRENCE-ID.
+func GroupEventsICS(db *sql.DB, userId, groupId string) ([]byte, error) {
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrGroupPermission
+	}
+
+	var title string
+	if err = db.QueryRow(`SELECT TITLE FROM ALL_GROUPS WHERE ID = ?`, groupId).Scan(&title); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(eventSeriesSelect+` WHERE e.GROUP_ID = ? ORDER BY e.START_AT`, groupId)
+	if err != nil {
+		return nil, err
+	}
+	var series []eventSeries
+	for rows.Next() {
+		s, err := scanEventSeries(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		series = append(series, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []icsEvent
+	for _, s := range series {
+		overrides, err := eventOverrides(db, s.id)
+		if err != nil {
+			return nil, err
+		}
+
+		master := seriesICSEvent(s)
+		var modified []icsEvent
+		if s.rule != nil {
+			master.rrule = s.rule.String()
+			for key := range overrides {
+				o, err := s.occurrence(key, overrides)
+				if err != nil {
+					continue
+				}
+				original, _ := time.Parse(eventSQLTimeLayout, key)
+				if o.cancelled {
+					master.exdates = append(master.exdates, original)
+					continue
+				}
+				e := occurrenceICSEvent(s, o)
+				e.uid = master.uid
+				e.recurrenceId = original
+				modified = append(modified, e)
+			}
+			sort.Slice(modified, func(i, j int) bool { return modified[i].recurrenceId.Before(modified[j].recurrenceId) })
+			sort.Slice(master.exdates, func(i, j int) bool { return master.exdates[i].Before(master.exdates[j]) })
+		}
+
+		events = append(events, master)
+		events = append(events, modified...)
+	}
+
+	w := newICSWriter(title)
+	w.timeZones(events)
+	for _, e := range events {
+		w.event(e)
+	}
+	return w.bytes(), nil
+}
+
+// UserCalendarICS exporte, pour le flux personnel du jeton, les événements des groupes de l'utilisateur
+// auxquels il a répondu going ou maybe. Chaque occurrence retenue d'une série y est un événement à part.
+func UserCalendarICS(db *sql.DB, token string) ([]byte, error) {
+	userId, err := calendarTokenUser(db, token)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT r.EVENT_ID, r.OCCURRENCE_AT, g.TITLE FROM EVENT_RSVPS r
+	          JOIN GROUPS_EVENT e ON e.ID = r.EVENT_ID
+	          JOIN GROUPS_MEMBERS m ON m.GROUP_ID = e.GROUP_ID AND m.USER_ID = r.USER_ID
+	          JOIN ALL_GROUPS g ON g.ID = e.GROUP_ID
+	          WHERE r.USER_ID = ? AND r.STATUS IN ('going', 'maybe')
+	          ORDER BY e.START_AT, r.OCCURRENCE_AT`
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	type rsvp struct{ eventId, occurrence, group string }
+	var rsvps []rsvp
+	for rows.Next() {
+		var r rsvp
+		if err = rows.Scan(&r.eventId, &r.occurrence, &r.group); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rsvps = append(rsvps, r)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	series := map[string]eventSeries{}
+	overrides := map[string]map[string]occurrenceOverride{}
+	var events []icsEvent
+	for _, r := range rsvps {
+		s, ok := series[r.eventId]
+		if !ok {
+			if s, err = loadEventSeries(db, r.eventId); err != nil {
+				return nil, err
+			}
+			series[r.eventId] = s
+			if overrides[r.eventId], err = eventOverrides(db, r.eventId); err != nil {
+				return nil, err
+			}
+		}
+
+		o, err := s.occurrence(r.occurrence, overrides[r.eventId])
+		if err != nil {
+			// Réponse à une occurrence qui n'existe plus
+			continue
+		}
+		e := occurrenceICSEvent(s, o)
+		if o.key != "" {
+			original, _ := time.Parse(eventSQLTimeLayout, o.key)
+			e.uid = s.id + "-" + original.Format(icsUTCLayout) + "@" + icsUIDDomain
+		}
+		e.categories = r.group
+		events = append(events, e)
+	}
+
+	w := newICSWriter("Mes événements")
+	w.timeZones(events)
+	for _, e := range events {
+		w.event(e)
+	}
+	return w.bytes(), nil
+}
+
+func seriesICSEvent(s eventSeries) icsEvent {
+	e := icsEvent{
+		uid:         s.id + "@" + icsUIDDomain,
+		start:       s.start,
+		loc:         s.loc,
+		summary:     s.title,
+		description: s.description,
+		location:    s.location,
+		cancelled:   s.cancelled,
+		created:     s.created,
+		updated:     s.updated,
+	}
+	if s.duration > 0 {
+		e.end = s.start.Add(s.duration)
+	}
+	return e
+}
+
+func occurrenceICSEvent(s eventSeries, o eventOccurrence) icsEvent {
+	return icsEvent{
+		uid:         s.id + "@" + icsUIDDomain,
+		start:       o.start,
+		end:         o.end,
+		loc:         s.loc,
+		summary:     o.title,
+		description: o.description,
+		location:    o.location,
+		cancelled:   o.cancelled,
+		created:     s.created,
+		updated:     o.updated,
+	}
+}
+
+// Longueur du préfixe de jeton servant à la recherche en base
+const calendarTokenPrefix = 8
+
+// calendarTokenUser renvoie l'utilisateur du jeton. La base n'est interrogée que sur un préfixe,
+// le jeton complet est comparé en temps constant pour ne rien laisser deviner par la durée de réponse.
+func calendarTokenUser(db *sql.DB, token string) (string, error) {
+	if len(token) <= calendarTokenPrefix {
+		return "", ErrCalendarTokenNotFound
+	}
+
+	rows, err := db.Query(`SELECT USER_ID, TOKEN FROM CALENDAR_TOKENS WHERE substr(TOKEN, 1, ?) = ?`, calendarTokenPrefix, token[:calendarTokenPrefix])
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	userId := ""
+	for rows.Next() {
+		var id, candidate string
+		if err = rows.Scan(&id, &candidate); err != nil {
+			return "", err
+		}
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			userId = id
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+	if userId == "" {
+		return "", ErrCalendarTokenNotFound
+	}
+	return userId, nil
+}
+
+// CalendarFeedToken renvoie le jeton du flux personnel de l'utilisateur, créé au premier appel.
+func CalendarFeedToken(db *sql.DB, userId string) (string, error) {
+	var token string
+	err := db.QueryRow(`SELECT TOKEN FROM CALENDAR_TOKENS WHERE USER_ID = ?`, userId).Scan(&token)
+	if err == nil {
+		return token, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+	return RotateCalendarFeedToken(db, userId)
+}
+
+// RotateCalendarFeedToken remplace le jeton : l'ancienne adresse du flux cesse de fonctionner.
+func RotateCalendarFeedToken(db *sql.DB, userId string) (string, error) {
+	token := utils.GenerateToken(24)
+	query := `INSERT INTO CALENDAR_TOKENS(USER_ID, TOKEN, CREATED_AT) VALUES (?, ?, datetime('now'))
+	          ON CONFLICT(USER_ID) DO UPDATE SET TOKEN = excluded.TOKEN, CREATED_AT = excluded.CREATED_AT`
+	if _, err := db.Exec(query, userId, token); err != nil {
+		return "", errors.Wrap(err, "failed to save calendar token")
+	}
+	return token, nil
+}
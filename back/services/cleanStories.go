@@ -0,0 +1,97 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const storyImagesDir = "Images/storyImages"
+
+// StartStoryCleanup lance en tâche de fond la suppression périodique des stories expirées.
+func StartStoryCleanup(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			count, err := CleanExpiredStories(db)
+			if err != nil {
+				log.Println("Erreur lors du nettoyage des stories :", err)
+			} else if count > 0 {
+				log.Printf("%d stories expirées supprimées", count)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// CleanExpiredStories supprime les stories expirées, leurs vues, leurs listes privées et leurs images.
+func CleanExpiredStories(db *sql.DB) (int, error) {
+	rows, err := db.Query(`SELECT ID, IMAGE FROM STORIES WHERE EXPIRE_AT <= datetime('now')`)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids, images []string
+	for rows.Next() {
+		var id string
+		var image sql.NullString
+		if err = rows.Scan(&id, &image); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+		images = append(images, image.String)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	return len(ids), deleteStories(db, ids, images)
+}
+
+func deleteStories(db *sql.DB, ids, images []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err = tx.Exec(`DELETE FROM STORY_VIEWS WHERE STORY_ID = ?`, id); err != nil {
+			return errors.Wrap(err, "failed to delete story views")
+		}
+		if _, err = tx.Exec(`DELETE FROM LIST_PRIVATE_STORY WHERE STORY_ID = ?`, id); err != nil {
+			return errors.Wrap(err, "failed to delete private story members")
+		}
+		if _, err = tx.Exec(`DELETE FROM STORIES WHERE ID = ?`, id); err != nil {
+			return errors.Wrap(err, "failed to delete story")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	// Les fichiers ne sont supprimés qu'une fois les lignes effacées
+	for _, image := range images {
+		if image == "" {
+			continue
+		}
+		err = os.Remove(filepath.Join(storyImagesDir, filepath.Base(image)))
+		if err != nil && !os.IsNotExist(err) {
+			log.Printf("Impossible de supprimer l'image de story %s : %v", image, err)
+		}
+	}
+
+	return nil
+}
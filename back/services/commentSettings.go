@@ -0,0 +1,255 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	CommentPolicyEveryone  = "everyone"
+	CommentPolicyOff       = "off"
+	CommentPolicyFollowers = "followers"
+	CommentPolicyMentioned = "mentioned"
+)
+
+type ModerationLog struct {
+	Id            string `json:"id"`
+	PostId        string `json:"post_id"`
+	Action        string `json:"action"`
+	CommentId     string `json:"comment_id"`     // null
+	CommentAuthor User   `json:"comment_author"` // null
+	Details       string `json:"details"`        // null
+	CreatedAt     string `json:"created_at"`
+}
+
+func isValidCommentPolicy(policy string) bool {
+	switch policy {
+	case CommentPolicyEveryone, CommentPolicyOff, CommentPolicyFollowers, CommentPolicyMentioned:
+		return true
+	}
+	return false
+}
+
+// canCommentOnPost applique les réglages de commentaires du post pour l'utilisateur donné.
+// L'auteur du post n'est soumis qu'au verrouillage du fil.
+func canCommentOnPost(db *sql.DB, userId, postId string) error {
+	var ownerId, policy string
+	var locked bool
+	query := `SELECT USER_ID, COMMENT_POLICY, COMMENTS_LOCKED FROM POSTS WHERE ID = ?`
+	err := db.QueryRow(query, postId).Scan(&ownerId, &policy, &locked)
+	if err == sql.ErrNoRows {
+		return errors.New("post not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if locked {
+		return errors.New("comments on this post are locked")
+	}
+	if err = checkPostPublished(db, postId); err != nil {
+		return err
+	}
+	if ownerId == userId {
+		return nil
+	}
+	if err = checkNotBlocked(db, userId, ownerId); err != nil {
+		return err
+	}
+
+	switch policy {
+	case CommentPolicyOff:
+		return errors.New("comments are disabled on this post")
+	case CommentPolicyFollowers:
+		var isFollower bool
+		query = `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
+		err = db.QueryRow(query, ownerId, userId).Scan(&isFollower)
+		if err != nil {
+			return err
+		}
+		if !isFollower {
+			return errors.New("only followers of the author can comment on this post")
+		}
+	case CommentPolicyMentioned:
+		var isMentioned bool
+		query = `SELECT EXISTS(SELECT 1 FROM POST_MENTIONS WHERE POST_ID = ? AND USER_ID = ?)`
+		err = db.QueryRow(query, postId, userId).Scan(&isMentioned)
+		if err != nil {
+			return err
+		}
+		if !isMentioned {
+			return errors.New("only mentioned users can comment on this post")
+		}
+	}
+
+	return nil
+}
+
+// UpdateCommentSettings modifie la politique de commentaires et/ou le verrouillage du fil.
+// Une chaîne vide pour policy ou locked laisse la valeur actuelle inchangée.
+func UpdateCommentSettings(db *sql.DB, userId, postId, policy, locked string) error {
+	var ownerId, currentPolicy string
+	var currentLocked bool
+	query := `SELECT USER_ID, COMMENT_POLICY, COMMENTS_LOCKED FROM POSTS WHERE ID = ?`
+	err := db.QueryRow(query, postId).Scan(&ownerId, &currentPolicy, &currentLocked)
+	if err == sql.ErrNoRows {
+		return errors.New("post not found")
+	}
+	if err != nil {
+		return err
+	}
+	if ownerId != userId {
+		return errors.New("you are not the owner of this post")
+	}
+
+	if policy != "" && !isValidCommentPolicy(policy) {
+		return errors.New("invalid comment policy")
+	}
+	if locked != "" && locked != "true" && locked != "false" {
+		return errors.New("invalid locked value")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	if policy != "" && policy != currentPolicy {
+		_, err = tx.Exec(`UPDATE POSTS SET COMMENT_POLICY = ? WHERE ID = ?`, policy, postId)
+		if err != nil {
+			return errors.Wrap(err, "failed to update comment policy")
+		}
+		err = addModerationLog(tx, postId, userId, "policy", "", "", policy)
+		if err != nil {
+			return err
+		}
+	}
+
+	if locked != "" && (locked == "true") != currentLocked {
+		action := "unlock"
+		if locked == "true" {
+			action = "lock"
+		}
+		_, err = tx.Exec(`UPDATE POSTS SET COMMENTS_LOCKED = ? WHERE ID = ?`, locked == "true", postId)
+		if err != nil {
+			return errors.Wrap(err, "failed to update comment lock")
+		}
+		err = addModerationLog(tx, postId, userId, action, "", "", "")
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// HideComment masque ou réaffiche un commentaire. Seul l'auteur du post peut le faire.
+func HideComment(db *sql.DB, userId, commentId string, hidden bool) error {
+	var postId, postOwner, commentAuthor string
+	query := `SELECT c.POST_ID, p.USER_ID, c.USER_ID FROM COMMENT c JOIN POSTS p ON c.POST_ID = p.ID WHERE c.ID = ?`
+	err := db.QueryRow(query, commentId).Scan(&postId, &postOwner, &commentAuthor)
+	if err == sql.ErrNoRows {
+		return errors.New("comment not found")
+	}
+	if err != nil {
+		return err
+	}
+	if postOwner != userId {
+		return errors.New("you are not the owner of this post")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE COMMENT SET HIDDEN = ? WHERE ID = ?`, hidden, commentId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update comment visibility")
+	}
+
+	action := "unhide"
+	if hidden {
+		action = "hide"
+	}
+	err = addModerationLog(tx, postId, userId, action, commentId, commentAuthor, "")
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// GetCommentModeration renvoie le journal de modération d'un post à son auteur.
+func GetCommentModeration(db *sql.DB, userId, postId string) ([]ModerationLog, error) {
+	var logs []ModerationLog
+
+	var ownerId string
+	err := db.QueryRow(`SELECT USER_ID FROM POSTS WHERE ID = ?`, postId).Scan(&ownerId)
+	if err == sql.ErrNoRows {
+		return logs, errors.New("post not found")
+	}
+	if err != nil {
+		return logs, err
+	}
+	if ownerId != userId {
+		return logs, errors.New("you are not the owner of this post")
+	}
+
+	query := `SELECT ID, POST_ID, ACTION, COMMENT_ID, COMMENT_AUTHOR_ID, DETAILS, CREATED_AT
+	          FROM COMMENT_MODERATION WHERE POST_ID = ? ORDER BY CREATED_AT DESC`
+	rows, err := db.Query(query, postId)
+	if err != nil {
+		return logs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l ModerationLog
+		var commentId, authorId, details sql.NullString
+
+		err = rows.Scan(&l.Id, &l.PostId, &l.Action, &commentId, &authorId, &details, &l.CreatedAt)
+		if err != nil {
+			return logs, err
+		}
+
+		if commentId.Valid {
+			l.CommentId = commentId.String
+		}
+		if details.Valid {
+			l.Details = details.String
+		}
+		if authorId.Valid {
+			l.CommentAuthor, err = getUserByID(db, authorId.String)
+			if err != nil {
+				return logs, err
+			}
+		}
+
+		logs = append(logs, l)
+	}
+
+	return logs, rows.Err()
+}
+
+func addModerationLog(tx *sql.Tx, postId, moderatorId, action, commentId, commentAuthor, details string) error {
+	query := `INSERT INTO COMMENT_MODERATION(ID, POST_ID, MODERATOR_ID, ACTION, COMMENT_ID, COMMENT_AUTHOR_ID, DETAILS, CREATED_AT)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'))`
+	_, err := tx.Exec(query, uuid.New().String(), postId, moderatorId, action,
+		toNullString(commentId), toNullString(commentAuthor), toNullString(details))
+	if err != nil {
+		return errors.Wrap(err, "failed to insert moderation log")
+	}
+	return nil
+}
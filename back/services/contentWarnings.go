@@ -0,0 +1,124 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	SensitiveCollapse = "collapse"
+	SensitiveExpand   = "expand"
+
+	maxContentWarningLength = 140
+)
+
+// ContentFlags regroupe l'avertissement de contenu et le marquage "média sensible"
+// d'un post, d'un commentaire ou d'un message.
+type ContentFlags struct {
+	Warning   string
+	Sensitive bool
+}
+
+// Flagged indique si le contenu doit être replié / flouté par défaut.
+func (f ContentFlags) Flagged() bool {
+	return f.Warning != "" || f.Sensitive
+}
+
+// NewContentFlags nettoie et valide les valeurs envoyées par le client.
+func NewContentFlags(warning, sensitive string) (ContentFlags, error) {
+	flags := ContentFlags{Warning: strings.TrimSpace(warning)}
+
+	if utf8.RuneCountInString(flags.Warning) > maxContentWarningLength {
+		return flags, errors.New("content warning too long (max 140 characters)")
+	}
+
+	switch sensitive {
+	case "", "false", "0":
+	case "true", "1":
+		flags.Sensitive = true
+	default:
+		return flags, errors.New("invalid sensitive value")
+	}
+
+	return flags, nil
+}
+
+// ContentFlagsUpdate : signalements envoyés lors d'une modification (nil : inchangé).
+type ContentFlagsUpdate struct {
+	Warning   *string
+	Sensitive *bool
+}
+
+// NewContentFlagsUpdate valide les champs content_warning et sensitive présents dans le formulaire.
+func NewContentFlagsUpdate(form url.Values) (ContentFlagsUpdate, error) {
+	var update ContentFlagsUpdate
+	flags, err := NewContentFlags(form.Get("content_warning"), form.Get("sensitive"))
+	if err != nil {
+		return update, err
+	}
+	if _, ok := form["content_warning"]; ok {
+		update.Warning = &flags.Warning
+	}
+	if _, ok := form["sensitive"]; ok {
+		update.Sensitive = &flags.Sensitive
+	}
+	return update, nil
+}
+
+// UpdateSensitivePreference modifie la préférence d'affichage du contenu signalé de l'utilisateur.
+func UpdateSensitivePreference(db *sql.DB, userId, preference string) error {
+	if preference != SensitiveCollapse && preference != SensitiveExpand {
+		return errors.New("invalid preference, must be collapse or expand")
+	}
+
+	_, err := db.Exec(`UPDATE USER SET SENSITIVE_CONTENT = ? WHERE ID = ?`, preference, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update sensitive content preference")
+	}
+
+	return nil
+}
+
+// ShouldBlurImage indique si l'image doit être servie floutée à l'utilisateur :
+// elle appartient à un contenu signalé, l'utilisateur n'en est pas l'auteur
+// et sa préférence est de replier le contenu signalé.
+func ShouldBlurImage(db *sql.DB, userID, typeImg, imgID string) (bool, error) {
+	var query string
+	switch typeImg {
+	case "postImages":
+		query = `SELECT USER_ID, CONTENT_WARNING, SENSITIVE FROM POSTS WHERE IMAGE = ? LIMIT 1`
+	case "commentImages":
+		query = `SELECT USER_ID, CONTENT_WARNING, SENSITIVE FROM COMMENT WHERE IMAGE = ? LIMIT 1`
+	case "messageImages":
+		query = `SELECT SENDER_ID, CONTENT_WARNING, SENSITIVE FROM MESSAGES WHERE CONTENT = ? AND TYPE = 1 LIMIT 1`
+	default:
+		return false, nil
+	}
+
+	var ownerId string
+	var warning sql.NullString
+	var flags ContentFlags
+	err := db.QueryRow(query, imgID).Scan(&ownerId, &warning, &flags.Sensitive)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	flags.Warning = warning.String
+
+	if !flags.Flagged() || ownerId == userID {
+		return false, nil
+	}
+
+	var preference string
+	err = db.QueryRow(`SELECT SENSITIVE_CONTENT FROM USER WHERE ID = ?`, userID).Scan(&preference)
+	if err != nil {
+		return false, err
+	}
+
+	return preference != SensitiveExpand, nil
+}
@@ -4,9 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/google/uuid"
+	"time"
 )
 
-func CreateEventGroup(db *sql.DB, userId, groupId, eventDesc, optionA, optionB, title, date string) (string, error) {
+func CreateEventGroup(db *sql.DB, userId, groupId string, in EventInput) (string, error) {
 	// Vérifie que l'utilisateur est bien membre du groupe
 	var exists bool
 	err := db.QueryRow(`
@@ -22,14 +23,52 @@ func CreateEventGroup(db *sql.DB, userId, groupId, eventDesc, optionA, optionB,
 		return "", errors.New("l'utilisateur n'est pas membre de ce groupe")
 	}
 
-	// Préparation des données
+	// Validation des données (heures converties en UTC)
+	f, err := in.validate()
+	if err != nil {
+		return "", err
+	}
+	if !f.start.After(time.Now()) {
+		return "", errors.New("event must start in the future")
+	}
+
+	var rule sql.NullString
+	if f.rule != nil {
+		rule = sql.NullString{String: f.rule.String(), Valid: true}
+	}
+
 	id := uuid.New().String()
 
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
 	// Insertion de l'événement
-	_, err = db.Exec(`
-		INSERT INTO GROUPS_EVENT (ID, GROUP_ID, SENDER, TITLE, DESCRIPTION, OPTION_A, OPTION_B, DATE_TIME, CREATED_AT)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
-	`, id, groupId, userId, title, eventDesc, optionA, optionB, date)
+	_, err = tx.Exec(`
+		INSERT INTO GROUPS_EVENT (ID, GROUP_ID, SENDER, TITLE, DESCRIPTION, START_AT, END_AT, TIME_ZONE, LOCATION, CAPACITY, RRULE, LAST_START_AT, CREATED_AT)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	`, id, groupId, userId, f.title, f.description, f.start.UTC().Format(eventSQLTimeLayout), f.end, f.timeZone, f.location, f.capacity,
+		rule, f.lastStart.UTC().Format(eventSQLTimeLayout))
+	if err != nil {
+		return "", err
+	}
+
+	if err = saveEventReminders(tx, id, f.reminders); err != nil {
+		return "", err
+	}
+
+	// L'organisateur participe d'office à un événement unique ; pour une série il répond par occurrence
+	if f.rule == nil {
+		if err = setEventRSVP(tx, id, "", userId, RSVPGoing); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
 
-	return id, err
+	return id, nil
 }
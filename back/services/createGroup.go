@@ -3,20 +3,43 @@ package services
 import (
 	"database/sql"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
-func CreateGroup(db *sql.DB, userID, title, desc, img string) error {
+func CreateGroup(db *sql.DB, userID, title, desc, img, visibility string) error {
+	if visibility == "" {
+		visibility = GroupClosed
+	}
+	if !isValidGroupVisibility(visibility) {
+		return errors.New("invalid visibility (public, closed or secret)")
+	}
+
 	groupId := uuid.New().String()
 	memberId := uuid.New().String()
-	query := `INSERT INTO ALL_GROUPS VALUES (?,?,?,?,?,datetime('now'))`
-	_, err := db.Exec(query, groupId, title, desc, userID, img)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO ALL_GROUPS(ID, TITLE, DESCRIPTION, OWNER, IMAGE, CREATED_AT, VISIBILITY) VALUES (?,?,?,?,?,datetime('now'),?)`
+	_, err = tx.Exec(query, groupId, title, desc, userID, img, visibility)
 	if err != nil {
 		return err
 	}
-	query = `INSERT INTO GROUPS_MEMBERS VALUES (?,?,?,datetime('now'))`
-	_, err = db.Exec(query, memberId, userID, groupId)
+	query = `INSERT INTO GROUPS_MEMBERS(ID, USER_ID, GROUP_ID, CREATED_AT, ROLE) VALUES (?,?,?,datetime('now'),?)`
+	_, err = tx.Exec(query, memberId, userID, groupId, RoleOwner)
 	if err != nil {
 		return err
 	}
+	if err = createDefaultGroupChannel(tx, groupId, userID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
 	return nil
 }
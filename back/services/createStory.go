@@ -0,0 +1,85 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// CreateStory publie une story (texte et/ou image) qui expire 24h après sa création.
+// La visibilité suit les mêmes règles que CreatePost : liste privée, followers ou public.
+func CreateStory(db *sql.DB, userId, content, image, privacy string, users []string) (string, error) {
+	if strings.TrimSpace(content) == "" && image == "" {
+		return "", errors.New("a story must contain text or an image")
+	}
+
+	privacyStory := PrivacyFriends
+	if privacy == "public" && len(users) == 0 {
+		privacyStory = PrivacyPublic
+	}
+	if len(users) > 0 {
+		privacyStory = PrivacyPrivate
+		for _, user := range users {
+			var isFollowing bool
+			query := `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE FOLLOWERS = ? AND USER_ID = ?)`
+			err := db.QueryRow(query, user, userId).Scan(&isFollowing)
+			if err != nil {
+				return "", err
+			}
+			if !isFollowing {
+				return "", errors.New("One or more users are not your followers")
+			}
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	id := uuid.New().String()
+	query := `
+		INSERT INTO STORIES(ID, USER_ID, CONTENT, IMAGE, PRIVACY, CREATED_AT, EXPIRE_AT)
+		VALUES (?, ?, ?, ?, ?, datetime('now'), datetime('now', '+24 hours'))
+	`
+	_, err = tx.Exec(query, id, userId, toNullString(content), toNullString(image), privacyStory)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to insert story")
+	}
+
+	if privacyStory == PrivacyPrivate {
+		for _, user := range users {
+			query = `INSERT INTO LIST_PRIVATE_STORY(ID, STORY_ID, USER_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+			_, err = tx.Exec(query, uuid.New().String(), id, user)
+			if err != nil {
+				return "", errors.Wrap(err, "failed to insert private story member")
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "transaction commit failed")
+	}
+
+	return id, nil
+}
+
+// DeleteStory supprime une story avant son expiration. Seul l'auteur peut le faire.
+func DeleteStory(db *sql.DB, userId, storyId string) error {
+	var owner string
+	var image sql.NullString
+	err := db.QueryRow(`SELECT USER_ID, IMAGE FROM STORIES WHERE ID = ?`, storyId).Scan(&owner, &image)
+	if err == sql.ErrNoRows {
+		return errors.New("story not found")
+	}
+	if err != nil {
+		return err
+	}
+	if owner != userId {
+		return errors.New("you are not the owner of this story")
+	}
+
+	return deleteStories(db, []string{storyId}, []string{image.String})
+}
@@ -6,20 +6,55 @@ import (
 	"fmt"
 )
 
+// DeleteComment supprime un commentaire. L'auteur du commentaire peut le supprimer,
+// ainsi que l'auteur du post et, pour un post de groupe, les modérateurs du groupe ;
+// une entrée est alors ajoutée au journal de modération.
 func DeleteComment(db *sql.DB, userId, commentId string) error {
-	// Suppression conditionnelle du commentaire
-	query := `DELETE FROM COMMENT WHERE ID = ? AND USER_ID = ?`
-	res, err := db.Exec(query, commentId, userId)
+	var postId, postOwner, commentAuthor, content string
+	var groupId sql.NullString
+	query := `SELECT c.POST_ID, p.USER_ID, c.USER_ID, c.CONTENT, p.GROUP_ID FROM COMMENT c JOIN POSTS p ON c.POST_ID = p.ID WHERE c.ID = ?`
+	err := db.QueryRow(query, commentId).Scan(&postId, &postOwner, &commentAuthor, &content, &groupId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("comment not found or user not authorized")
+	}
 	if err != nil {
-		return fmt.Errorf("error executing delete statement: %w", err)
+		return fmt.Errorf("error fetching comment: %w", err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
+	if commentAuthor != userId && postOwner != userId {
+		canModerate := false
+		if groupId.Valid && groupId.String != "" {
+			canModerate, err = canModerateGroupContent(db, userId, groupId.String)
+			if err != nil {
+				return fmt.Errorf("error fetching group role: %w", err)
+			}
+		}
+		if !canModerate {
+			return errors.New("comment not found or user not authorized")
+		}
+	}
+
+	tx, err := db.Begin()
 	if err != nil {
-		return fmt.Errorf("error retrieving affected rows: %w", err)
+		return fmt.Errorf("transaction begin failed: %w", err)
 	}
-	if rowsAffected == 0 {
-		return errors.New("comment not found or user not authorized")
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM COMMENT WHERE ID = ?`, commentId)
+	if err != nil {
+		return fmt.Errorf("error executing delete statement: %w", err)
+	}
+
+	// Modération par l'auteur du post
+	if commentAuthor != userId {
+		err = addModerationLog(tx, postId, userId, "delete", commentId, commentAuthor, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("transaction commit failed: %w", err)
 	}
 
 	return nil
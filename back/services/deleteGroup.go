@@ -2,25 +2,212 @@ package services
 
 import (
 	"database/sql"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"log"
+	"social-network/utils"
 )
 
+// groupFile : image liée au groupe, supprimée du disque une fois la transaction validée
+type groupFile struct {
+	dir  string // sous-dossier de Images/
+	name string
+}
+
+type GroupDeletedNotification struct {
+	GroupID   string `json:"group_id"`
+	GroupName string `json:"group_name"`
+	DeletedBy User   `json:"deleted_by"`
+	DeletedAt string `json:"deleted_at"`
+}
+
+// Sous-requêtes des éléments rattachés au groupe (?1 = ID du groupe)
+const (
+	groupPostIds  = `SELECT ID FROM POSTS WHERE GROUP_ID = ?1`
+	groupEventIds = `SELECT ID FROM GROUPS_EVENT WHERE GROUP_ID = ?1`
+	groupAskIds   = `SELECT ID FROM ASK_GROUP WHERE GROUP_ID = ?1`
+)
+
+// Suppressions effectuées par purgeGroup, enfants avant parents
+var groupPurgeQueries = []struct{ label, query string }{
+	{"comment events", `DELETE FROM COMMENT_EVENT WHERE COMMENT_ID IN (SELECT ID FROM COMMENT WHERE POST_ID IN (` + groupPostIds + `))`},
+	{"comments", `DELETE FROM COMMENT WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"comment moderation", `DELETE FROM COMMENT_MODERATION WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"post events", `DELETE FROM POST_EVENT WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"tags", `DELETE FROM TAGS WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"post mentions", `DELETE FROM POST_MENTIONS WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"post views", `DELETE FROM POST_VIEWS WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"post audience lists", `DELETE FROM POST_AUDIENCE_LISTS WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"private post members", `DELETE FROM LIST_PRIVATE_POST WHERE POST_ID IN (` + groupPostIds + `)`},
+	{"posts", `DELETE FROM POSTS WHERE GROUP_ID = ?1`},
+	{"event rsvps", `DELETE FROM EVENT_RSVPS WHERE EVENT_ID IN (` + groupEventIds + `)`},
+	{"event occurrences", `DELETE FROM EVENT_OCCURRENCES WHERE EVENT_ID IN (` + groupEventIds + `)`},
+	{"event reminders", `DELETE FROM EVENT_REMINDERS WHERE EVENT_ID IN (` + groupEventIds + `)`},
+	{"sent event reminders", `DELETE FROM EVENT_REMINDERS_SENT WHERE EVENT_ID IN (` + groupEventIds + `)`},
+	{"events", `DELETE FROM GROUPS_EVENT WHERE GROUP_ID = ?1`},
+	{"group answers", `DELETE FROM GROUP_ANSWERS WHERE ASK_ID IN (` + groupAskIds + `)`},
+	{"group requests", `DELETE FROM ASK_GROUP WHERE GROUP_ID = ?1`},
+	{"join decisions", `DELETE FROM GROUP_JOIN_DECISIONS WHERE GROUP_ID = ?1`},
+	{"group questions", `DELETE FROM GROUP_QUESTIONS WHERE GROUP_ID = ?1`},
+	{"group transfers", `DELETE FROM GROUP_TRANSFERS WHERE GROUP_ID = ?1`},
+	{"group members", `DELETE FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1`},
+	{"invite links", `DELETE FROM GROUP_INVITE_LINKS WHERE GROUP_ID = ?1`},
+	{"group rules", `DELETE FROM GROUP_RULES WHERE GROUP_ID = ?1`},
+	{"group announcements", `DELETE FROM GROUP_ANNOUNCEMENTS WHERE GROUP_ID = ?1`},
+	{"group bans", `DELETE FROM GROUP_BANS WHERE GROUP_ID = ?1`},
+	{"group messages", `DELETE FROM MESSAGES WHERE GROUP_ID = ?1`},
+	{"channel subscriptions", `DELETE FROM GROUP_CHANNEL_UNSUBSCRIBED WHERE CHANNEL_ID IN (SELECT ID FROM GROUP_CHANNELS WHERE GROUP_ID = ?1)`},
+	{"group channels", `DELETE FROM GROUP_CHANNELS WHERE GROUP_ID = ?1`},
+	{"group files", `DELETE FROM GROUP_FILES WHERE GROUP_ID = ?1`},
+	{"group file folders", `DELETE FROM GROUP_FILE_FOLDERS WHERE GROUP_ID = ?1`},
+	{"group mutes", `DELETE FROM MUTES WHERE TARGET_TYPE = 'group' AND TARGET_ID = ?1`},
+}
+
+// DeleteGroup supprime le groupe et tout ce qui en dépend (membres, posts, événements, messages,
+// notifications, fichiers) en une seule transaction. Le groupe est archivé dans GROUP_DELETIONS
+// et les membres sont notifiés.
 func DeleteGroup(db *sql.DB, userId, groupId string) error {
-	var owner string
-	query := `SELECT OWNER FROM ALL_GROUPS WHERE ID = ?`
-	err := db.QueryRow(query, groupId).Scan(&owner)
+	if _, err := checkGroupPermission(db, userId, groupId, PermDeleteGroup); err != nil {
+		return err
+	}
+
+	var title, owner string
+	var image sql.NullString
+	query := `SELECT TITLE, OWNER, IMAGE FROM ALL_GROUPS WHERE ID = ?`
+	err := db.QueryRow(query, groupId).Scan(&title, &owner, &image)
+	if err == sql.ErrNoRows {
+		return ErrGroupNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if owner != userId {
-		return errors.New("Not allowed to delete this group")
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	archiveId := uuid.New().String()
+	query = `INSERT INTO GROUP_DELETIONS(ID, GROUP_ID, TITLE, OWNER, MEMBER_COUNT, DELETED_BY, DELETED_AT)
+	         VALUES (?, ?, ?, ?, (SELECT COUNT(*) FROM GROUPS_MEMBERS WHERE GROUP_ID = ?), ?, datetime('now'))`
+	_, err = tx.Exec(query, archiveId, groupId, title, owner, groupId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to archive group")
+	}
+
+	// Les membres sont notifiés avant que leur adhésion ne soit effacée
+	if err = notifyAllGroupMembers(tx, groupId, userId, "GROUP_DELETED", archiveId); err != nil {
+		return err
 	}
 
-	query = `DELETE FROM ALL_GROUPS WHERE ID = ?`
-	_, err = db.Exec(query, groupId)
+	_, files, err := purgeGroup(tx, groupId)
 	if err != nil {
 		return err
 	}
 
+	if _, err = tx.Exec(`DELETE FROM ALL_GROUPS WHERE ID = ?`, groupId); err != nil {
+		return errors.Wrap(err, "failed to delete group")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	if image.String != "" {
+		files = append(files, groupFile{"groupImages", image.String})
+	}
+	removeGroupFiles(files)
+
 	return nil
 }
+
+// purgeGroup supprime dans la transaction toutes les données rattachées au groupe, sauf la ligne
+// ALL_GROUPS elle-même. Renvoie le nombre de lignes supprimées et les images à effacer après validation.
+func purgeGroup(tx *sql.Tx, groupId string) (int64, []groupFile, error) {
+	files, err := groupFiles(tx, groupId)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Les notifications pointent vers les lignes supprimées ci-dessous : elles partent en premier.
+	// Celles d'une occurrence d'événement utilisent l'ID "eventId|occurrence".
+	query := `DELETE FROM NOTIFICATIONS WHERE ID_TYPE IN (
+	              ` + groupPostIds + `
+	              UNION SELECT ID FROM POST_EVENT WHERE POST_ID IN (` + groupPostIds + `)
+	              UNION SELECT ID FROM COMMENT WHERE POST_ID IN (` + groupPostIds + `)
+	              UNION SELECT ce.ID FROM COMMENT_EVENT ce JOIN COMMENT c ON c.ID = ce.COMMENT_ID
+	                    WHERE c.POST_ID IN (` + groupPostIds + `)
+	              UNION ` + groupEventIds + `
+	              UNION ` + groupAskIds + `
+	              UNION SELECT ID FROM GROUP_JOIN_DECISIONS WHERE GROUP_ID = ?1
+	              UNION SELECT ID FROM GROUP_TRANSFERS WHERE GROUP_ID = ?1
+	              UNION SELECT ID FROM GROUP_RULES WHERE GROUP_ID = ?1
+	              UNION SELECT ID FROM GROUP_ANNOUNCEMENTS WHERE GROUP_ID = ?1)
+	          OR EXISTS (SELECT 1 FROM GROUPS_EVENT e WHERE e.GROUP_ID = ?1 AND NOTIFICATIONS.ID_TYPE LIKE e.ID || '|%')`
+	res, err := tx.Exec(query, groupId)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to delete group notifications")
+	}
+	total, _ := res.RowsAffected()
+
+	for _, q := range groupPurgeQueries {
+		res, err = tx.Exec(q.query, groupId)
+		if err != nil {
+			return 0, nil, errors.Wrapf(err, "failed to delete %s", q.label)
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+
+	return total, files, nil
+}
+
+// groupFiles liste les images des posts, commentaires et messages du groupe, et sa bibliothèque de fichiers.
+func groupFiles(tx *sql.Tx, groupId string) ([]groupFile, error) {
+	query := `SELECT 'postImages', IMAGE FROM POSTS WHERE GROUP_ID = ?1 AND IFNULL(IMAGE, '') <> ''
+	          UNION ALL SELECT 'commentImages', IMAGE FROM COMMENT
+	                    WHERE POST_ID IN (` + groupPostIds + `) AND IFNULL(IMAGE, '') <> ''
+	          UNION ALL SELECT 'groupMessages', CONTENT FROM MESSAGES
+	                    WHERE GROUP_ID = ?1 AND TYPE = 1 AND IFNULL(CONTENT, '') <> ''
+	          UNION ALL SELECT 'groupFiles', STORED_NAME FROM GROUP_FILES WHERE GROUP_ID = ?1`
+	rows, err := tx.Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []groupFile
+	for rows.Next() {
+		var f groupFile
+		if err = rows.Scan(&f.dir, &f.name); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// removeGroupFiles efface les images et leurs variantes floutées. Un échec est journalisé
+// sans annuler la suppression, les lignes étant déjà effacées.
+func removeGroupFiles(files []groupFile) {
+	for _, f := range files {
+		if err := utils.RemoveImage(f.dir, f.name); err != nil {
+			log.Printf("Impossible de supprimer l'image %s/%s : %v", f.dir, f.name, err)
+		}
+	}
+}
+
+// groupDeletedNotification : données de la notification GROUP_DELETED (idType = ID de l'archive).
+func groupDeletedNotification(db *sql.DB, archiveId string) (GroupDeletedNotification, error) {
+	var n GroupDeletedNotification
+	var deletedBy string
+	query := `SELECT GROUP_ID, TITLE, DELETED_BY, DELETED_AT FROM GROUP_DELETIONS WHERE ID = ?`
+	err := db.QueryRow(query, archiveId).Scan(&n.GroupID, &n.GroupName, &deletedBy, &n.DeletedAt)
+	if err != nil {
+		return n, err
+	}
+
+	n.DeletedBy, err = getUserByID(db, deletedBy)
+	return n, err
+}
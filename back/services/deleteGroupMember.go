@@ -5,29 +5,27 @@ import (
 	"github.com/pkg/errors"
 )
 
-func DeleteGroupMember(db *sql.DB, owner, userId, groupId string) error {
-	var ownerID string
-	query := `SELECT OWNER FROM ALL_GROUPS WHERE ID = ?`
-	err := db.QueryRow(query, groupId).Scan(&ownerID)
+// DeleteGroupMember exclut un membre sans garder de trace : il peut redemander à rejoindre le groupe.
+// Pour l'empêcher de revenir, voir BanGroupMember.
+func DeleteGroupMember(db *sql.DB, actorId, userId, groupId string) error {
+	// Propriétaire ou admin, et seulement sur un membre de rang inférieur
+	_, _, err := checkOutranks(db, actorId, userId, groupId, PermRemoveMember)
 	if err != nil {
 		return err
 	}
-	if owner != ownerID {
-		return errors.New("you are not the owner of this group")
-	}
 
-	query = `DELETE FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?`
-	result, err := db.Exec(query, userId, groupId)
+	tx, err := db.Begin()
 	if err != nil {
-		return errors.Wrap(err, "failed to delete group member")
+		return errors.Wrap(err, "transaction begin failed")
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return errors.Wrap(err, "failed to get number of affected rows")
+	if err = removeGroupMember(tx, userId, groupId); err != nil {
+		return err
 	}
-	if rowsAffected == 0 {
-		return errors.New("no matching group member found to delete")
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
 	}
 
 	return nil
@@ -8,9 +8,10 @@ import (
 
 func DeletePost(db *sql.DB, postId, userId string) error {
 	var dbUserID string
+	var groupId sql.NullString
 
-	query := `SELECT USER_ID FROM POSTS WHERE ID = ?`
-	err := db.QueryRow(query, postId).Scan(&dbUserID)
+	query := `SELECT USER_ID, GROUP_ID FROM POSTS WHERE ID = ?`
+	err := db.QueryRow(query, postId).Scan(&dbUserID, &groupId)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return errors.New("post not found")
@@ -18,8 +19,18 @@ func DeletePost(db *sql.DB, postId, userId string) error {
 		return fmt.Errorf("error fetching post owner: %w", err)
 	}
 
+	// Les modérateurs d'un groupe peuvent supprimer les posts des membres
 	if dbUserID != userId {
-		return errors.New("user ID does not match")
+		canModerate := false
+		if groupId.Valid && groupId.String != "" {
+			canModerate, err = canModerateGroupContent(db, userId, groupId.String)
+			if err != nil {
+				return fmt.Errorf("error fetching group role: %w", err)
+			}
+		}
+		if !canModerate {
+			return errors.New("user ID does not match")
+		}
 	}
 
 	//  le post
@@ -42,5 +53,10 @@ func DeletePost(db *sql.DB, postId, userId string) error {
 		return errors.New("no post deleted, ID might be incorrect")
 	}
 
+	_, err = db.Exec(`DELETE FROM POST_AUDIENCE_LISTS WHERE POST_ID = ?`, postId)
+	if err != nil {
+		return fmt.Errorf("error deleting post audience lists: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,427 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventSeries : un événement tel qu'enregistré, unique ou récurrent.
+type eventSeries struct {
+	id          string
+	groupId     string
+	sender      string
+	title       string
+	description string
+	start       time.Time     // UTC, première occurrence
+	duration    time.Duration // 0 sans heure de fin
+	timeZone    string
+	loc         *time.Location
+	location    string
+	capacity    int // 0 : illimité
+	cancelled   bool
+	rule        *recurrence // nil : événement unique
+	created     string
+	updated     string // dernière modification de la série, CREATED_AT à défaut
+}
+
+// eventOccurrence : une occurrence avec ses éventuelles modifications appliquées.
+// key vaut ” pour un événement unique, sinon le début prévu par la règle (UTC, format SQL).
+type eventOccurrence struct {
+	key         string
+	start       time.Time
+	end         time.Time // zéro sans heure de fin
+	title       string
+	description string
+	location    string
+	cancelled   bool // série ou occurrence annulée
+	modified    bool
+	updated     string
+}
+
+type occurrenceOverride struct {
+	title       sql.NullString
+	description sql.NullString
+	start       sql.NullString
+	end         sql.NullString
+	location    sql.NullString
+	cancelled   bool
+	updated     string
+}
+
+const eventSeriesSelect = `SELECT e.ID, e.GROUP_ID, e.SENDER, e.TITLE, e.DESCRIPTION, e.START_AT, IFNULL(e.END_AT, ''),
+	e.TIME_ZONE, IFNULL(e.LOCATION, ''), IFNULL(e.CAPACITY, 0), e.CANCELLED_AT IS NOT NULL, IFNULL(e.RRULE, ''),
+	e.CREATED_AT, IFNULL(e.UPDATED_AT, e.CREATED_AT)
+	FROM GROUPS_EVENT e`
+
+func scanEventSeries(row interface{ Scan(...any) error }) (eventSeries, error) {
+	var s eventSeries
+	var start, end, rule string
+
+	err := row.Scan(&s.id, &s.groupId, &s.sender, &s.title, &s.description, &start, &end, &s.timeZone, &s.location,
+		&s.capacity, &s.cancelled, &rule, &s.created, &s.updated)
+	if err != nil {
+		return s, err
+	}
+
+	s.loc, err = time.LoadLocation(s.timeZone)
+	if err != nil {
+		s.loc = time.UTC
+	}
+	s.start, err = time.Parse(eventSQLTimeLayout, start)
+	if err != nil {
+		return s, errors.Wrap(err, "invalid event start")
+	}
+	if end != "" {
+		if e, err := time.Parse(eventSQLTimeLayout, end); err == nil {
+			s.duration = e.Sub(s.start)
+		}
+	}
+	if rule != "" {
+		s.rule, err = parseRecurrence(rule, s.loc)
+		if err != nil {
+			return s, err
+		}
+	}
+
+	return s, nil
+}
+
+func loadEventSeries(db *sql.DB, eventId string) (eventSeries, error) {
+	s, err := scanEventSeries(db.QueryRow(eventSeriesSelect+` WHERE e.ID = ?`, eventId))
+	if err == sql.ErrNoRows {
+		return s, errors.New("event not found")
+	}
+	return s, err
+}
+
+func eventOverrides(db *sql.DB, eventId string) (map[string]occurrenceOverride, error) {
+	overrides := map[string]occurrenceOverride{}
+
+	query := `SELECT OCCURRENCE_AT, TITLE, DESCRIPTION, START_AT, END_AT, LOCATION, CANCELLED_AT IS NOT NULL, UPDATED_AT
+	          FROM EVENT_OCCURRENCES WHERE EVENT_ID = ?`
+	rows, err := db.Query(query, eventId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var o occurrenceOverride
+		if err = rows.Scan(&key, &o.title, &o.description, &o.start, &o.end, &o.location, &o.cancelled, &o.updated); err != nil {
+			return nil, err
+		}
+		overrides[key] = o
+	}
+
+	return overrides, rows.Err()
+}
+
+// occurrenceStarts renvoie les débuts prévus par la série (un seul pour un événement unique).
+func (s eventSeries) occurrenceStarts() ([]time.Time, error) {
+	if s.rule == nil {
+		return []time.Time{s.start}, nil
+	}
+	starts, err := s.rule.expand(s.start.In(s.loc))
+	if err != nil {
+		return nil, err
+	}
+	for i := range starts {
+		starts[i] = starts[i].UTC()
+	}
+	return starts, nil
+}
+
+func (s eventSeries) occurrenceKeyOf(start time.Time) string {
+	if s.rule == nil {
+		return ""
+	}
+	return start.UTC().Format(eventSQLTimeLayout)
+}
+
+// apply construit l'occurrence prévue à start en appliquant ses modifications.
+func (s eventSeries) apply(start time.Time, overrides map[string]occurrenceOverride) eventOccurrence {
+	o := eventOccurrence{
+		key:         s.occurrenceKeyOf(start),
+		start:       start,
+		title:       s.title,
+		description: s.description,
+		location:    s.location,
+		cancelled:   s.cancelled,
+		updated:     s.updated,
+	}
+	if s.duration > 0 {
+		o.end = start.Add(s.duration)
+	}
+
+	ov, ok := overrides[o.key]
+	if !ok || o.key == "" {
+		return o
+	}
+	o.modified = true
+	o.cancelled = o.cancelled || ov.cancelled
+	if ov.updated > o.updated {
+		o.updated = ov.updated
+	}
+	if ov.title.Valid {
+		o.title = ov.title.String
+	}
+	if ov.description.Valid {
+		o.description = ov.description.String
+	}
+	if ov.location.Valid {
+		o.location = ov.location.String
+	}
+	if ov.start.Valid {
+		if t, err := time.Parse(eventSQLTimeLayout, ov.start.String); err == nil {
+			o.start = t
+			o.end = time.Time{}
+		}
+	}
+	if ov.end.Valid {
+		if t, err := time.Parse(eventSQLTimeLayout, ov.end.String); err == nil {
+			o.end = t
+		}
+	}
+	return o
+}
+
+// occurrences renvoie les occurrences dont le début (après modification) est dans [from, to).
+func (s eventSeries) occurrences(from, to time.Time, overrides map[string]occurrenceOverride) ([]eventOccurrence, error) {
+	starts, err := s.occurrenceStarts()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []eventOccurrence
+	for _, start := range starts {
+		o := s.apply(start, overrides)
+		if !o.start.Before(from) && o.start.Before(to) {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+// occurrence renvoie l'occurrence key de la série ; key doit être vide pour un événement unique.
+func (s eventSeries) occurrence(key string, overrides map[string]occurrenceOverride) (eventOccurrence, error) {
+	if s.rule == nil {
+		if key != "" {
+			return eventOccurrence{}, errors.New("this event is not recurring")
+		}
+		return s.apply(s.start, overrides), nil
+	}
+	if key == "" {
+		return eventOccurrence{}, errors.New("missing occurrence for a recurring event")
+	}
+
+	starts, err := s.occurrenceStarts()
+	if err != nil {
+		return eventOccurrence{}, err
+	}
+	for _, start := range starts {
+		if s.occurrenceKeyOf(start) == key {
+			return s.apply(start, overrides), nil
+		}
+	}
+	return eventOccurrence{}, errors.New("invalid occurrence")
+}
+
+// parseOccurrenceKey accepte le début prévu de l'occurrence en RFC 3339 (tel que renvoyé par l'API).
+func parseOccurrenceKey(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(eventSQLTimeLayout, value)
+		if err != nil {
+			return "", errors.New("invalid occurrence")
+		}
+	}
+	return t.UTC().Format(eventSQLTimeLayout), nil
+}
+
+// formatOccurrenceKey : forme API de la clé (RFC 3339 en UTC), vide pour un événement unique.
+func formatOccurrenceKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	t, err := time.Parse(eventSQLTimeLayout, key)
+	if err != nil {
+		return key
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Notifications d'une occurrence : ID_TYPE = "<event>|<occurrence>", l'ID seul pour toute la série.
+func eventNotifID(eventId, occurrence string) string {
+	if occurrence == "" {
+		return eventId
+	}
+	return eventId + "|" + occurrence
+}
+
+func splitEventNotifID(idType string) (string, string) {
+	eventId, occurrence, _ := strings.Cut(idType, "|")
+	return eventId, occurrence
+}
+
+// UpdateEventOccurrence modifie une seule occurrence d'un événement récurrent (titre, description,
+// début, fin, lieu). Le fuseau, la capacité et les rappels restent ceux de la série.
+func UpdateEventOccurrence(db *sql.DB, userId, eventId, occurrence string, in EventInput) (string, string, error) {
+	s, key, err := manageableOccurrence(db, userId, eventId, occurrence)
+	if err != nil {
+		return "", "", err
+	}
+
+	in.TimeZone = s.timeZone
+	in.Recurrence = ""
+	in.Reminders = []int{}
+	f, err := in.validate()
+	if err != nil {
+		return "", "", err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", "", errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO EVENT_OCCURRENCES(EVENT_ID, OCCURRENCE_AT, TITLE, DESCRIPTION, START_AT, END_AT, LOCATION, UPDATED_AT)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	          ON CONFLICT(EVENT_ID, OCCURRENCE_AT) DO UPDATE SET TITLE = excluded.TITLE, DESCRIPTION = excluded.DESCRIPTION,
+	          START_AT = excluded.START_AT, END_AT = excluded.END_AT, LOCATION = excluded.LOCATION, UPDATED_AT = excluded.UPDATED_AT`
+	_, err = tx.Exec(query, eventId, key, f.title, f.description, f.start.UTC().Format(eventSQLTimeLayout), f.end, f.location)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to update occurrence")
+	}
+
+	// Les rappels repartent selon le nouvel horaire
+	_, err = tx.Exec(`DELETE FROM EVENT_REMINDERS_SENT WHERE EVENT_ID = ? AND OCCURRENCE_AT = ?`, eventId, key)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to reset occurrence reminders")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", "", errors.Wrap(err, "transaction commit failed")
+	}
+
+	return s.groupId, key, nil
+}
+
+// CancelEventOccurrence annule une seule occurrence d'un événement récurrent.
+func CancelEventOccurrence(db *sql.DB, userId, eventId, occurrence string) (string, string, error) {
+	s, key, err := manageableOccurrence(db, userId, eventId, occurrence)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := `INSERT INTO EVENT_OCCURRENCES(EVENT_ID, OCCURRENCE_AT, CANCELLED_AT, UPDATED_AT)
+	          VALUES (?, ?, datetime('now'), datetime('now'))
+	          ON CONFLICT(EVENT_ID, OCCURRENCE_AT) DO UPDATE SET CANCELLED_AT = excluded.CANCELLED_AT, UPDATED_AT = excluded.UPDATED_AT`
+	if _, err = db.Exec(query, eventId, key); err != nil {
+		return "", "", errors.Wrap(err, "failed to cancel occurrence")
+	}
+
+	return s.groupId, key, nil
+}
+
+func manageableOccurrence(db *sql.DB, userId, eventId, occurrence string) (eventSeries, string, error) {
+	if _, err := canManageEvent(db, userId, eventId); err != nil {
+		return eventSeries{}, "", err
+	}
+
+	s, err := loadEventSeries(db, eventId)
+	if err != nil {
+		return s, "", err
+	}
+	if s.rule == nil {
+		return s, "", errors.New("this event is not recurring")
+	}
+
+	key, err := parseOccurrenceKey(occurrence)
+	if err != nil {
+		return s, "", err
+	}
+	overrides, err := eventOverrides(db, eventId)
+	if err != nil {
+		return s, "", err
+	}
+	o, err := s.occurrence(key, overrides)
+	if err != nil {
+		return s, "", err
+	}
+	if o.cancelled {
+		return s, "", errors.New("this occurrence has been cancelled")
+	}
+
+	return s, key, nil
+}
+
+// remapOccurrences reporte réponses et modifications de la i-ème occurrence de l'ancienne série
+// sur la i-ème de la nouvelle ; celles qui n'existent plus sont supprimées.
+func remapOccurrences(tx *sql.Tx, eventId string, oldKeys, newKeys []string) error {
+	for _, table := range []string{"EVENT_RSVPS", "EVENT_OCCURRENCES"} {
+		// Deux passes pour éviter les collisions quand les occurrences se décalent
+		query := `UPDATE ` + table + ` SET OCCURRENCE_AT = ? WHERE EVENT_ID = ? AND OCCURRENCE_AT = ?`
+		for i, key := range oldKeys {
+			if i >= len(newKeys) {
+				break
+			}
+			if _, err := tx.Exec(query, "~"+strconv.Itoa(i), eventId, key); err != nil {
+				return errors.Wrap(err, "failed to move occurrence")
+			}
+		}
+		_, err := tx.Exec(`DELETE FROM `+table+` WHERE EVENT_ID = ? AND OCCURRENCE_AT NOT LIKE '~%'`, eventId)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete removed occurrences")
+		}
+		for i := range oldKeys {
+			if i >= len(newKeys) {
+				break
+			}
+			if _, err = tx.Exec(query, newKeys[i], eventId, "~"+strconv.Itoa(i)); err != nil {
+				return errors.Wrap(err, "failed to move occurrence")
+			}
+		}
+	}
+
+	// Les notifications d'une occurrence suivent sa nouvelle date ; celles d'une occurrence supprimée
+	// désignent désormais toute la série
+	query := `UPDATE NOTIFICATIONS SET ID_TYPE = ? WHERE ID_TYPE = ?`
+	for i, key := range oldKeys {
+		if key == "" {
+			continue
+		}
+		if _, err := tx.Exec(query, eventNotifID(eventId, "~"+strconv.Itoa(i)), eventNotifID(eventId, key)); err != nil {
+			return errors.Wrap(err, "failed to move occurrence notifications")
+		}
+	}
+	for i, key := range oldKeys {
+		if key == "" {
+			continue
+		}
+		newId := eventId
+		if i < len(newKeys) {
+			newId = eventNotifID(eventId, newKeys[i])
+		}
+		if _, err := tx.Exec(query, newId, eventNotifID(eventId, "~"+strconv.Itoa(i))); err != nil {
+			return errors.Wrap(err, "failed to move occurrence notifications")
+		}
+	}
+
+	// Les modifications d'occurrence n'ont pas de sens pour un événement unique
+	if len(newKeys) == 1 && newKeys[0] == "" {
+		if _, err := tx.Exec(`DELETE FROM EVENT_OCCURRENCES WHERE EVENT_ID = ?`, eventId); err != nil {
+			return errors.Wrap(err, "failed to delete occurrences")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"log"
+	"time"
+)
+
+// StartEventReminders lance en tâche de fond l'envoi périodique des rappels d'événements.
+func StartEventReminders(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			count, err := SendEventReminders(db)
+			if err != nil {
+				log.Println("Erreur lors de l'envoi des rappels d'événements :", err)
+			} else if count > 0 {
+				log.Printf("%d rappels d'événements envoyés", count)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Un rappel part au plus 4 semaines avant le début (voir EventInput.validate)
+const maxReminderLead = 4 * 7 * 24 * time.Hour
+
+// dueReminder : occurrence dont au moins un rappel est dû.
+type dueReminder struct {
+	eventId    string
+	occurrence string
+	offsets    []int
+}
+
+// SendEventReminders notifie les participants (going, maybe) des occurrences dont un rappel est dû,
+// hors ceux qui ont mis le groupe en sourdine.
+// Plusieurs rappels dus pour la même occurrence (serveur arrêté) ne donnent qu'une notification ;
+// un rappel dont l'heure précède la dernière modification de l'occurrence ne part pas.
+func SendEventReminders(db *sql.DB) (int, error) {
+	now := time.Now().UTC()
+	nowSQL := now.Format(eventSQLTimeLayout)
+
+	query := eventSeriesSelect + ` WHERE e.CANCELLED_AT IS NULL
+	         AND EXISTS(SELECT 1 FROM EVENT_REMINDERS WHERE EVENT_ID = e.ID)
+	         AND e.START_AT <= ?2
+	         AND (e.LAST_START_AT > ?1 OR e.ID IN (SELECT EVENT_ID FROM EVENT_OCCURRENCES WHERE START_AT > ?1))`
+	rows, err := db.Query(query, nowSQL, now.Add(maxReminderLead).Format(eventSQLTimeLayout))
+	if err != nil {
+		return 0, err
+	}
+	var series []eventSeries
+	for rows.Next() {
+		s, err := scanEventSeries(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		series = append(series, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var due []dueReminder
+	for _, s := range series {
+		offsets, err := eventReminders(db, s.id)
+		if err != nil {
+			return 0, err
+		}
+		overrides, err := eventOverrides(db, s.id)
+		if err != nil {
+			return 0, err
+		}
+		occurrences, err := s.occurrences(now, now.Add(maxReminderLead+time.Minute), overrides)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, o := range occurrences {
+			if o.cancelled {
+				continue
+			}
+			updated, _ := time.Parse(eventSQLTimeLayout, o.updated)
+
+			d := dueReminder{eventId: s.id, occurrence: o.key}
+			for _, offset := range offsets {
+				fire := o.start.Add(-time.Duration(offset) * time.Minute)
+				if fire.After(now) || !fire.After(updated) {
+					continue
+				}
+				var sent bool
+				query = `SELECT EXISTS(SELECT 1 FROM EVENT_REMINDERS_SENT WHERE EVENT_ID = ? AND OCCURRENCE_AT = ? AND OFFSET_MINUTES = ?)`
+				if err = db.QueryRow(query, s.id, o.key, offset).Scan(&sent); err != nil {
+					return 0, err
+				}
+				if !sent {
+					d.offsets = append(d.offsets, offset)
+				}
+			}
+			if len(d.offsets) > 0 {
+				due = append(due, d)
+			}
+		}
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	count := 0
+	for _, d := range due {
+		n, err := sendEventReminder(tx, d)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "transaction commit failed")
+	}
+
+	return count, nil
+}
+
+func sendEventReminder(tx *sql.Tx, d dueReminder) (int, error) {
+	query := `SELECT r.USER_ID FROM EVENT_RSVPS r JOIN GROUPS_EVENT e ON e.ID = r.EVENT_ID
+	          JOIN GROUPS_MEMBERS m ON m.USER_ID = r.USER_ID AND m.GROUP_ID = e.GROUP_ID
+	          WHERE r.EVENT_ID = ? AND r.OCCURRENCE_AT = ? AND r.STATUS IN ('going', 'maybe')
+	            AND r.USER_ID NOT IN (SELECT USER_ID FROM MUTES WHERE TARGET_TYPE = 'group' AND TARGET_ID = e.GROUP_ID AND ` + activeMute + `)`
+	rows, err := tx.Query(query, d.eventId, d.occurrence)
+	if err != nil {
+		return 0, err
+	}
+	var users []string
+	for rows.Next() {
+		var userId string
+		if err = rows.Scan(&userId); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		users = append(users, userId)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	idType := eventNotifID(d.eventId, d.occurrence)
+
+	// Le rappel précédent non lu est remplacé
+	_, err = tx.Exec(`DELETE FROM NOTIFICATIONS WHERE TYPE = 'EVENT_REMINDER' AND ID_TYPE = ? AND READ = 0`, idType)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete previous reminders")
+	}
+
+	query = `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE) VALUES (?, 'EVENT_REMINDER', ?, ?)`
+	for _, userId := range users {
+		if _, err = tx.Exec(query, uuid.New().String(), userId, idType); err != nil {
+			return 0, errors.Wrap(err, "failed to insert notification")
+		}
+	}
+
+	query = `INSERT OR IGNORE INTO EVENT_REMINDERS_SENT(EVENT_ID, OCCURRENCE_AT, OFFSET_MINUTES, SENT_AT)
+	         VALUES (?, ?, ?, datetime('now'))`
+	for _, offset := range d.offsets {
+		if _, err = tx.Exec(query, d.eventId, d.occurrence, offset); err != nil {
+			return 0, errors.Wrap(err, "failed to update event reminders")
+		}
+	}
+
+	return len(users), nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const (
+	DefaultFollowListLimit = 50
+	MaxFollowListLimit     = 100
+)
+
+const (
+	followListFollowers = "followers"
+	followListFollowing = "following"
+	followListMutuals   = "mutuals"
+)
+
+var ErrPrivateFollowList = errors.New("this account is private: only its followers can see this list")
+
+type FollowListOptions struct {
+	Search string
+	Cursor string
+	Limit  int // 0 : liste complète, sans pagination
+}
+
+type FollowListPage struct {
+	Entries    []ListOfFollowers
+	NextCursor string
+}
+
+// SendFollowersPage renvoie une page des abonnés de target.
+func SendFollowersPage(db *sql.DB, viewerId, target string, opts FollowListOptions) (FollowListPage, error) {
+	return sendFollowList(db, viewerId, target, followListFollowers, opts)
+}
+
+// SendFollowingPage renvoie une page des comptes suivis par target.
+func SendFollowingPage(db *sql.DB, viewerId, target string, opts FollowListOptions) (FollowListPage, error) {
+	return sendFollowList(db, viewerId, target, followListFollowing, opts)
+}
+
+// SendMutualsPage renvoie une page des comptes suivis par le viewer qui suivent aussi target.
+func SendMutualsPage(db *sql.DB, viewerId, target string, opts FollowListOptions) (FollowListPage, error) {
+	return sendFollowList(db, viewerId, target, followListMutuals, opts)
+}
+
+// canSeeFollowLists applique la visibilité du profil : les listes d'un compte privé
+// ne sont visibles que par lui-même et ses abonnés acceptés.
+func canSeeFollowLists(db *sql.DB, viewerId, target string) error {
+	if viewerId == target {
+		return nil
+	}
+
+	var public int
+	err := db.QueryRow(`SELECT PUBLIC FROM USER WHERE ID = ?`, target).Scan(&public)
+	if err == sql.ErrNoRows {
+		return errors.New("user not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = checkNotBlocked(db, viewerId, target); err != nil {
+		return err
+	}
+	if public == 1 {
+		return nil
+	}
+
+	var isFollower bool
+	query := `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
+	if err = db.QueryRow(query, target, viewerId).Scan(&isFollower); err != nil {
+		return err
+	}
+	if !isFollower {
+		return ErrPrivateFollowList
+	}
+	return nil
+}
+
+// sendFollowList pagine par curseur (date de l'abonnement puis ID, du plus récent au plus ancien).
+// Les utilisateurs bloqués avec le viewer sont exclus.
+func sendFollowList(db *sql.DB, viewerId, target, kind string, opts FollowListOptions) (FollowListPage, error) {
+	var page FollowListPage
+
+	if opts.Limit < 0 || opts.Limit > MaxFollowListLimit {
+		return page, errors.New("limit must be between 1 and 100")
+	}
+	if err := canSeeFollowLists(db, viewerId, target); err != nil {
+		return page, err
+	}
+
+	cursorDate, cursorId, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return page, err
+	}
+
+	// f : la relation listée ; u : l'utilisateur affiché
+	var from string
+	switch kind {
+	case followListFollowers:
+		from = `FROM FOLLOWERS f JOIN USER u ON u.ID = f.FOLLOWERS WHERE f.USER_ID = ?1`
+	case followListFollowing:
+		from = `FROM FOLLOWERS f JOIN USER u ON u.ID = f.USER_ID WHERE f.FOLLOWERS = ?1`
+	case followListMutuals:
+		from = `FROM FOLLOWERS f JOIN USER u ON u.ID = f.FOLLOWERS
+		        JOIN FOLLOWERS v ON v.USER_ID = u.ID AND v.FOLLOWERS = ?2
+		        WHERE f.USER_ID = ?1`
+	}
+
+	query := `
+		SELECT u.ID, u.FIRSTNAME, u.LASTNAME, IFNULL(u.ABOUT_ME, ''), IFNULL(u.IMAGE, ''), IFNULL(u.USERNAME, ''),
+		       f.CREATED_AT, f.ID,
+		       EXISTS(SELECT 1 FROM FOLLOWERS y WHERE y.USER_ID = u.ID AND y.FOLLOWERS = ?2),
+		       EXISTS(SELECT 1 FROM FOLLOWERS y WHERE y.USER_ID = ?2 AND y.FOLLOWERS = u.ID)
+		` + from + `
+		  AND (?3 = '' OR u.USERNAME LIKE ?3 OR u.FIRSTNAME LIKE ?3 OR u.LASTNAME LIKE ?3)
+		  AND (?4 = '' OR f.CREATED_AT < ?4 OR (f.CREATED_AT = ?4 AND f.ID < ?5))
+		  AND NOT EXISTS(
+			SELECT 1 FROM BLOCKS b
+			WHERE (b.BLOCKER_ID = ?2 AND b.BLOCKED_ID = u.ID) OR (b.BLOCKER_ID = u.ID AND b.BLOCKED_ID = ?2)
+		  )
+		ORDER BY f.CREATED_AT DESC, f.ID DESC
+		LIMIT ?6
+	`
+
+	search := ""
+	if s := strings.TrimSpace(opts.Search); s != "" {
+		search = "%" + s + "%"
+	}
+
+	// LIMIT -1 : pas de limite pour SQLite
+	limit := -1
+	if opts.Limit > 0 {
+		limit = opts.Limit + 1
+	}
+
+	rows, err := db.Query(query, target, viewerId, search, cursorDate, cursorId, limit)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var lastDate, lastId string
+	for rows.Next() {
+		var e ListOfFollowers
+		var createdAt, followId string
+		err = rows.Scan(&e.UserID, &e.FirstName, &e.LastName, &e.AboutMe, &e.Image, &e.Username,
+			&createdAt, &followId, &e.YouFollow, &e.FollowsYou)
+		if err != nil {
+			return page, err
+		}
+
+		if opts.Limit > 0 && len(page.Entries) == opts.Limit {
+			page.NextCursor = encodeCursor(lastDate, lastId)
+			break
+		}
+
+		e.Followed = e.YouFollow
+		page.Entries = append(page.Entries, e)
+		lastDate, lastId = createdAt, followId
+	}
+
+	return page, rows.Err()
+}
+
+// encodeCursor encode la position (date, ID) de la dernière ligne d'une page, pour les listes
+// paginées par date puis ID (abonnés, abonnements, membres d'un groupe).
+func encodeCursor(createdAt, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt + "|" + id))
+}
+
+// decodeCursor relit un curseur d'encodeCursor ; un curseur vide désigne la première page.
+func decodeCursor(cursor string) (string, string, error) {
+	if cursor == "" {
+		return "", "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", errors.New("invalid cursor")
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", errors.New("invalid cursor")
+	}
+	return parts[0], parts[1], nil
+}
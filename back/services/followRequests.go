@@ -0,0 +1,276 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"log"
+	"time"
+)
+
+// Les demandes restées en attente plus longtemps sont supprimées
+const FollowRequestExpiryDays = 30
+
+var ErrFollowRequestNotFound = errors.New("no pending follow request from this user")
+
+const (
+	FollowRequestsIn  = "in"
+	FollowRequestsOut = "out"
+)
+
+type FollowRequest struct {
+	Id        string `json:"id"`
+	User      User   `json:"user"` // demandeur (in) ou destinataire (out)
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// SendFollowRequests renvoie les demandes en attente reçues (in) ou envoyées (out), les plus récentes d'abord.
+func SendFollowRequests(db *sql.DB, userId, direction string) ([]FollowRequest, error) {
+	var query string
+	switch direction {
+	case FollowRequestsIn:
+		query = `SELECT ID, ASKER_ID, CREATED_AT, datetime(CREATED_AT, ?) FROM REQUEST_FOLLOW
+		         WHERE RECEIVER_ID = ? AND STATUS = 'pending' ORDER BY CREATED_AT DESC`
+	case FollowRequestsOut:
+		query = `SELECT ID, RECEIVER_ID, CREATED_AT, datetime(CREATED_AT, ?) FROM REQUEST_FOLLOW
+		         WHERE ASKER_ID = ? AND STATUS = 'pending' ORDER BY CREATED_AT DESC`
+	default:
+		return nil, errors.New("direction must be in or out")
+	}
+
+	rows, err := db.Query(query, fmt.Sprintf("+%d days", FollowRequestExpiryDays), userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []FollowRequest
+	for rows.Next() {
+		var r FollowRequest
+		var otherId string
+		if err = rows.Scan(&r.Id, &otherId, &r.CreatedAt, &r.ExpiresAt); err != nil {
+			return nil, err
+		}
+		r.User, err = getUserByID(db, otherId)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, r)
+	}
+
+	return requests, rows.Err()
+}
+
+// Résultat d'une demande dans une acceptation ou un refus groupé
+const (
+	FollowRequestAccepted  = "accepted"
+	FollowRequestDeclined  = "declined"
+	FollowRequestNotFound  = "not_found" // aucune demande en attente de cet utilisateur
+	FollowRequestDuplicate = "duplicate" // utilisateur déjà présent plus haut dans la liste
+)
+
+type FollowRequestResult struct {
+	UserId string `json:"user_id"`
+	Status string `json:"status"`
+}
+
+// AcceptFollowRequests accepte en une transaction les demandes en attente des utilisateurs donnés.
+// Chaque demandeur reçoit une notification FOLLOW_ACCEPTED. Un identifiant inconnu ou répété
+// n'annule pas les autres : le résultat est renvoyé pour chaque identifiant.
+func AcceptFollowRequests(db *sql.DB, userId string, askers []string) ([]FollowRequestResult, error) {
+	return answerFollowRequests(db, userId, askers, true)
+}
+
+// DeclineFollowRequests refuse en une transaction les demandes en attente des utilisateurs donnés.
+func DeclineFollowRequests(db *sql.DB, userId string, askers []string) ([]FollowRequestResult, error) {
+	return answerFollowRequests(db, userId, askers, false)
+}
+
+func answerFollowRequests(db *sql.DB, userId string, askers []string, accept bool) ([]FollowRequestResult, error) {
+	if len(askers) == 0 {
+		return nil, errors.New("no users given")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	results := make([]FollowRequestResult, 0, len(askers))
+	seen := make(map[string]bool)
+	for _, asker := range askers {
+		result := FollowRequestResult{UserId: asker}
+		if seen[asker] {
+			result.Status = FollowRequestDuplicate
+			results = append(results, result)
+			continue
+		}
+		seen[asker] = true
+
+		requestId, err := pendingFollowRequestId(tx, userId, asker)
+		if err == ErrFollowRequestNotFound {
+			result.Status = FollowRequestNotFound
+			results = append(results, result)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if accept {
+			err = acceptFollowRequest(tx, requestId, userId, asker)
+			result.Status = FollowRequestAccepted
+		} else {
+			err = deleteFollowRequest(tx, requestId)
+			result.Status = FollowRequestDeclined
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "transaction commit failed")
+	}
+
+	return results, nil
+}
+
+// acceptPendingFollowRequests accepte dans la transaction toutes les demandes en attente reçues
+// par l'utilisateur. Utilisé quand un compte privé devient public.
+func acceptPendingFollowRequests(tx *sql.Tx, userId string) (int, error) {
+	rows, err := tx.Query(`SELECT ID, ASKER_ID FROM REQUEST_FOLLOW WHERE RECEIVER_ID = ? AND STATUS = 'pending'`, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	type pending struct{ id, asker string }
+	var requests []pending
+	for rows.Next() {
+		var p pending
+		if err = rows.Scan(&p.id, &p.asker); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		requests = append(requests, p)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, p := range requests {
+		if err = acceptFollowRequest(tx, p.id, userId, p.asker); err != nil {
+			return 0, err
+		}
+	}
+	return len(requests), nil
+}
+
+func pendingFollowRequestId(tx *sql.Tx, receiverId, askerId string) (string, error) {
+	var requestId string
+	query := `SELECT ID FROM REQUEST_FOLLOW WHERE RECEIVER_ID = ? AND ASKER_ID = ? AND STATUS = 'pending'`
+	err := tx.QueryRow(query, receiverId, askerId).Scan(&requestId)
+	if err == sql.ErrNoRows {
+		return "", ErrFollowRequestNotFound
+	}
+	return requestId, err
+}
+
+func acceptFollowRequest(tx *sql.Tx, requestId, receiverId, askerId string) error {
+	var isFollowing bool
+	query := `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
+	err := tx.QueryRow(query, receiverId, askerId).Scan(&isFollowing)
+	if err != nil {
+		return err
+	}
+
+	if !isFollowing {
+		query = `INSERT INTO FOLLOWERS (ID, USER_ID, FOLLOWERS, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+		_, err = tx.Exec(query, uuid.New().String(), receiverId, askerId)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert follower")
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE REQUEST_FOLLOW SET STATUS = 'accepted' WHERE ID = ?`, requestId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update follow request")
+	}
+
+	query = `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE, READ, CREATED_AT) VALUES (?, 'FOLLOW_ACCEPTED', ?, ?, 0, datetime('now'))`
+	_, err = tx.Exec(query, uuid.New().String(), askerId, requestId)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert notification")
+	}
+
+	return nil
+}
+
+// deleteFollowRequest supprime une demande et la notification ASK_FOLLOW associée.
+func deleteFollowRequest(tx *sql.Tx, requestId string) error {
+	_, err := tx.Exec(`DELETE FROM NOTIFICATIONS WHERE TYPE = 'ASK_FOLLOW' AND ID_TYPE = ?`, requestId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete follow request notification")
+	}
+	_, err = tx.Exec(`DELETE FROM REQUEST_FOLLOW WHERE ID = ?`, requestId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete follow request")
+	}
+	return nil
+}
+
+// StartFollowRequestExpiry lance en tâche de fond la suppression périodique des demandes expirées.
+func StartFollowRequestExpiry(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			count, err := ExpireFollowRequests(db)
+			if err != nil {
+				log.Println("Erreur lors de l'expiration des demandes d'abonnement :", err)
+			} else if count > 0 {
+				log.Printf("%d demandes d'abonnement expirées supprimées", count)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ExpireFollowRequests supprime les demandes en attente depuis plus de FollowRequestExpiryDays jours.
+func ExpireFollowRequests(db *sql.DB) (int, error) {
+	modifier := fmt.Sprintf("-%d days", FollowRequestExpiryDays)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM NOTIFICATIONS WHERE TYPE = 'ASK_FOLLOW' AND ID_TYPE IN (
+	             SELECT ID FROM REQUEST_FOLLOW WHERE STATUS = 'pending' AND CREATED_AT <= datetime('now', ?))`
+	_, err = tx.Exec(query, modifier)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete follow request notifications")
+	}
+
+	res, err := tx.Exec(`DELETE FROM REQUEST_FOLLOW WHERE STATUS = 'pending' AND CREATED_AT <= datetime('now', ?)`, modifier)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete follow requests")
+	}
+	count, _ := res.RowsAffected()
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "transaction commit failed")
+	}
+
+	return int(count), nil
+}
@@ -9,6 +9,7 @@ type ListConversation struct {
 	ConvID      string      `json:"conv"` //x
 	User        []User      `json:"user"` //x
 	LastMessage LastMessage `json:"last_Message"`
+	Muted       bool        `json:"muted"`
 }
 
 type LastMessage struct {
@@ -120,6 +121,11 @@ func GetConversation(db *sql.DB, userId string) ([]ListConversation, error) {
 			lastMsg.Type = "image"
 		}
 
+		c.Muted, err = isMuted(db, userId, MuteConversation, c.ConvID)
+		if err != nil {
+			return l, errors.New("Failed to get mute status")
+		}
+
 		c.User = members
 		l = append(l, c) // <<< Important ! Ajouter la conversation à la liste
 	}
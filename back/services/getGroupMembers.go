@@ -2,62 +2,116 @@ package services
 
 import (
 	"database/sql"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const (
+	DefaultGroupMemberLimit = 50
+	MaxGroupMemberLimit     = 100
 )
 
 type InfoGroupMembers struct {
-	Users       User `json:"users"`
-	IsFollowing bool `json:"is_following"`
+	Users       User   `json:"users"`
+	IsFollowing bool   `json:"is_following"`
+	Role        string `json:"role"`
+	JoinedAt    string `json:"joined_at"`
+	Trusted     bool   `json:"trusted"`               // publie sans passer par la validation
+	InviteLink  string `json:"invite_link,omitempty"` // code du lien d'adhésion, visible des gestionnaires
 }
 
-func GetGroupMember(db *sql.DB, userId, groupId string) ([]InfoGroupMembers, error) {
-	var infoUsers []InfoGroupMembers
+type GroupMemberListOptions struct {
+	Search string
+	Role   string // vide : tous les rôles
+	Cursor string
+	Limit  int
+}
+
+type GroupMemberPage struct {
+	Members    []InfoGroupMembers `json:"members"`
+	Total      int                `json:"total"` // membres correspondant à la recherche, hors viewer
+	NextCursor string             `json:"next_cursor"`
+}
 
-	query := `SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ?`
-	rows, err := db.Query(query, groupId)
+// GetGroupMember renvoie une page des membres du groupe, hors viewer, du plus ancien au plus récent
+// (pagination par curseur sur la date d'adhésion puis l'ID), filtrée par nom et par rôle.
+func GetGroupMember(db *sql.DB, userId, groupId string, opts GroupMemberListOptions) (GroupMemberPage, error) {
+	page := GroupMemberPage{Members: []InfoGroupMembers{}}
+
+	if opts.Limit <= 0 || opts.Limit > MaxGroupMemberLimit {
+		return page, errors.Errorf("limit must be between 1 and %d", MaxGroupMemberLimit)
+	}
+	if opts.Role != "" {
+		if _, ok := roleRank[opts.Role]; !ok {
+			return page, errors.New("invalid role (owner, admin, moderator or member)")
+		}
+	}
+
+	if err := canSeeGroup(db, userId, groupId); err != nil {
+		return page, err
+	}
+
+	viewerRole, err := groupRole(db, userId, groupId)
 	if err != nil {
-		return nil, err
+		return page, err
 	}
-	defer rows.Close()
+	showInviteLinks := hasGroupPermission(viewerRole, PermManageRequests)
 
-	for rows.Next() {
-		var memberID string
-		if err := rows.Scan(&memberID); err != nil {
-			return nil, err
-		}
+	cursorDate, cursorId, err := decodeCursor(opts.Cursor)
+	if err != nil {
+		return page, err
+	}
 
-		if memberID == userId {
-			continue
-		}
+	search := ""
+	if s := strings.TrimSpace(opts.Search); s != "" {
+		search = "%" + s + "%"
+	}
 
-		var i InfoGroupMembers
-		i.Users.ID = memberID
+	filter := `FROM GROUPS_MEMBERS m JOIN USER u ON u.ID = m.USER_ID
+	           LEFT JOIN GROUP_INVITE_LINKS l ON l.ID = m.INVITE_LINK_ID
+	           WHERE m.GROUP_ID = ?1 AND m.USER_ID <> ?2
+	             AND (?3 = '' OR u.USERNAME LIKE ?3 OR u.FIRSTNAME LIKE ?3 OR u.LASTNAME LIKE ?3)
+	             AND (?4 = '' OR m.ROLE = ?4)`
 
-		var username, imgUser sql.NullString
-		queryUser := `SELECT LASTNAME, FIRSTNAME, USERNAME, IMAGE FROM USER WHERE ID = ?`
-		err = db.QueryRow(queryUser, memberID).Scan(&i.Users.Lastname, &i.Users.Firstname, &username, &imgUser)
-		if err != nil {
-			return nil, err
-		}
+	err = db.QueryRow(`SELECT COUNT(*) `+filter, groupId, userId, search, opts.Role).Scan(&page.Total)
+	if err != nil {
+		return page, err
+	}
 
-		if username.Valid {
-			i.Users.Username = username.String
-		}
-		if imgUser.Valid {
-			i.Users.ProfilePic = imgUser.String
-		}
+	query := `SELECT m.USER_ID, m.ROLE, m.TRUSTED, m.CREATED_AT, m.ID, IFNULL(l.CODE, ''),
+	                 u.LASTNAME, u.FIRSTNAME, IFNULL(u.USERNAME, ''), IFNULL(u.IMAGE, ''),
+	                 EXISTS(SELECT 1 FROM FOLLOWERS f WHERE f.USER_ID = m.USER_ID AND f.FOLLOWERS = ?2)
+	          ` + filter + `
+	            AND (?5 = '' OR m.CREATED_AT > ?5 OR (m.CREATED_AT = ?5 AND m.ID > ?6))
+	          ORDER BY m.CREATED_AT ASC, m.ID ASC
+	          LIMIT ?7`
+	rows, err := db.Query(query, groupId, userId, search, opts.Role, cursorDate, cursorId, opts.Limit+1)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
 
-		queryFollow := `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
-		err = db.QueryRow(queryFollow, memberID, userId).Scan(&i.IsFollowing)
+	var lastDate, lastId string
+	for rows.Next() {
+		var i InfoGroupMembers
+		var memberId, inviteLink string
+		err = rows.Scan(&i.Users.ID, &i.Role, &i.Trusted, &i.JoinedAt, &memberId, &inviteLink,
+			&i.Users.Lastname, &i.Users.Firstname, &i.Users.Username, &i.Users.ProfilePic, &i.IsFollowing)
 		if err != nil {
-			return nil, err
+			return page, err
 		}
 
-		infoUsers = append(infoUsers, i)
-	}
+		if len(page.Members) == opts.Limit {
+			page.NextCursor = encodeCursor(lastDate, lastId)
+			break
+		}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+		if showInviteLinks {
+			i.InviteLink = inviteLink
+		}
+		page.Members = append(page.Members, i)
+		lastDate, lastId = i.JoinedAt, memberId
 	}
 
-	return infoUsers, nil
+	return page, rows.Err()
 }
@@ -7,20 +7,33 @@ import (
 
 func GetGroupPosts(db *sql.DB, userId, groupId string) ([]PostProfile, error) {
 	var posts []PostProfile
-	var isMember bool
 
-	// Vérifie que l'utilisateur est bien membre du groupe
-	queryMember := `SELECT EXISTS (SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-	err := db.QueryRow(queryMember, userId, groupId).Scan(&isMember)
+	// Membre du groupe, ou groupe public lisible par tous
+	canRead, err := canReadGroupContent(db, userId, groupId)
 	if err != nil {
 		return posts, err
 	}
-	if !isMember {
+	if !canRead {
 		return posts, errors.New("user is not member of group")
 	}
 
-	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE FROM POSTS WHERE GROUP_ID = ? ORDER BY CREATED_AT DESC`
-	rows, err := db.Query(query, groupId)
+	// Les posts en attente ou refusés ne sont montrés qu'à leur auteur (voir ListPendingGroupPosts)
+	return groupPosts(db, userId, groupId, `(STATUS = 'published' OR USER_ID = ?) ORDER BY CREATED_AT DESC`, userId)
+}
+
+// groupPosts renvoie les posts du groupe qui vérifient la condition SQL where (suivie de son tri).
+func groupPosts(db *sql.DB, userId, groupId, where string, args ...any) ([]PostProfile, error) {
+	var posts []PostProfile
+
+	blocked, err := blockedUserIds(db, userId)
+	if err != nil {
+		return posts, err
+	}
+
+	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE, COMMENT_POLICY, COMMENTS_LOCKED, IFNULL(CONTENT_WARNING, ''), SENSITIVE,
+	                 STATUS, IFNULL(REJECT_REASON, '')
+	          FROM POSTS WHERE GROUP_ID = ? AND ` + where
+	rows, err := db.Query(query, append([]any{groupId}, args...)...)
 	if err != nil {
 		return posts, err
 	}
@@ -36,8 +49,14 @@ func GetGroupPosts(db *sql.DB, userId, groupId string) ([]PostProfile, error) {
 			&p.UserId,
 			&p.CreatedAt,
 			&imageContent,
+			&p.CommentPolicy,
+			&p.CommentsLocked,
+			&p.ContentWarning,
+			&p.Sensitive,
+			&p.Status,
+			&p.RejectReason,
 		)
-		if err != nil {
+		if err != nil || blocked[p.UserId] {
 			continue
 		}
 
@@ -98,12 +117,15 @@ func GetGroupPosts(db *sql.DB, userId, groupId string) ([]PostProfile, error) {
 
 		_ = db.QueryRow(`SELECT COUNT(*) FROM POST_EVENT WHERE POST_ID = ? AND LIKED = 'liked'`, p.Id).Scan(&p.LikeCount)
 		_ = db.QueryRow(`SELECT COUNT(*) FROM POST_EVENT WHERE POST_ID = ? AND LIKED = 'disliked'`, p.Id).Scan(&p.DislikeCount)
-		_ = db.QueryRow(`SELECT COUNT(*) FROM COMMENT WHERE POST_ID = ?`, p.Id).Scan(&p.CommentCount)
+		_ = db.QueryRow(visibleCommentCount, p.Id, userId).Scan(&p.CommentCount)
 
 		p.OwnerUserId = userId == p.UserId
 		_ = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS =?)`, p.UserId, userId).Scan(&p.Followed)
 		p.Privacy = "group"
 
+		if p.Status == PostStatusPublished {
+			RecordPostView(userId, p.Id, p.UserId)
+		}
 		posts = append(posts, p)
 	}
 
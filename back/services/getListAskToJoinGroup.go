@@ -2,24 +2,20 @@ package services
 
 import (
 	"database/sql"
-	"github.com/pkg/errors"
 )
 
-func ListAskToJoinGroup(userID, groupID string, db *sql.DB) ([]User, error) {
-	var u []User
-	var isOwner bool
+// ListAskToJoinGroup renvoie les demandes en attente avec les réponses aux questions du groupe.
+func ListAskToJoinGroup(userID, groupID string, db *sql.DB) ([]GroupApplication, error) {
+	var applications []GroupApplication
 
-	query := `SELECT EXISTS(SELECT 1 FROM ALL_GROUPS WHERE OWNER = ? AND ID = ? )`
-	err := db.QueryRow(query, userID, groupID).Scan(&isOwner)
+	_, err := checkGroupPermission(db, userID, groupID, PermManageRequests)
 	if err != nil {
 		return nil, err
 	}
 
-	if !isOwner {
-		return nil, errors.New("user is not owner of group " + groupID)
-	}
-
-	queryAskGroup := `SELECT ASKER FROM ASK_GROUP WHERE GROUP_ID = ? AND ACCEPTED = 0 `
+	// Les invitations (ASKER = membre qui invite) ne sont pas des demandes
+	queryAskGroup := `SELECT ID, ASKER, CREATED_AT FROM ASK_GROUP WHERE GROUP_ID = ? AND ACCEPTED = 0
+	                  AND ASKER NOT IN (SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1) ORDER BY CREATED_AT ASC`
 	rows, err := db.Query(queryAskGroup, groupID)
 	if err != nil {
 		return nil, err
@@ -27,30 +23,28 @@ func ListAskToJoinGroup(userID, groupID string, db *sql.DB) ([]User, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		var us User
-		var username, img sql.NullString
+		var a GroupApplication
+		var askerID string
 
-		err = rows.Scan(&us.ID)
-		if err != nil {
+		if err = rows.Scan(&a.AskId, &askerID, &a.CreatedAt); err != nil {
 			return nil, err
 		}
 
-		queryUser := `SELECT LASTNAME, FIRSTNAME, USERNAME, IMAGE FROM USER WHERE ID = ?`
-		err = db.QueryRow(queryUser, us.ID).Scan(&us.Lastname, &us.Firstname, &username, &img)
+		a.User, err = getUserByID(db, askerID)
+		if err == sql.ErrNoRows {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
 
-		if img.Valid {
-			us.ProfilePic = img.String
-		}
-
-		if username.Valid {
-			us.Username = username.String
+		a.Answers, err = sendGroupAnswers(db, a.AskId)
+		if err != nil {
+			return nil, err
 		}
 
-		u = append(u, us)
+		applications = append(applications, a)
 	}
 
-	return u, nil
+	return applications, rows.Err()
 }
@@ -18,6 +18,8 @@ type Messages struct {
 	Seen      bool   `json:"seen"`
 	IsImage   bool   `json:"isImage"`
 	CreatedAt string `json:"createdAt"`
+	Warning   string `json:"contentWarning"`
+	Sensitive bool   `json:"sensitive"`
 }
 
 type Members struct {
@@ -69,7 +71,7 @@ func GetMessages(db *sql.DB, userID, convID string) (AllMessages, error) {
 		m.Members = append(m.Members, u)
 	}
 
-	query := `SELECT ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CREATED_AT FROM MESSAGES WHERE CONVERSATION_ID = ?`
+	query := `SELECT ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CREATED_AT, IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM MESSAGES WHERE CONVERSATION_ID = ?`
 	rows, err := db.Query(query, convID)
 	if err != nil {
 		return m, err
@@ -79,7 +81,7 @@ func GetMessages(db *sql.DB, userID, convID string) (AllMessages, error) {
 	for rows.Next() {
 		var mes Messages
 		var typeMessage int
-		err = rows.Scan(&mes.ID, &mes.Sender, &mes.ConvID, &mes.Content, &mes.Seen, &typeMessage, &mes.CreatedAt)
+		err = rows.Scan(&mes.ID, &mes.Sender, &mes.ConvID, &mes.Content, &mes.Seen, &typeMessage, &mes.CreatedAt, &mes.Warning, &mes.Sensitive)
 		if err != nil {
 			return m, err
 		}
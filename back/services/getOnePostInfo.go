@@ -7,25 +7,31 @@ import (
 )
 
 type OnePostInfo struct {
-	Id           string        `json:"id"`                //x
-	UserId       string        `json:"userId"`            //x
-	FirstName    string        `json:"first_name"`        //x
-	LastName     string        `json:"last_name"`         //x
-	Username     string        `json:"username"`          // null x
-	ImageProfile string        `json:"image_profile_url"` // null x
-	Content      string        `json:"content"`           // x
-	Tags         []string      `json:"tags"`              // null x
-	ImageContent string        `json:"image_content_url"` // null x
-	CreatedAt    string        `json:"created_at"`        // x
-	Liked        bool          `json:"liked"`             //x
-	Disliked     bool          `json:"disliked"`          //x
-	LikeCount    int           `json:"like_count"`        //x
-	DislikeCount int           `json:"dislike_count"`     //x
-	CommentCount int           `json:"comment_count"`     //x
-	Comment      []CommentInfo `json:"comment"`           //
-	Followed     bool          `json:"followed"`          //x
-	GroupId      GroupIdPost   `json:"group_id"`          // null x
-	OwnerUserId  bool          `json:"owner_user_id"`     // x
+	Id             string         `json:"id"`                       //x
+	UserId         string         `json:"userId"`                   //x
+	FirstName      string         `json:"first_name"`               //x
+	LastName       string         `json:"last_name"`                //x
+	Username       string         `json:"username"`                 // null x
+	ImageProfile   string         `json:"image_profile_url"`        // null x
+	Content        string         `json:"content"`                  // x
+	Tags           []string       `json:"tags"`                     // null x
+	ImageContent   string         `json:"image_content_url"`        // null x
+	CreatedAt      string         `json:"created_at"`               // x
+	Liked          bool           `json:"liked"`                    //x
+	Disliked       bool           `json:"disliked"`                 //x
+	LikeCount      int            `json:"like_count"`               //x
+	DislikeCount   int            `json:"dislike_count"`            //x
+	CommentCount   int            `json:"comment_count"`            //x
+	Comment        []CommentInfo  `json:"comment"`                  //
+	Followed       bool           `json:"followed"`                 //x
+	GroupId        GroupIdPost    `json:"group_id"`                 // null x
+	OwnerUserId    bool           `json:"owner_user_id"`            // x
+	CommentPolicy  string         `json:"comment_policy"`           // x
+	CommentsLocked bool           `json:"comments_locked"`          // x
+	CanComment     bool           `json:"can_comment"`              // x
+	ContentWarning string         `json:"content_warning"`          // null x
+	Sensitive      bool           `json:"sensitive"`                // x
+	AudienceLists  []AudienceList `json:"audience_lists,omitempty"` // uniquement pour l'auteur
 }
 
 type CommentInfo struct {
@@ -45,6 +51,9 @@ type CommentInfo struct {
 	DislikeCount int    `json:"dislike_count"`     // x
 	CreatedAt    string `json:"created_at"`        // x
 	UpdatedAt    string `json:"updated_at"`        // null x
+	Hidden       bool   `json:"hidden"`            // x
+	Warning      string `json:"content_warning"`   // null x
+	Sensitive    bool   `json:"sensitive"`         // x
 }
 type GroupIdPost struct {
 	Id          string `json:"id"`            //x
@@ -61,16 +70,32 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 	var image, username, groupID sql.NullString
 	var accessGroup bool
 
-	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE, GROUP_ID, PRIVACY FROM POSTS WHERE ID = ? LIMIT 1`
-	err := db.QueryRow(query, postId).Scan(&p.Id, &p.Content, &p.UserId, &p.CreatedAt, &image, &groupID, &private)
+	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE, GROUP_ID, PRIVACY, COMMENT_POLICY, COMMENTS_LOCKED, IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM POSTS WHERE ID = ? LIMIT 1`
+	err := db.QueryRow(query, postId).Scan(&p.Id, &p.Content, &p.UserId, &p.CreatedAt, &image, &groupID, &private, &p.CommentPolicy, &p.CommentsLocked, &p.ContentWarning, &p.Sensitive)
 	if err != nil {
 		return p, err
 	}
 
+	blocked, err := blockedUserIds(db, userID)
+	if err != nil {
+		return p, err
+	}
+	if blocked[p.UserId] {
+		return p, ErrBlocked
+	}
+
+	// Post de groupe en attente de validation ou refusé
+	visible, err := canSeePost(db, userID, postId)
+	if err != nil {
+		return p, err
+	}
+	if !visible {
+		return p, ErrPostNotFound
+	}
+
 	if groupID.Valid {
 		p.GroupId.Id = groupID.String
-		query = `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-		err = db.QueryRow(query, userID, p.GroupId.Id).Scan(&accessGroup)
+		accessGroup, err = canReadGroupContent(db, userID, p.GroupId.Id)
 		if err != nil || (!accessGroup && userID != p.UserId) {
 			log.Printf("Post %s ignoré (accès privé refusé pour l'utilisateur %s)", p.Id, userID)
 			return p, err
@@ -101,13 +126,7 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 		}
 	} else if private == 0 && p.UserId != userID {
 		var hasAccess bool
-		query = `
-		SELECT EXISTS(
-			SELECT 1 FROM LIST_PRIVATE_POST 
-			WHERE POST_ID = ? AND USER_ID = ?
-		)
-	`
-		err = db.QueryRow(query, postId, userID).Scan(&hasAccess)
+		hasAccess, err = hasPrivatePostAccess(db, userID, postId)
 		if err != nil {
 			return p, err
 		}
@@ -122,6 +141,15 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 		p.OwnerUserId = false
 	}
 
+	p.CanComment = canCommentOnPost(db, userID, postId) == nil
+
+	if p.OwnerUserId && private == PrivacyPrivate {
+		p.AudienceLists, err = sendPostAudienceLists(db, postId)
+		if err != nil {
+			return p, err
+		}
+	}
+
 	var followed bool
 	query = `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
 	err = db.QueryRow(query, p.UserId, userID).Scan(&followed)
@@ -148,7 +176,7 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 		return p, err
 	}
 
-	err = db.QueryRow(`SELECT COUNT(*) FROM COMMENT WHERE POST_ID = ?`, postId).Scan(&p.CommentCount)
+	err = db.QueryRow(visibleCommentCount, postId, userID).Scan(&p.CommentCount)
 	if err != nil {
 		return p, err
 	}
@@ -177,7 +205,7 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 		p.ImageProfile = image.String
 	}
 
-	query = `SELECT * FROM COMMENT WHERE POST_ID = ?`
+	query = `SELECT ID, POST_ID, USER_ID, CONTENT, IMAGE, CREATED, UPDATED_AT, HIDDEN, IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM COMMENT WHERE POST_ID = ?`
 	rows, err := db.Query(query, p.Id)
 	if err != nil {
 		return p, err
@@ -205,12 +233,21 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 
 		var imageComment, updateAt, imageProfile, usernameC sql.NullString
 
-		err = rows.Scan(&c.Id, &c.PostId, &c.UserId, &c.Content, &imageComment, &c.CreatedAt, &updateAt)
+		err = rows.Scan(&c.Id, &c.PostId, &c.UserId, &c.Content, &imageComment, &c.CreatedAt, &updateAt, &c.Hidden, &c.Warning, &c.Sensitive)
 		if err != nil {
 			log.Println(err)
 			continue
 		}
 
+		if blocked[c.UserId] {
+			continue
+		}
+
+		// Un commentaire masqué reste visible pour l'auteur du post et pour son auteur
+		if c.Hidden && !p.OwnerUserId && c.UserId != userID {
+			continue
+		}
+
 		query = `SELECT FIRSTNAME, LASTNAME, IMAGE, USERNAME FROM USER WHERE ID = ?`
 		err = db.QueryRow(query, c.UserId).Scan(&c.FirstName, &c.LastName, &imageProfile, &usernameC)
 		if err != nil {
@@ -269,5 +306,7 @@ func GetOnePostInfo(db *sql.DB, userID, postId string) (OnePostInfo, error) {
 
 	p.Comment = comments
 
+	RecordPostView(userID, p.Id, p.UserId)
+
 	return p, nil
 }
@@ -68,7 +68,7 @@ func GetUserInfoFromTarget(db *sql.DB, targetId, userID string) (UserInfoRespons
 	}
 
 	var isOnWaiting bool
-	query = `SELECT EXISTS(SELECT 1 FROM REQUEST_FOLLOW WHERE ASKER_ID = ? AND RECEIVER_ID = ? )`
+	query = `SELECT EXISTS(SELECT 1 FROM REQUEST_FOLLOW WHERE ASKER_ID = ? AND RECEIVER_ID = ? AND STATUS = 'pending')`
 	err = db.QueryRow(query, userID, targetId).Scan(&isOnWaiting)
 	if err != nil {
 		return userInfo, err
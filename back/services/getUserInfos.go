@@ -18,6 +18,7 @@ type UserInfoResponse struct {
 	Following     int    `json:"following"`
 	CreatedAt     string `json:"created_at"`
 	UnreadMessage int    `json:"unread_message"`
+	Sensitive     string `json:"sensitive_content"` // collapse | expand
 }
 
 func GetUserInfos(db *sql.DB, userId string) (UserInfoResponse, error) {
@@ -41,9 +42,9 @@ func GetUserInfos(db *sql.DB, userId string) (UserInfoResponse, error) {
 	}
 
 	// Infos utilisateur
-	query3 := `SELECT ID, EMAIL, FIRSTNAME, LASTNAME, ABOUT_ME, USERNAME, IMAGE, PUBLIC, DATE_OF_BIRTH, CREATED_AT FROM USER WHERE ID = ? LIMIT 1`
+	query3 := `SELECT ID, EMAIL, FIRSTNAME, LASTNAME, ABOUT_ME, USERNAME, IMAGE, PUBLIC, DATE_OF_BIRTH, CREATED_AT, SENSITIVE_CONTENT FROM USER WHERE ID = ? LIMIT 1`
 	row := db.QueryRow(query3, userId)
-	err = row.Scan(&userInfo.Id, &userInfo.Email, &userInfo.FirstName, &userInfo.LastName, &about, &username, &image, &public, &userInfo.DateOfBirth, &userInfo.CreatedAt)
+	err = row.Scan(&userInfo.Id, &userInfo.Email, &userInfo.FirstName, &userInfo.LastName, &about, &username, &image, &public, &userInfo.DateOfBirth, &userInfo.CreatedAt, &userInfo.Sensitive)
 	if err != nil {
 		return userInfo, err
 	}
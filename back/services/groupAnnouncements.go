@@ -0,0 +1,180 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const maxAnnouncementLength = 2000
+
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+type GroupAnnouncement struct {
+	ID        string `json:"id"`
+	GroupID   string `json:"group_id"`
+	Author    User   `json:"author"`
+	Content   string `json:"content"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GroupAnnouncementNotification struct {
+	AnnouncementID string `json:"announcement_id"`
+	GroupID        string `json:"group_id"`
+	GroupName      string `json:"group_name"`
+	GroupPic       string `json:"group_pic"`
+	Author         User   `json:"author"`
+	Content        string `json:"content"`
+}
+
+// CreateGroupAnnouncement publie une annonce. Tous les membres sont notifiés, y compris
+// ceux qui ont mis le groupe en sourdine, contrairement aux posts du groupe.
+func CreateGroupAnnouncement(db *sql.DB, userId, groupId, content string) (GroupAnnouncement, error) {
+	var a GroupAnnouncement
+
+	if _, err := checkGroupPermission(db, userId, groupId, PermAnnounce); err != nil {
+		return a, err
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return a, errors.New("missing content")
+	}
+	if len([]rune(content)) > maxAnnouncementLength {
+		return a, errors.Errorf("announcement too long (max %d characters)", maxAnnouncementLength)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return a, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	id := uuid.New().String()
+	query := `INSERT INTO GROUP_ANNOUNCEMENTS(ID, GROUP_ID, USER_ID, CONTENT, CREATED_AT) VALUES (?, ?, ?, ?, datetime('now'))`
+	if _, err = tx.Exec(query, id, groupId, userId, content); err != nil {
+		return a, errors.Wrap(err, "failed to insert announcement")
+	}
+
+	if err = notifyAllGroupMembers(tx, groupId, userId, "GROUP_ANNOUNCEMENT", id); err != nil {
+		return a, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return a, errors.Wrap(err, "transaction commit failed")
+	}
+
+	return groupAnnouncement(db, id)
+}
+
+// ListGroupAnnouncements renvoie les annonces du groupe, de la plus récente à la plus ancienne.
+func ListGroupAnnouncements(db *sql.DB, userId, groupId string) ([]GroupAnnouncement, error) {
+	canRead, err := canReadGroupContent(db, userId, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if !canRead {
+		return nil, errors.New("user is not member of group")
+	}
+
+	rows, err := db.Query(`SELECT ID FROM GROUP_ANNOUNCEMENTS WHERE GROUP_ID = ? ORDER BY CREATED_AT DESC`, groupId)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	announcements := []GroupAnnouncement{}
+	for _, id := range ids {
+		a, err := groupAnnouncement(db, id)
+		if err != nil {
+			return nil, err
+		}
+		announcements = append(announcements, a)
+	}
+
+	return announcements, nil
+}
+
+// DeleteGroupAnnouncement supprime une annonce et les notifications qui y renvoient.
+func DeleteGroupAnnouncement(db *sql.DB, userId, announcementId string) error {
+	var groupId string
+	err := db.QueryRow(`SELECT GROUP_ID FROM GROUP_ANNOUNCEMENTS WHERE ID = ?`, announcementId).Scan(&groupId)
+	if err == sql.ErrNoRows {
+		return ErrAnnouncementNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = checkGroupPermission(db, userId, groupId, PermAnnounce); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(`DELETE FROM NOTIFICATIONS WHERE TYPE = 'GROUP_ANNOUNCEMENT' AND ID_TYPE = ?`, announcementId); err != nil {
+		return errors.Wrap(err, "failed to delete notifications")
+	}
+	if _, err = tx.Exec(`DELETE FROM GROUP_ANNOUNCEMENTS WHERE ID = ?`, announcementId); err != nil {
+		return errors.Wrap(err, "failed to delete announcement")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+	return nil
+}
+
+func groupAnnouncement(db *sql.DB, announcementId string) (GroupAnnouncement, error) {
+	var a GroupAnnouncement
+	var authorId string
+	query := `SELECT ID, GROUP_ID, USER_ID, CONTENT, CREATED_AT FROM GROUP_ANNOUNCEMENTS WHERE ID = ?`
+	err := db.QueryRow(query, announcementId).Scan(&a.ID, &a.GroupID, &authorId, &a.Content, &a.CreatedAt)
+	if err != nil {
+		return a, err
+	}
+
+	a.Author, err = getUserByID(db, authorId)
+	return a, err
+}
+
+func groupAnnouncementNotification(db *sql.DB, announcementId string) (GroupAnnouncementNotification, error) {
+	var n GroupAnnouncementNotification
+
+	a, err := groupAnnouncement(db, announcementId)
+	if err != nil {
+		return n, err
+	}
+
+	var groupPic sql.NullString
+	err = db.QueryRow(`SELECT TITLE, IMAGE FROM ALL_GROUPS WHERE ID = ?`, a.GroupID).Scan(&n.GroupName, &groupPic)
+	if err != nil {
+		return n, err
+	}
+	if groupPic.Valid {
+		n.GroupPic = groupPic.String
+	}
+
+	n.AnnouncementID = a.ID
+	n.GroupID = a.GroupID
+	n.Author = a.Author
+	n.Content = a.Content
+	return n, nil
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxGroupQuestions      = 5
+	maxGroupQuestionLength = 300
+	maxGroupAnswerLength   = 1000
+)
+
+// Durées proposées pour interdire une nouvelle demande après un refus
+var reapplyBlocks = map[string]time.Duration{
+	"1w": 7 * 24 * time.Hour,
+	"1m": 30 * 24 * time.Hour,
+	"3m": 90 * 24 * time.Hour,
+	"1y": 365 * 24 * time.Hour,
+}
+
+type GroupQuestion struct {
+	Position int    `json:"position"`
+	Question string `json:"question"`
+}
+
+type GroupAnswer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// GroupApplication : les champs de l'utilisateur restent à la racine pour le front existant.
+type GroupApplication struct {
+	User
+	AskId     string        `json:"ask_id"`
+	CreatedAt string        `json:"created_at"`
+	Answers   []GroupAnswer `json:"answers"`
+}
+
+type GroupDecisionData struct {
+	GroupID      string `json:"group_id"`
+	GroupName    string `json:"group_name"`
+	GroupPic     string `json:"group_pic"`
+	Accepted     bool   `json:"accepted"`
+	Reason       string `json:"reason"`
+	BlockedUntil string `json:"blocked_until"` // vide : nouvelle demande possible
+	CreatedAt    string `json:"created_at"`
+}
+
+// SendGroupQuestions renvoie les questions posées aux candidats, visibles de tous ceux qui voient le groupe.
+func SendGroupQuestions(db *sql.DB, userId, groupId string) ([]GroupQuestion, error) {
+	if err := canSeeGroup(db, userId, groupId); err != nil {
+		return nil, err
+	}
+	return groupQuestions(db, groupId)
+}
+
+func groupQuestions(db *sql.DB, groupId string) ([]GroupQuestion, error) {
+	var questions []GroupQuestion
+
+	rows, err := db.Query(`SELECT POSITION, QUESTION FROM GROUP_QUESTIONS WHERE GROUP_ID = ? ORDER BY POSITION`, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var q GroupQuestion
+		if err = rows.Scan(&q.Position, &q.Question); err != nil {
+			return nil, err
+		}
+		questions = append(questions, q)
+	}
+
+	return questions, rows.Err()
+}
+
+// SetGroupQuestions remplace les questions du groupe. Une liste vide supprime les questions.
+// Les demandes déjà envoyées gardent leurs réponses.
+func SetGroupQuestions(db *sql.DB, userId, groupId string, questions []string) error {
+	if _, err := checkGroupPermission(db, userId, groupId, PermManageRequests); err != nil {
+		return err
+	}
+
+	var cleaned []string
+	for _, q := range questions {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			continue
+		}
+		if utf8.RuneCountInString(q) > maxGroupQuestionLength {
+			return errors.Errorf("question too long (max %d characters)", maxGroupQuestionLength)
+		}
+		cleaned = append(cleaned, q)
+	}
+	if len(cleaned) > MaxGroupQuestions {
+		return errors.Errorf("too many questions (max %d)", MaxGroupQuestions)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM GROUP_QUESTIONS WHERE GROUP_ID = ?`, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group questions")
+	}
+
+	query := `INSERT INTO GROUP_QUESTIONS(ID, GROUP_ID, POSITION, QUESTION, CREATED_AT) VALUES (?, ?, ?, ?, datetime('now'))`
+	for i, q := range cleaned {
+		_, err = tx.Exec(query, uuid.New().String(), groupId, i+1, q)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert group question")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// checkReapplyBlock refuse une nouvelle demande tant que le dernier refus l'interdit.
+func checkReapplyBlock(db *sql.DB, userId, groupId string) error {
+	var blockedUntil string
+	query := `SELECT BLOCKED_UNTIL FROM GROUP_JOIN_DECISIONS
+	          WHERE GROUP_ID = ? AND USER_ID = ? AND BLOCKED_UNTIL > datetime('now')
+	          ORDER BY BLOCKED_UNTIL DESC LIMIT 1`
+	err := db.QueryRow(query, groupId, userId).Scan(&blockedUntil)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Errorf("you cannot ask to join this group again before %s", blockedUntil)
+}
+
+// saveGroupAnswers vérifie qu'il y a une réponse par question et les enregistre avec la demande.
+func saveGroupAnswers(db *sql.DB, tx *sql.Tx, askId, groupId string, answers []string) error {
+	questions, err := groupQuestions(db, groupId)
+	if err != nil {
+		return err
+	}
+	if len(answers) != len(questions) {
+		return errors.Errorf("expected %d answers, got %d", len(questions), len(answers))
+	}
+
+	query := `INSERT INTO GROUP_ANSWERS(ASK_ID, POSITION, QUESTION, ANSWER) VALUES (?, ?, ?, ?)`
+	for i, q := range questions {
+		answer := strings.TrimSpace(answers[i])
+		if answer == "" {
+			return errors.Errorf("missing answer to question %d", q.Position)
+		}
+		if utf8.RuneCountInString(answer) > maxGroupAnswerLength {
+			return errors.Errorf("answer too long (max %d characters)", maxGroupAnswerLength)
+		}
+		if _, err = tx.Exec(query, askId, q.Position, q.Question, answer); err != nil {
+			return errors.Wrap(err, "failed to insert answer")
+		}
+	}
+
+	return nil
+}
+
+func sendGroupAnswers(db *sql.DB, askId string) ([]GroupAnswer, error) {
+	var answers []GroupAnswer
+
+	rows, err := db.Query(`SELECT QUESTION, ANSWER FROM GROUP_ANSWERS WHERE ASK_ID = ? ORDER BY POSITION`, askId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a GroupAnswer
+		if err = rows.Scan(&a.Question, &a.Answer); err != nil {
+			return nil, err
+		}
+		answers = append(answers, a)
+	}
+
+	return answers, rows.Err()
+}
+
+// DeclineAskerToJoinGroup refuse une demande d'adhésion, avec un motif facultatif.
+// block ("1w", "1m", "3m", "1y") interdit au candidat de redemander pendant cette durée.
+func DeclineAskerToJoinGroup(db *sql.DB, groupId, userId, askerId, reason, block string) error {
+	if _, err := checkGroupPermission(db, userId, groupId, PermManageRequests); err != nil {
+		return err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > maxGroupAnswerLength {
+		return errors.Errorf("reason too long (max %d characters)", maxGroupAnswerLength)
+	}
+
+	var blockedUntil sql.NullString
+	if block != "" {
+		d, ok := reapplyBlocks[block]
+		if !ok {
+			return errors.New("invalid block duration (1w, 1m, 3m or 1y)")
+		}
+		blockedUntil = sql.NullString{String: time.Now().UTC().Add(d).Format("2006-01-02 15:04:05"), Valid: true}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	var askId string
+	query := `SELECT ID FROM ASK_GROUP WHERE GROUP_ID = ? AND ASKER = ? AND ACCEPTED = 0`
+	err = tx.QueryRow(query, groupId, askerId).Scan(&askId)
+	if err == sql.ErrNoRows {
+		return errors.New("this user has not requested to join the group or has already been accepted")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to check ask request")
+	}
+
+	if err = deleteGroupAsk(tx, askId); err != nil {
+		return err
+	}
+	if err = addJoinDecision(tx, groupId, askerId, userId, false, reason, blockedUntil); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// deleteGroupAsk supprime une demande, ses réponses et sa notification.
+func deleteGroupAsk(tx *sql.Tx, askId string) error {
+	_, err := tx.Exec(`DELETE FROM GROUP_ANSWERS WHERE ASK_ID = ?`, askId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete answers")
+	}
+	_, err = tx.Exec(`DELETE FROM NOTIFICATIONS WHERE TYPE = 'ASK_GROUP' AND ID_TYPE = ?`, askId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete ask notification")
+	}
+	_, err = tx.Exec(`DELETE FROM ASK_GROUP WHERE ID = ?`, askId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete ask request")
+	}
+	return nil
+}
+
+// addJoinDecision enregistre la décision et notifie le candidat.
+func addJoinDecision(tx *sql.Tx, groupId, askerId, deciderId string, accepted bool, reason string, blockedUntil sql.NullString) error {
+	decisionId := uuid.New().String()
+	query := `INSERT INTO GROUP_JOIN_DECISIONS(ID, GROUP_ID, USER_ID, DECIDED_BY, ACCEPTED, REASON, BLOCKED_UNTIL, CREATED_AT)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'))`
+	_, err := tx.Exec(query, decisionId, groupId, askerId, deciderId, accepted, toNullString(reason), blockedUntil)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert join decision")
+	}
+
+	notifType := "GROUP_REQUEST_DECLINED"
+	if accepted {
+		notifType = "GROUP_REQUEST_ACCEPTED"
+	}
+	query = `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE) VALUES (?, ?, ?, ?)`
+	_, err = tx.Exec(query, uuid.New().String(), notifType, askerId, decisionId)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert notification")
+	}
+
+	return nil
+}
+
+// groupDecision renvoie les données de notification d'une décision. Le décideur n'est pas exposé.
+func groupDecision(db *sql.DB, decisionId string) (GroupDecisionData, error) {
+	var d GroupDecisionData
+	var imgGroup sql.NullString
+
+	query := `SELECT d.GROUP_ID, g.TITLE, g.IMAGE, d.ACCEPTED, IFNULL(d.REASON, ''), IFNULL(d.BLOCKED_UNTIL, ''), d.CREATED_AT
+	          FROM GROUP_JOIN_DECISIONS d JOIN ALL_GROUPS g ON g.ID = d.GROUP_ID WHERE d.ID = ?`
+	err := db.QueryRow(query, decisionId).Scan(&d.GroupID, &d.GroupName, &imgGroup, &d.Accepted, &d.Reason, &d.BlockedUntil, &d.CreatedAt)
+	if err != nil {
+		return d, err
+	}
+	if imgGroup.Valid {
+		d.GroupPic = imgGroup.String
+	}
+
+	return d, nil
+}
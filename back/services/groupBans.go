@@ -0,0 +1,185 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+const maxBanReasonLength = 500
+
+var ErrGroupBanned = errors.New("you are banned from this group")
+
+// Durées de bannissement proposées ; sans durée le bannissement dure jusqu'à sa levée
+var groupBanDurations = map[string]time.Duration{
+	"1d": 24 * time.Hour,
+	"1w": 7 * 24 * time.Hour,
+	"1m": 30 * 24 * time.Hour,
+	"1y": 365 * 24 * time.Hour,
+}
+
+// Un bannissement est actif tant qu'il n'a pas expiré
+const activeGroupBan = `(EXPIRES_AT IS NULL OR EXPIRES_AT > datetime('now'))`
+
+type GroupBan struct {
+	User      User   `json:"user"`
+	BannedBy  User   `json:"banned_by"`
+	Reason    string `json:"reason"`
+	ExpiresAt string `json:"expires_at"` // vide : jusqu'à la levée
+	CreatedAt string `json:"created_at"`
+}
+
+// isBannedFromGroup indique si l'utilisateur est banni du groupe.
+func isBannedFromGroup(db *sql.DB, userId, groupId string) (bool, error) {
+	var banned bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUP_BANS WHERE GROUP_ID = ? AND USER_ID = ? AND ` + activeGroupBan + `)`
+	err := db.QueryRow(query, groupId, userId).Scan(&banned)
+	return banned, err
+}
+
+// checkNotBannedFromGroup refuse l'entrée dans le groupe à un utilisateur banni.
+func checkNotBannedFromGroup(db *sql.DB, userId, groupId string) error {
+	banned, err := isBannedFromGroup(db, userId, groupId)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrGroupBanned
+	}
+	return nil
+}
+
+// BanGroupMember exclut l'utilisateur et l'empêche de revenir (demande, invitation ou lien).
+// duration vaut "1d", "1w", "1m", "1y" ou "" (jusqu'à la levée). Un non-membre peut aussi être banni ;
+// bannir à nouveau remplace la durée et le motif.
+func BanGroupMember(db *sql.DB, actorId, groupId, targetId, duration, reason string) error {
+	if actorId == targetId {
+		return errors.New("you cannot do this on yourself")
+	}
+
+	targetRole, err := groupRole(db, targetId, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to get group role")
+	}
+	if targetRole != "" {
+		// Un membre ne peut être banni que par un rang supérieur
+		if _, _, err = checkOutranks(db, actorId, targetId, groupId, PermRemoveMember); err != nil {
+			return err
+		}
+	} else {
+		if _, err = checkGroupPermission(db, actorId, groupId, PermRemoveMember); err != nil {
+			return err
+		}
+		var exists bool
+		if err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM USER WHERE ID = ?)`, targetId).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("user not found")
+		}
+	}
+
+	var expiresAt sql.NullString
+	if duration != "" {
+		d, ok := groupBanDurations[duration]
+		if !ok {
+			return errors.New("invalid duration (1d, 1w, 1m, 1y or empty)")
+		}
+		expiresAt = sql.NullString{String: time.Now().UTC().Add(d).Format("2006-01-02 15:04:05"), Valid: true}
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > maxBanReasonLength {
+		return errors.Errorf("ban reason too long (max %d characters)", maxBanReasonLength)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO GROUP_BANS(ID, GROUP_ID, USER_ID, BANNED_BY, REASON, EXPIRES_AT, CREATED_AT)
+	          VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
+	          ON CONFLICT(GROUP_ID, USER_ID) DO UPDATE SET BANNED_BY = excluded.BANNED_BY, REASON = excluded.REASON,
+	              EXPIRES_AT = excluded.EXPIRES_AT, CREATED_AT = excluded.CREATED_AT`
+	_, err = tx.Exec(query, uuid.New().String(), groupId, targetId, actorId, toNullString(reason), expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert group ban")
+	}
+
+	// Retire aussi les demandes et invitations en attente d'un non-membre
+	if err = removeGroupMember(tx, targetId, groupId); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// UnbanGroupMember lève le bannissement : l'utilisateur peut de nouveau demander à rejoindre le groupe.
+func UnbanGroupMember(db *sql.DB, actorId, groupId, targetId string) error {
+	if _, err := checkGroupPermission(db, actorId, groupId, PermRemoveMember); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM GROUP_BANS WHERE GROUP_ID = ? AND USER_ID = ? AND ` + activeGroupBan
+	res, err := db.Exec(query, groupId, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group ban")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("user is not banned from this group")
+	}
+	return nil
+}
+
+// ListGroupBans renvoie les bannissements actifs du groupe, du plus récent au plus ancien.
+func ListGroupBans(db *sql.DB, actorId, groupId string) ([]GroupBan, error) {
+	if _, err := checkGroupPermission(db, actorId, groupId, PermRemoveMember); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT USER_ID, BANNED_BY, IFNULL(REASON, ''), IFNULL(EXPIRES_AT, ''), CREATED_AT FROM GROUP_BANS
+	          WHERE GROUP_ID = ? AND ` + activeGroupBan + ` ORDER BY CREATED_AT DESC`
+	rows, err := db.Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	type banRow struct {
+		ban              GroupBan
+		userId, bannedBy string
+	}
+	var banRows []banRow
+	for rows.Next() {
+		var b banRow
+		if err = rows.Scan(&b.userId, &b.bannedBy, &b.ban.Reason, &b.ban.ExpiresAt, &b.ban.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		banRows = append(banRows, b)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	bans := []GroupBan{}
+	for _, b := range banRows {
+		if b.ban.User, err = getUserByID(db, b.userId); err != nil {
+			return nil, err
+		}
+		if b.ban.BannedBy, err = getUserByID(db, b.bannedBy); err != nil {
+			return nil, err
+		}
+		bans = append(bans, b.ban)
+	}
+
+	return bans, nil
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	DefaultGroupChannel      = "general"
+	maxGroupChannelName      = 32
+	maxGroupChannelDesc      = 200
+	maxGroupChannelsPerGroup = 50
+)
+
+var groupChannelName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+var ErrGroupChannelNotFound = errors.New("group channel not found")
+
+type GroupChannel struct {
+	ID          string   `json:"id"`
+	GroupID     string   `json:"group_id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"` // vide : ouvert à tous les membres
+	IsDefault   bool     `json:"is_default"`
+	Subscribed  bool     `json:"subscribed"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+// groupChannelRow : salon et rôles autorisés tels que stockés (NULL : tous les membres)
+type groupChannelRow struct {
+	GroupChannel
+	roles sql.NullString
+}
+
+const groupChannelColumns = `ID, GROUP_ID, NAME, DESCRIPTION, ROLES, IS_DEFAULT, CREATED_AT`
+
+func scanGroupChannel(row interface{ Scan(...any) error }) (groupChannelRow, error) {
+	var c groupChannelRow
+	err := row.Scan(&c.ID, &c.GroupID, &c.Name, &c.Description, &c.roles, &c.IsDefault, &c.CreatedAt)
+	c.setRoles()
+	return c, err
+}
+
+func (c *groupChannelRow) setRoles() {
+	c.Roles = []string{}
+	if c.roles.Valid {
+		c.Roles = strings.Split(c.roles.String, ",")
+	}
+}
+
+// allows indique si un membre de ce rôle accède au salon. Les gestionnaires du groupe
+// (propriétaire, admins) accèdent à tous les salons.
+func (c groupChannelRow) allows(role string) bool {
+	if role == "" {
+		return false
+	}
+	if !c.roles.Valid || hasGroupPermission(role, PermEditGroup) {
+		return true
+	}
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeGroupChannelName : "#Events " devient "events".
+func normalizeGroupChannelName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "#"))
+	if name == "" {
+		return "", errors.New("missing channel name")
+	}
+	if len(name) > maxGroupChannelName {
+		return "", errors.Errorf("channel name too long (max %d characters)", maxGroupChannelName)
+	}
+	if !groupChannelName.MatchString(name) {
+		return "", errors.New("channel name can only contain lowercase letters, digits, - and _")
+	}
+	return name, nil
+}
+
+// groupChannelRoles valide la liste des rôles autorisés. Une liste vide ouvre le salon à tous
+// les membres (NULL).
+func groupChannelRoles(roles []string) (sql.NullString, error) {
+	unique := map[string]bool{}
+	for _, r := range roles {
+		r = strings.ToLower(strings.TrimSpace(r))
+		if r == "" {
+			continue
+		}
+		if _, ok := roleRank[r]; !ok {
+			return sql.NullString{}, errors.Errorf("invalid role: %s", r)
+		}
+		unique[r] = true
+	}
+	if len(unique) == 0 || len(unique) == len(roleRank) {
+		return sql.NullString{}, nil
+	}
+
+	// Stockés du plus bas au plus haut rang
+	var list []string
+	for _, r := range []string{RoleMember, RoleModerator, RoleAdmin, RoleOwner} {
+		if unique[r] {
+			list = append(list, r)
+		}
+	}
+	return sql.NullString{String: strings.Join(list, ","), Valid: true}, nil
+}
+
+func groupChannel(db *sql.DB, channelId string) (groupChannelRow, error) {
+	query := `SELECT ` + groupChannelColumns + ` FROM GROUP_CHANNELS WHERE ID = ?`
+	c, err := scanGroupChannel(db.QueryRow(query, channelId))
+	if err == sql.ErrNoRows {
+		return c, ErrGroupChannelNotFound
+	}
+	return c, err
+}
+
+// createDefaultGroupChannel crée le salon #general d'un nouveau groupe.
+func createDefaultGroupChannel(tx *sql.Tx, groupId, userId string) error {
+	query := `INSERT INTO GROUP_CHANNELS(ID, GROUP_ID, NAME, IS_DEFAULT, CREATED_BY, CREATED_AT)
+	          VALUES (?, ?, ?, 1, ?, datetime('now'))`
+	_, err := tx.Exec(query, uuid.New().String(), groupId, DefaultGroupChannel, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to create default group channel")
+	}
+	return nil
+}
+
+// resolveGroupChannel renvoie le salon du groupe ("" : le salon par défaut) si l'utilisateur y a accès.
+// Un salon réservé à d'autres rôles renvoie ErrGroupChannelNotFound, pour ne pas en révéler l'existence.
+func resolveGroupChannel(db *sql.DB, userId, groupId, channelId string) (groupChannelRow, error) {
+	role, err := checkGroupMember(db, userId, groupId)
+	if err != nil {
+		return groupChannelRow{}, err
+	}
+
+	var c groupChannelRow
+	if channelId == "" {
+		query := `SELECT ` + groupChannelColumns + ` FROM GROUP_CHANNELS WHERE GROUP_ID = ? AND IS_DEFAULT = 1`
+		c, err = scanGroupChannel(db.QueryRow(query, groupId))
+		if err == sql.ErrNoRows {
+			err = ErrGroupChannelNotFound
+		}
+	} else {
+		c, err = groupChannel(db, channelId)
+	}
+	if err != nil {
+		return c, err
+	}
+
+	if c.GroupID != groupId || !c.allows(role) {
+		return c, ErrGroupChannelNotFound
+	}
+	return c, nil
+}
+
+// ListGroupChannels renvoie les salons du groupe accessibles à l'utilisateur, le salon par défaut en tête.
+func ListGroupChannels(db *sql.DB, userId, groupId string) ([]GroupChannel, error) {
+	role, err := checkGroupMember(db, userId, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + groupChannelColumns + `,
+	                 NOT EXISTS(SELECT 1 FROM GROUP_CHANNEL_UNSUBSCRIBED u WHERE u.CHANNEL_ID = GROUP_CHANNELS.ID AND u.USER_ID = ?)
+	          FROM GROUP_CHANNELS WHERE GROUP_ID = ? ORDER BY IS_DEFAULT DESC, NAME`
+	rows, err := db.Query(query, userId, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []GroupChannel{}
+	for rows.Next() {
+		var c groupChannelRow
+		err = rows.Scan(&c.ID, &c.GroupID, &c.Name, &c.Description, &c.roles, &c.IsDefault, &c.CreatedAt, &c.Subscribed)
+		if err != nil {
+			return nil, err
+		}
+		c.setRoles()
+		if c.allows(role) {
+			channels = append(channels, c.GroupChannel)
+		}
+	}
+	return channels, rows.Err()
+}
+
+// CreateGroupChannel crée un salon (propriétaire et admins). roles vide : ouvert à tous les membres.
+func CreateGroupChannel(db *sql.DB, userId, groupId, name, description string, roles []string) (GroupChannel, error) {
+	if _, err := checkGroupPermission(db, userId, groupId, PermEditGroup); err != nil {
+		return GroupChannel{}, err
+	}
+
+	name, err := normalizeGroupChannelName(name)
+	if err != nil {
+		return GroupChannel{}, err
+	}
+	description = strings.TrimSpace(description)
+	if len([]rune(description)) > maxGroupChannelDesc {
+		return GroupChannel{}, errors.Errorf("channel description too long (max %d characters)", maxGroupChannelDesc)
+	}
+	stored, err := groupChannelRoles(roles)
+	if err != nil {
+		return GroupChannel{}, err
+	}
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUP_CHANNELS WHERE GROUP_ID = ? AND NAME = ?)`
+	if err = db.QueryRow(query, groupId, name).Scan(&exists); err != nil {
+		return GroupChannel{}, err
+	}
+	if exists {
+		return GroupChannel{}, errors.New("a channel with this name already exists")
+	}
+	var count int
+	if err = db.QueryRow(`SELECT COUNT(*) FROM GROUP_CHANNELS WHERE GROUP_ID = ?`, groupId).Scan(&count); err != nil {
+		return GroupChannel{}, err
+	}
+	if count >= maxGroupChannelsPerGroup {
+		return GroupChannel{}, errors.Errorf("too many channels in this group (max %d)", maxGroupChannelsPerGroup)
+	}
+
+	id := uuid.New().String()
+	query = `INSERT INTO GROUP_CHANNELS(ID, GROUP_ID, NAME, DESCRIPTION, ROLES, IS_DEFAULT, CREATED_BY, CREATED_AT)
+	         VALUES (?, ?, ?, ?, ?, 0, ?, datetime('now'))`
+	if _, err = db.Exec(query, id, groupId, name, description, stored, userId); err != nil {
+		return GroupChannel{}, errors.Wrap(err, "failed to insert group channel")
+	}
+
+	c, err := groupChannel(db, id)
+	c.Subscribed = true
+	return c.GroupChannel, err
+}
+
+// UpdateGroupChannel renomme un salon ("" : inchangé), change sa description (nil : inchangée)
+// ou ses rôles autorisés (nil : inchangés, vide : tous les membres). Le salon par défaut reste
+// ouvert à tous.
+func UpdateGroupChannel(db *sql.DB, userId, channelId, name string, description *string, roles []string) (GroupChannel, error) {
+	c, err := groupChannel(db, channelId)
+	if err != nil {
+		return GroupChannel{}, err
+	}
+	if _, err = checkGroupPermission(db, userId, c.GroupID, PermEditGroup); err != nil {
+		return GroupChannel{}, err
+	}
+
+	if name != "" {
+		if name, err = normalizeGroupChannelName(name); err != nil {
+			return GroupChannel{}, err
+		}
+		var exists bool
+		query := `SELECT EXISTS(SELECT 1 FROM GROUP_CHANNELS WHERE GROUP_ID = ? AND NAME = ? AND ID <> ?)`
+		if err = db.QueryRow(query, c.GroupID, name, channelId).Scan(&exists); err != nil {
+			return GroupChannel{}, err
+		}
+		if exists {
+			return GroupChannel{}, errors.New("a channel with this name already exists")
+		}
+		if _, err = db.Exec(`UPDATE GROUP_CHANNELS SET NAME = ? WHERE ID = ?`, name, channelId); err != nil {
+			return GroupChannel{}, errors.Wrap(err, "failed to rename group channel")
+		}
+	}
+
+	if description != nil {
+		desc := strings.TrimSpace(*description)
+		if len([]rune(desc)) > maxGroupChannelDesc {
+			return GroupChannel{}, errors.Errorf("channel description too long (max %d characters)", maxGroupChannelDesc)
+		}
+		if _, err = db.Exec(`UPDATE GROUP_CHANNELS SET DESCRIPTION = ? WHERE ID = ?`, desc, channelId); err != nil {
+			return GroupChannel{}, errors.Wrap(err, "failed to update group channel description")
+		}
+	}
+
+	if roles != nil {
+		stored, err := groupChannelRoles(roles)
+		if err != nil {
+			return GroupChannel{}, err
+		}
+		if stored.Valid && c.IsDefault {
+			return GroupChannel{}, errors.New("the default channel cannot be restricted")
+		}
+		if _, err = db.Exec(`UPDATE GROUP_CHANNELS SET ROLES = ? WHERE ID = ?`, stored, channelId); err != nil {
+			return GroupChannel{}, errors.Wrap(err, "failed to update group channel roles")
+		}
+	}
+
+	c, err = groupChannel(db, channelId)
+	if err != nil {
+		return GroupChannel{}, err
+	}
+	query := `SELECT NOT EXISTS(SELECT 1 FROM GROUP_CHANNEL_UNSUBSCRIBED WHERE CHANNEL_ID = ? AND USER_ID = ?)`
+	err = db.QueryRow(query, channelId, userId).Scan(&c.Subscribed)
+	return c.GroupChannel, err
+}
+
+// DeleteGroupChannel supprime un salon et ses messages (propriétaire et admins).
+// Le salon par défaut ne peut pas être supprimé.
+func DeleteGroupChannel(db *sql.DB, userId, channelId string) error {
+	c, err := groupChannel(db, channelId)
+	if err != nil {
+		return err
+	}
+	if _, err = checkGroupPermission(db, userId, c.GroupID, PermEditGroup); err != nil {
+		return err
+	}
+	if c.IsDefault {
+		return errors.New("the default channel cannot be deleted")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `SELECT 'groupMessages', CONTENT FROM MESSAGES WHERE CHANNEL_ID = ? AND TYPE = 1 AND IFNULL(CONTENT, '') <> ''`
+	rows, err := tx.Query(query, channelId)
+	if err != nil {
+		return err
+	}
+	var files []groupFile
+	for rows.Next() {
+		var f groupFile
+		if err = rows.Scan(&f.dir, &f.name); err != nil {
+			rows.Close()
+			return err
+		}
+		files = append(files, f)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM MESSAGES WHERE CHANNEL_ID = ?`, channelId); err != nil {
+		return errors.Wrap(err, "failed to delete channel messages")
+	}
+	if _, err = tx.Exec(`DELETE FROM GROUP_CHANNEL_UNSUBSCRIBED WHERE CHANNEL_ID = ?`, channelId); err != nil {
+		return errors.Wrap(err, "failed to delete channel subscriptions")
+	}
+	if _, err = tx.Exec(`DELETE FROM GROUP_CHANNELS WHERE ID = ?`, channelId); err != nil {
+		return errors.Wrap(err, "failed to delete group channel")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	removeGroupFiles(files)
+	return nil
+}
+
+// SetGroupChannelSubscription abonne ou désabonne l'utilisateur d'un salon : un membre désabonné
+// peut toujours lire le salon mais n'en reçoit plus les messages en direct.
+func SetGroupChannelSubscription(db *sql.DB, userId, channelId string, subscribed bool) error {
+	c, err := groupChannel(db, channelId)
+	if err != nil {
+		return err
+	}
+	if _, err = resolveGroupChannel(db, userId, c.GroupID, channelId); err != nil {
+		return err
+	}
+
+	if subscribed {
+		_, err = db.Exec(`DELETE FROM GROUP_CHANNEL_UNSUBSCRIBED WHERE CHANNEL_ID = ? AND USER_ID = ?`, channelId, userId)
+	} else {
+		query := `INSERT OR IGNORE INTO GROUP_CHANNEL_UNSUBSCRIBED(CHANNEL_ID, USER_ID, CREATED_AT) VALUES (?, ?, datetime('now'))`
+		_, err = db.Exec(query, channelId, userId)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to update channel subscription")
+	}
+	return nil
+}
+
+// GroupChannelSubscribers renvoie les membres qui ont accès au salon et y sont abonnés.
+// L'expéditeur est toujours gardé, pour que son message lui revienne sur ses autres sessions.
+func GroupChannelSubscribers(db *sql.DB, groupId, channelId, senderId string) ([]string, error) {
+	c, err := groupChannel(db, channelId)
+	if err != nil {
+		return nil, err
+	}
+	if c.GroupID != groupId {
+		return nil, ErrGroupChannelNotFound
+	}
+
+	query := `SELECT USER_ID, ROLE FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1 AND (USER_ID = ?3 OR USER_ID NOT IN (
+	              SELECT USER_ID FROM GROUP_CHANNEL_UNSUBSCRIBED WHERE CHANNEL_ID = ?2))`
+	rows, err := db.Query(query, groupId, channelId, senderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []string
+	for rows.Next() {
+		var userId, role string
+		if err = rows.Scan(&userId, &role); err != nil {
+			return nil, err
+		}
+		if c.allows(role) {
+			members = append(members, userId)
+		}
+	}
+	return members, rows.Err()
+}
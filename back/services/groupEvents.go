@@ -0,0 +1,421 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+	_ "time/tzdata" // fuseaux IANA disponibles même sans zoneinfo sur la machine
+	"unicode/utf8"
+)
+
+const (
+	RSVPGoing    = "going"
+	RSVPMaybe    = "maybe"
+	RSVPNotGoing = "not_going"
+	RSVPWaitlist = "waitlist" // going au-delà de la capacité
+)
+
+const (
+	maxEventTitleLength  = 200
+	maxEventReminders    = 5
+	minEventReminder     = 5               // minutes
+	maxEventReminder     = 4 * 7 * 24 * 60 // 4 semaines
+	eventSQLTimeLayout   = "2006-01-02 15:04:05"
+	eventInputTimeLayout = "2006-01-02T15:04"
+)
+
+// Rappels par défaut (minutes avant le début) quand l'organisateur n'en choisit pas
+var DefaultEventReminders = []int{24 * 60, 60}
+
+// EventInput : start et end sont des heures locales du fuseau TimeZone ("2006-01-02T15:04") ou du RFC 3339.
+type EventInput struct {
+	Title       string
+	Description string
+	Start       string
+	End         string // facultatif
+	TimeZone    string // IANA, UTC par défaut
+	Location    string
+	Capacity    int    // 0 : illimité
+	Reminders   []int  // minutes avant le début ; nil : DefaultEventReminders
+	Recurrence  string // sous-ensemble RRULE (voir parseRecurrence), vide pour un événement unique
+}
+
+// eventFields : EventInput validé, prêt à être enregistré (heures en UTC)
+type eventFields struct {
+	title       string
+	description string
+	start       time.Time
+	end         sql.NullString
+	timeZone    string
+	location    sql.NullString
+	capacity    sql.NullInt64
+	reminders   []int
+	rule        *recurrence
+	lastStart   time.Time
+}
+
+func (in EventInput) validate() (eventFields, error) {
+	var f eventFields
+
+	f.title = strings.TrimSpace(in.Title)
+	if f.title == "" {
+		return f, errors.New("missing event title")
+	}
+	if utf8.RuneCountInString(f.title) > maxEventTitleLength {
+		return f, errors.Errorf("event title too long (max %d characters)", maxEventTitleLength)
+	}
+	f.description = strings.TrimSpace(in.Description)
+
+	f.timeZone = strings.TrimSpace(in.TimeZone)
+	if f.timeZone == "" {
+		f.timeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(f.timeZone)
+	if err != nil {
+		return f, errors.New("invalid time zone")
+	}
+
+	f.start, err = parseEventTime(in.Start, loc)
+	if err != nil {
+		return f, errors.New("invalid start time")
+	}
+	if strings.TrimSpace(in.End) != "" {
+		end, err := parseEventTime(in.End, loc)
+		if err != nil {
+			return f, errors.New("invalid end time")
+		}
+		if !end.After(f.start) {
+			return f, errors.New("event must end after it starts")
+		}
+		f.end = sql.NullString{String: end.UTC().Format(eventSQLTimeLayout), Valid: true}
+	}
+
+	// La série commence par start, qui doit donc suivre la règle
+	f.lastStart = f.start
+	f.rule, err = parseRecurrence(in.Recurrence, loc)
+	if err != nil {
+		return f, err
+	}
+	if f.rule != nil {
+		starts, err := f.rule.expand(f.start.In(loc))
+		if err != nil {
+			return f, err
+		}
+		if len(starts) == 0 || !starts[0].Equal(f.start) {
+			return f, errors.New("event start must match the recurrence rule")
+		}
+		f.lastStart = starts[len(starts)-1]
+	}
+
+	f.location = toNullString(strings.TrimSpace(in.Location))
+
+	if in.Capacity < 0 {
+		return f, errors.New("invalid capacity")
+	}
+	if in.Capacity > 0 {
+		f.capacity = sql.NullInt64{Int64: int64(in.Capacity), Valid: true}
+	}
+
+	f.reminders = DefaultEventReminders
+	if in.Reminders != nil {
+		f.reminders = nil
+		seen := map[int]bool{}
+		for _, m := range in.Reminders {
+			if m < minEventReminder || m > maxEventReminder {
+				return f, errors.Errorf("reminders must be between %d minutes and 4 weeks before the event", minEventReminder)
+			}
+			if !seen[m] {
+				seen[m] = true
+				f.reminders = append(f.reminders, m)
+			}
+		}
+		if len(f.reminders) > maxEventReminders {
+			return f, errors.Errorf("too many reminders (max %d)", maxEventReminders)
+		}
+	}
+
+	return f, nil
+}
+
+func parseEventTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(eventInputTimeLayout, value, loc); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04", value, loc)
+}
+
+// formatEventTime convertit une heure UTC stockée en RFC 3339 dans le fuseau de l'événement.
+func formatEventTime(value, timeZone string) string {
+	t, err := time.Parse(eventSQLTimeLayout, value)
+	if err != nil {
+		return value
+	}
+	if loc, err := time.LoadLocation(timeZone); err == nil {
+		t = t.In(loc)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// canManageEvent autorise l'organisateur (toujours membre) et les rôles pouvant modifier le groupe.
+// Renvoie l'ID du groupe de l'événement.
+func canManageEvent(db *sql.DB, userId, eventId string) (string, error) {
+	var groupId, sender string
+	var cancelled bool
+	query := `SELECT GROUP_ID, SENDER, CANCELLED_AT IS NOT NULL FROM GROUPS_EVENT WHERE ID = ?`
+	err := db.QueryRow(query, eventId).Scan(&groupId, &sender, &cancelled)
+	if err == sql.ErrNoRows {
+		return "", errors.New("event not found")
+	}
+	if err != nil {
+		return "", err
+	}
+	if cancelled {
+		return "", errors.New("this event has been cancelled")
+	}
+
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return "", err
+	}
+	if role == "" {
+		return "", errors.New("user is not a member of the group")
+	}
+	if sender != userId && !hasGroupPermission(role, PermEditGroup) {
+		return "", ErrGroupPermission
+	}
+
+	return groupId, nil
+}
+
+// UpdateEventGroup remplace les informations de l'événement (toute la série s'il est récurrent).
+// Les réponses de la i-ème occurrence suivent la i-ème occurrence du nouveau calendrier.
+// Une capacité augmentée fait passer les premiers inscrits en liste d'attente à going ;
+// une capacité réduite ne retire personne.
+func UpdateEventGroup(db *sql.DB, userId, eventId string, in EventInput) (string, error) {
+	groupId, err := canManageEvent(db, userId, eventId)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := in.validate()
+	if err != nil {
+		return "", err
+	}
+
+	old, err := loadEventSeries(db, eventId)
+	if err != nil {
+		return "", err
+	}
+	oldStarts, err := old.occurrenceStarts()
+	if err != nil {
+		return "", err
+	}
+	var oldKeys []string
+	for _, t := range oldStarts {
+		oldKeys = append(oldKeys, old.occurrenceKeyOf(t))
+	}
+
+	updated := old
+	updated.start = f.start.UTC()
+	updated.loc, _ = time.LoadLocation(f.timeZone)
+	updated.rule = f.rule
+	newStarts, err := updated.occurrenceStarts()
+	if err != nil {
+		return "", err
+	}
+	var newKeys []string
+	for _, t := range newStarts {
+		newKeys = append(newKeys, updated.occurrenceKeyOf(t))
+	}
+
+	var rule sql.NullString
+	if f.rule != nil {
+		rule = sql.NullString{String: f.rule.String(), Valid: true}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE GROUPS_EVENT SET TITLE = ?, DESCRIPTION = ?, START_AT = ?, END_AT = ?, TIME_ZONE = ?,
+	          LOCATION = ?, CAPACITY = ?, RRULE = ?, LAST_START_AT = ?, UPDATED_AT = datetime('now') WHERE ID = ?`
+	_, err = tx.Exec(query, f.title, f.description, f.start.UTC().Format(eventSQLTimeLayout), f.end, f.timeZone,
+		f.location, f.capacity, rule, f.lastStart.UTC().Format(eventSQLTimeLayout), eventId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to update event")
+	}
+
+	if err = remapOccurrences(tx, eventId, oldKeys, newKeys); err != nil {
+		return "", err
+	}
+	if err = saveEventReminders(tx, eventId, f.reminders); err != nil {
+		return "", err
+	}
+
+	// Places libérées par une capacité plus grande, pour chaque occurrence
+	rows, err := tx.Query(`SELECT DISTINCT OCCURRENCE_AT FROM EVENT_RSVPS WHERE EVENT_ID = ? AND STATUS = 'waitlist'`, eventId)
+	if err != nil {
+		return "", err
+	}
+	var waiting []string
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			rows.Close()
+			return "", err
+		}
+		waiting = append(waiting, key)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return "", err
+	}
+	for _, key := range waiting {
+		if err = promoteWaitlist(tx, eventId, key); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "transaction commit failed")
+	}
+
+	return groupId, nil
+}
+
+// CancelEventGroup annule l'événement : les réponses sont conservées, les rappels ne partent plus.
+func CancelEventGroup(db *sql.DB, userId, eventId string) (string, error) {
+	groupId, err := canManageEvent(db, userId, eventId)
+	if err != nil {
+		return "", err
+	}
+
+	query := `UPDATE GROUPS_EVENT SET CANCELLED_AT = datetime('now'), UPDATED_AT = datetime('now') WHERE ID = ?`
+	if _, err = db.Exec(query, eventId); err != nil {
+		return "", errors.Wrap(err, "failed to cancel event")
+	}
+
+	return groupId, nil
+}
+
+// saveEventReminders remplace les rappels de l'événement et oublie ceux déjà envoyés :
+// seuls les rappels dont l'heure suit cette modification partiront (voir SendEventReminders).
+func saveEventReminders(tx *sql.Tx, eventId string, offsets []int) error {
+	_, err := tx.Exec(`DELETE FROM EVENT_REMINDERS WHERE EVENT_ID = ?`, eventId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete event reminders")
+	}
+	_, err = tx.Exec(`DELETE FROM EVENT_REMINDERS_SENT WHERE EVENT_ID = ?`, eventId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete sent event reminders")
+	}
+
+	query := `INSERT INTO EVENT_REMINDERS(EVENT_ID, OFFSET_MINUTES) VALUES (?, ?)`
+	for _, offset := range offsets {
+		if _, err = tx.Exec(query, eventId, offset); err != nil {
+			return errors.Wrap(err, "failed to insert event reminder")
+		}
+	}
+
+	return nil
+}
+
+// setEventRSVP enregistre la réponse à une occurrence ; UPDATED_AT sert d'ordre d'arrivée dans la liste d'attente.
+func setEventRSVP(tx *sql.Tx, eventId, occurrence, userId, status string) error {
+	query := `INSERT INTO EVENT_RSVPS(ID, EVENT_ID, OCCURRENCE_AT, USER_ID, STATUS, CREATED_AT, UPDATED_AT)
+	          VALUES (?, ?, ?, ?, ?, datetime('now'), datetime('now'))
+	          ON CONFLICT(EVENT_ID, OCCURRENCE_AT, USER_ID) DO UPDATE SET STATUS = excluded.STATUS, UPDATED_AT = excluded.UPDATED_AT`
+	_, err := tx.Exec(query, uuid.New().String(), eventId, occurrence, userId, status)
+	if err != nil {
+		return errors.Wrap(err, "failed to save event response")
+	}
+	return nil
+}
+
+// promoteWaitlist fait passer à going les premiers inscrits en attente d'une occurrence,
+// dans la limite des places libres, et les notifie.
+func promoteWaitlist(tx *sql.Tx, eventId, occurrence string) error {
+	// LIMIT -1 : pas de limite quand la capacité est illimitée
+	query := `SELECT USER_ID FROM EVENT_RSVPS WHERE EVENT_ID = ?1 AND OCCURRENCE_AT = ?2 AND STATUS = 'waitlist'
+	          ORDER BY UPDATED_AT, ID
+	          LIMIT (SELECT CASE WHEN e.CAPACITY IS NULL THEN -1
+	                 ELSE MAX(e.CAPACITY - (SELECT COUNT(*) FROM EVENT_RSVPS
+	                                        WHERE EVENT_ID = ?1 AND OCCURRENCE_AT = ?2 AND STATUS = 'going'), 0) END
+	                 FROM GROUPS_EVENT e WHERE e.ID = ?1)`
+	rows, err := tx.Query(query, eventId, occurrence)
+	if err != nil {
+		return errors.Wrap(err, "failed to read waitlist")
+	}
+	var promoted []string
+	for rows.Next() {
+		var userId string
+		if err = rows.Scan(&userId); err != nil {
+			rows.Close()
+			return err
+		}
+		promoted = append(promoted, userId)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, userId := range promoted {
+		query = `UPDATE EVENT_RSVPS SET STATUS = 'going' WHERE EVENT_ID = ? AND OCCURRENCE_AT = ? AND USER_ID = ?`
+		if _, err = tx.Exec(query, eventId, occurrence, userId); err != nil {
+			return errors.Wrap(err, "failed to promote waitlisted member")
+		}
+		query = `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE) VALUES (?, 'EVENT_WAITLIST_PROMOTED', ?, ?)`
+		if _, err = tx.Exec(query, uuid.New().String(), userId, eventNotifID(eventId, occurrence)); err != nil {
+			return errors.Wrap(err, "failed to insert notification")
+		}
+	}
+
+	return nil
+}
+
+// deleteMemberRSVPs retire les réponses d'un membre qui quitte le groupe et libère ses places.
+func deleteMemberRSVPs(tx *sql.Tx, userId, groupId string) error {
+	// Occurrences à venir où le membre occupait une place
+	query := `SELECT r.EVENT_ID, r.OCCURRENCE_AT FROM EVENT_RSVPS r JOIN GROUPS_EVENT e ON e.ID = r.EVENT_ID
+	          WHERE r.USER_ID = ? AND e.GROUP_ID = ? AND r.STATUS = 'going' AND e.CANCELLED_AT IS NULL
+	          AND CASE r.OCCURRENCE_AT WHEN '' THEN e.START_AT ELSE r.OCCURRENCE_AT END > datetime('now')`
+	rows, err := tx.Query(query, userId, groupId)
+	if err != nil {
+		return err
+	}
+	var events [][2]string
+	for rows.Next() {
+		var eventId, occurrence string
+		if err = rows.Scan(&eventId, &occurrence); err != nil {
+			rows.Close()
+			return err
+		}
+		events = append(events, [2]string{eventId, occurrence})
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	query = `DELETE FROM EVENT_RSVPS WHERE USER_ID = ? AND EVENT_ID IN (SELECT ID FROM GROUPS_EVENT WHERE GROUP_ID = ?)`
+	if _, err = tx.Exec(query, userId, groupId); err != nil {
+		return errors.Wrap(err, "failed to delete event responses")
+	}
+
+	for _, e := range events {
+		if err = promoteWaitlist(tx, e[0], e[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
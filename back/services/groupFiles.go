@@ -0,0 +1,472 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"log"
+	"social-network/utils"
+	"sort"
+	"strings"
+)
+
+const (
+	GroupFilesDir      = "Images/groupFiles"
+	MaxGroupFileSize   = 20 << 20 // taille max d'un fichier envoyé
+	MaxGroupFileQuota  = 1 << 30
+	maxGroupFileName   = 255
+	maxGroupFolderName = 100
+)
+
+// Types MIME (détectés sur le contenu) acceptés par la bibliothèque ; un groupe peut en restreindre la liste
+var groupFileTypes = map[string]bool{
+	"application/pdf":              true,
+	"image/png":                    true,
+	"image/jpeg":                   true,
+	"image/gif":                    true,
+	"image/webp":                   true,
+	"text/plain":                   true,
+	"application/zip":              true, // aussi les documents bureautiques (docx, xlsx, odt...)
+	"application/x-gzip":           true,
+	"application/x-rar-compressed": true,
+}
+
+var (
+	ErrGroupFileNotFound   = errors.New("file not found")
+	ErrGroupFolderNotFound = errors.New("folder not found")
+	ErrGroupFileQuota      = errors.New("the group file library is full")
+)
+
+type GroupFile struct {
+	ID         string `json:"id"`
+	FolderID   string `json:"folder_id"` // vide : racine
+	Name       string `json:"name"`
+	URL        string `json:"url"` // nom à demander à /api/groupFiles/
+	MimeType   string `json:"mime_type"`
+	Size       int64  `json:"size"`
+	UploadedBy User   `json:"uploaded_by"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type GroupFolder struct {
+	ID        string `json:"id"`
+	ParentID  string `json:"parent_id"` // vide : racine
+	Name      string `json:"name"`
+	CreatedBy User   `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type GroupFileSettings struct {
+	Quota        int64    `json:"quota"` // en octets
+	Used         int64    `json:"used"`
+	AllowedTypes []string `json:"allowed_types"`
+}
+
+type GroupFileListing struct {
+	Folder   *GroupFolder      `json:"folder"` // nil à la racine
+	Folders  []GroupFolder     `json:"folders"`
+	Files    []GroupFile       `json:"files"`
+	Settings GroupFileSettings `json:"settings"`
+}
+
+// checkGroupFolder vérifie que le dossier appartient au groupe ("" : racine).
+func checkGroupFolder(db *sql.DB, groupId, folderId string) error {
+	if folderId == "" {
+		return nil
+	}
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUP_FILE_FOLDERS WHERE ID = ? AND GROUP_ID = ?)`
+	if err := db.QueryRow(query, folderId, groupId).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrGroupFolderNotFound
+	}
+	return nil
+}
+
+// groupFileSettings renvoie le quota, l'espace occupé et les types acceptés par le groupe.
+func groupFileSettings(db *sql.DB, groupId string) (GroupFileSettings, error) {
+	var s GroupFileSettings
+	var types string
+	query := `SELECT FILE_QUOTA, IFNULL(FILE_TYPES, ''),
+	                 (SELECT IFNULL(SUM(SIZE), 0) FROM GROUP_FILES WHERE GROUP_ID = ?1)
+	          FROM ALL_GROUPS WHERE ID = ?1`
+	err := db.QueryRow(query, groupId).Scan(&s.Quota, &types, &s.Used)
+	if err == sql.ErrNoRows {
+		return s, ErrGroupNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+
+	s.AllowedTypes = []string{}
+	if types == "" {
+		for t := range groupFileTypes {
+			s.AllowedTypes = append(s.AllowedTypes, t)
+		}
+	} else {
+		for _, t := range strings.Split(types, ",") {
+			// Un type retiré de la liste globale n'est plus accepté
+			if groupFileTypes[t] {
+				s.AllowedTypes = append(s.AllowedTypes, t)
+			}
+		}
+	}
+	sort.Strings(s.AllowedTypes)
+	return s, nil
+}
+
+// CheckGroupFileUpload vérifie qu'un membre peut envoyer un fichier de cette taille dans le dossier,
+// et renvoie les types MIME acceptés par le groupe, à passer à utils.SaveFile.
+func CheckGroupFileUpload(db *sql.DB, userId, groupId, folderId string, size int64) (map[string]bool, error) {
+	if size <= 0 {
+		return nil, errors.New("empty file")
+	}
+	if size > MaxGroupFileSize {
+		return nil, errors.Errorf("file too large (max %d MB)", MaxGroupFileSize>>20)
+	}
+	if _, err := checkGroupMember(db, userId, groupId); err != nil {
+		return nil, err
+	}
+	if err := checkGroupFolder(db, groupId, folderId); err != nil {
+		return nil, err
+	}
+
+	settings, err := groupFileSettings(db, groupId)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Used+size > settings.Quota {
+		return nil, ErrGroupFileQuota
+	}
+
+	allowed := map[string]bool{}
+	for _, t := range settings.AllowedTypes {
+		allowed[t] = true
+	}
+	return allowed, nil
+}
+
+// AddGroupFile enregistre un fichier déjà sauvegardé dans GroupFilesDir. Le quota est vérifié
+// de nouveau dans la transaction pour départager des envois simultanés.
+func AddGroupFile(db *sql.DB, userId, groupId, folderId, name, storedName, mimeType string, size int64) (GroupFile, error) {
+	var f GroupFile
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return f, errors.New("missing file name")
+	}
+	if len([]rune(name)) > maxGroupFileName {
+		return f, errors.Errorf("file name too long (max %d characters)", maxGroupFileName)
+	}
+
+	if _, err := CheckGroupFileUpload(db, userId, groupId, folderId, size); err != nil {
+		return f, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return f, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	f = GroupFile{ID: uuid.New().String(), FolderID: folderId, Name: name, URL: storedName, MimeType: mimeType, Size: size}
+	query := `INSERT INTO GROUP_FILES(ID, GROUP_ID, FOLDER_ID, NAME, STORED_NAME, MIME_TYPE, SIZE, UPLOADED_BY, CREATED_AT)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))`
+	_, err = tx.Exec(query, f.ID, groupId, toNullString(folderId), name, storedName, mimeType, size, userId)
+	if err != nil {
+		return f, errors.Wrap(err, "failed to insert group file")
+	}
+
+	var overQuota bool
+	query = `SELECT (SELECT SUM(SIZE) FROM GROUP_FILES WHERE GROUP_ID = ?1) > (SELECT FILE_QUOTA FROM ALL_GROUPS WHERE ID = ?1)`
+	if err = tx.QueryRow(query, groupId).Scan(&overQuota); err != nil {
+		return f, err
+	}
+	if overQuota {
+		return f, ErrGroupFileQuota
+	}
+
+	if err = tx.QueryRow(`SELECT CREATED_AT FROM GROUP_FILES WHERE ID = ?`, f.ID).Scan(&f.CreatedAt); err != nil {
+		return f, err
+	}
+	if err = tx.Commit(); err != nil {
+		return f, errors.Wrap(err, "transaction commit failed")
+	}
+
+	f.UploadedBy, err = getUserByID(db, userId)
+	return f, err
+}
+
+// ListGroupFiles renvoie le contenu d'un dossier de la bibliothèque ("" : racine), dossiers puis fichiers
+// par ordre alphabétique.
+func ListGroupFiles(db *sql.DB, userId, groupId, folderId string) (GroupFileListing, error) {
+	listing := GroupFileListing{Folders: []GroupFolder{}, Files: []GroupFile{}}
+
+	if _, err := checkGroupMember(db, userId, groupId); err != nil {
+		return listing, err
+	}
+
+	var err error
+	if folderId != "" {
+		folder, err := groupFolder(db, folderId)
+		if err == sql.ErrNoRows {
+			return listing, ErrGroupFolderNotFound
+		}
+		if err != nil {
+			return listing, err
+		}
+		if folder.groupId != groupId {
+			return listing, ErrGroupFolderNotFound
+		}
+		listing.Folder = &folder.GroupFolder
+	}
+
+	if listing.Settings, err = groupFileSettings(db, groupId); err != nil {
+		return listing, err
+	}
+
+	query := `SELECT f.ID, IFNULL(f.PARENT_ID, ''), f.NAME, f.CREATED_AT,
+	                 u.ID, u.LASTNAME, u.FIRSTNAME, IFNULL(u.USERNAME, ''), IFNULL(u.IMAGE, '')
+	          FROM GROUP_FILE_FOLDERS f JOIN USER u ON u.ID = f.CREATED_BY
+	          WHERE f.GROUP_ID = ? AND IFNULL(f.PARENT_ID, '') = ?
+	          ORDER BY f.NAME COLLATE NOCASE`
+	rows, err := db.Query(query, groupId, folderId)
+	if err != nil {
+		return listing, err
+	}
+	for rows.Next() {
+		var f GroupFolder
+		err = rows.Scan(&f.ID, &f.ParentID, &f.Name, &f.CreatedAt,
+			&f.CreatedBy.ID, &f.CreatedBy.Lastname, &f.CreatedBy.Firstname, &f.CreatedBy.Username, &f.CreatedBy.ProfilePic)
+		if err != nil {
+			rows.Close()
+			return listing, err
+		}
+		listing.Folders = append(listing.Folders, f)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return listing, err
+	}
+
+	query = `SELECT f.ID, IFNULL(f.FOLDER_ID, ''), f.NAME, f.STORED_NAME, f.MIME_TYPE, f.SIZE, f.CREATED_AT,
+	                u.ID, u.LASTNAME, u.FIRSTNAME, IFNULL(u.USERNAME, ''), IFNULL(u.IMAGE, '')
+	         FROM GROUP_FILES f JOIN USER u ON u.ID = f.UPLOADED_BY
+	         WHERE f.GROUP_ID = ? AND IFNULL(f.FOLDER_ID, '') = ?
+	         ORDER BY f.NAME COLLATE NOCASE`
+	rows, err = db.Query(query, groupId, folderId)
+	if err != nil {
+		return listing, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var f GroupFile
+		err = rows.Scan(&f.ID, &f.FolderID, &f.Name, &f.URL, &f.MimeType, &f.Size, &f.CreatedAt,
+			&f.UploadedBy.ID, &f.UploadedBy.Lastname, &f.UploadedBy.Firstname, &f.UploadedBy.Username, &f.UploadedBy.ProfilePic)
+		if err != nil {
+			return listing, err
+		}
+		listing.Files = append(listing.Files, f)
+	}
+
+	return listing, rows.Err()
+}
+
+type groupFolderRow struct {
+	GroupFolder
+	groupId string
+}
+
+func groupFolder(db *sql.DB, folderId string) (groupFolderRow, error) {
+	var f groupFolderRow
+	var createdBy string
+	query := `SELECT ID, GROUP_ID, IFNULL(PARENT_ID, ''), NAME, CREATED_BY, CREATED_AT FROM GROUP_FILE_FOLDERS WHERE ID = ?`
+	err := db.QueryRow(query, folderId).Scan(&f.ID, &f.groupId, &f.ParentID, &f.Name, &createdBy, &f.CreatedAt)
+	if err != nil {
+		return f, err
+	}
+	f.CreatedBy, err = getUserByID(db, createdBy)
+	return f, err
+}
+
+// CreateGroupFolder crée un dossier dans la bibliothèque ("" : à la racine). Deux dossiers d'un même
+// parent ne peuvent pas porter le même nom.
+func CreateGroupFolder(db *sql.DB, userId, groupId, parentId, name string) (GroupFolder, error) {
+	var f GroupFolder
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return f, errors.New("missing folder name")
+	}
+	if len([]rune(name)) > maxGroupFolderName {
+		return f, errors.Errorf("folder name too long (max %d characters)", maxGroupFolderName)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return f, errors.New("folder name cannot contain / or \\")
+	}
+
+	if _, err := checkGroupMember(db, userId, groupId); err != nil {
+		return f, err
+	}
+	if err := checkGroupFolder(db, groupId, parentId); err != nil {
+		return f, err
+	}
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUP_FILE_FOLDERS
+	          WHERE GROUP_ID = ? AND IFNULL(PARENT_ID, '') = ? AND NAME = ? COLLATE NOCASE)`
+	if err := db.QueryRow(query, groupId, parentId, name).Scan(&exists); err != nil {
+		return f, err
+	}
+	if exists {
+		return f, errors.New("a folder with this name already exists")
+	}
+
+	id := uuid.New().String()
+	query = `INSERT INTO GROUP_FILE_FOLDERS(ID, GROUP_ID, PARENT_ID, NAME, CREATED_BY, CREATED_AT)
+	         VALUES (?, ?, ?, ?, ?, datetime('now'))`
+	if _, err := db.Exec(query, id, groupId, toNullString(parentId), name, userId); err != nil {
+		return f, errors.Wrap(err, "failed to insert group folder")
+	}
+
+	folder, err := groupFolder(db, id)
+	return folder.GroupFolder, err
+}
+
+// DeleteGroupFolder supprime un dossier vide. Réservé à son créateur et aux modérateurs.
+func DeleteGroupFolder(db *sql.DB, userId, folderId string) error {
+	folder, err := groupFolder(db, folderId)
+	if err == sql.ErrNoRows {
+		return ErrGroupFolderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	role, err := checkGroupMember(db, userId, folder.groupId)
+	if err != nil {
+		return err
+	}
+	if folder.CreatedBy.ID != userId && !hasGroupPermission(role, PermModerateContent) {
+		return ErrGroupPermission
+	}
+
+	var notEmpty bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUP_FILE_FOLDERS WHERE PARENT_ID = ?1)
+	          OR EXISTS(SELECT 1 FROM GROUP_FILES WHERE FOLDER_ID = ?1)`
+	if err = db.QueryRow(query, folderId).Scan(&notEmpty); err != nil {
+		return err
+	}
+	if notEmpty {
+		return errors.New("folder is not empty")
+	}
+
+	if _, err = db.Exec(`DELETE FROM GROUP_FILE_FOLDERS WHERE ID = ?`, folderId); err != nil {
+		return errors.Wrap(err, "failed to delete group folder")
+	}
+	return nil
+}
+
+// DeleteGroupFile supprime un fichier de la bibliothèque. Réservé à celui qui l'a envoyé et aux modérateurs.
+func DeleteGroupFile(db *sql.DB, userId, fileId string) error {
+	var groupId, storedName, uploadedBy string
+	query := `SELECT GROUP_ID, STORED_NAME, UPLOADED_BY FROM GROUP_FILES WHERE ID = ?`
+	err := db.QueryRow(query, fileId).Scan(&groupId, &storedName, &uploadedBy)
+	if err == sql.ErrNoRows {
+		return ErrGroupFileNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	role, err := checkGroupMember(db, userId, groupId)
+	if err != nil {
+		return err
+	}
+	if uploadedBy != userId && !hasGroupPermission(role, PermModerateContent) {
+		return ErrGroupPermission
+	}
+
+	if _, err = db.Exec(`DELETE FROM GROUP_FILES WHERE ID = ?`, fileId); err != nil {
+		return errors.Wrap(err, "failed to delete group file")
+	}
+
+	// Le fichier n'est supprimé qu'une fois la ligne effacée
+	if err = utils.RemoveImage("groupFiles", storedName); err != nil {
+		log.Printf("Impossible de supprimer le fichier de groupe %s : %v", storedName, err)
+	}
+	return nil
+}
+
+// SetGroupFileSettings modifie le quota (0 : inchangé) et les types acceptés (nil : inchangés).
+func SetGroupFileSettings(db *sql.DB, userId, groupId string, quota int64, types []string) (GroupFileSettings, error) {
+	if _, err := checkGroupPermission(db, userId, groupId, PermEditGroup); err != nil {
+		return GroupFileSettings{}, err
+	}
+
+	if quota < 0 || quota > MaxGroupFileQuota {
+		return GroupFileSettings{}, errors.Errorf("quota must be between 1 and %d bytes", MaxGroupFileQuota)
+	}
+	if quota > 0 {
+		if _, err := db.Exec(`UPDATE ALL_GROUPS SET FILE_QUOTA = ? WHERE ID = ?`, quota, groupId); err != nil {
+			return GroupFileSettings{}, errors.Wrap(err, "failed to update group file quota")
+		}
+	}
+
+	if types != nil {
+		unique := map[string]bool{}
+		for _, t := range types {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if !groupFileTypes[t] {
+				return GroupFileSettings{}, errors.Errorf("unsupported file type: %s", t)
+			}
+			unique[t] = true
+		}
+		if len(unique) == 0 {
+			return GroupFileSettings{}, errors.New("at least one file type is required")
+		}
+
+		// Tous les types pris en charge : NULL, pour suivre les ajouts futurs à la liste
+		var stored sql.NullString
+		if len(unique) < len(groupFileTypes) {
+			list := make([]string, 0, len(unique))
+			for t := range unique {
+				list = append(list, t)
+			}
+			sort.Strings(list)
+			stored = sql.NullString{String: strings.Join(list, ","), Valid: true}
+		}
+		if _, err := db.Exec(`UPDATE ALL_GROUPS SET FILE_TYPES = ? WHERE ID = ?`, stored, groupId); err != nil {
+			return GroupFileSettings{}, errors.Wrap(err, "failed to update group file types")
+		}
+	}
+
+	return groupFileSettings(db, groupId)
+}
+
+// CanPassGroupFile renvoie le fichier si l'utilisateur est membre de son groupe. Un non-membre reçoit
+// ErrGroupFileNotFound, pour ne pas révéler l'existence du fichier.
+func CanPassGroupFile(db *sql.DB, userId, storedName string) (GroupFile, error) {
+	var f GroupFile
+	var groupId string
+	query := `SELECT ID, GROUP_ID, IFNULL(FOLDER_ID, ''), NAME, STORED_NAME, MIME_TYPE, SIZE, CREATED_AT
+	          FROM GROUP_FILES WHERE STORED_NAME = ?`
+	err := db.QueryRow(query, storedName).Scan(&f.ID, &groupId, &f.FolderID, &f.Name, &f.URL, &f.MimeType, &f.Size, &f.CreatedAt)
+	if err == sql.ErrNoRows {
+		return f, ErrGroupFileNotFound
+	}
+	if err != nil {
+		return f, errors.Wrap(err, "CanPassGroupFile")
+	}
+
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return f, err
+	}
+	if role == "" {
+		return f, ErrGroupFileNotFound
+	}
+	return f, nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"social-network/utils"
+	"time"
+)
+
+// Un code inconnu est traité comme introuvable (404), sans rien révéler du groupe
+var ErrInviteLinkNotFound = errors.New("invite link not found")
+
+// Durées de validité proposées ; sans durée le lien n'expire pas
+var inviteLinkExpiries = map[string]time.Duration{
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+	"1w": 7 * 24 * time.Hour,
+	"1m": 30 * 24 * time.Hour,
+}
+
+// Condition SQL : le lien l est encore utilisable
+const usableInviteLink = `l.REVOKED_AT IS NULL
+	AND (l.EXPIRES_AT IS NULL OR l.EXPIRES_AT > datetime('now'))
+	AND (l.MAX_USES IS NULL OR l.USES < l.MAX_USES)`
+
+type GroupInviteLink struct {
+	ID        string `json:"id"`
+	Code      string `json:"code"`
+	CreatedBy string `json:"created_by"`
+	MaxUses   int    `json:"max_uses"` // 0 : illimité
+	Uses      int    `json:"uses"`
+	ExpiresAt string `json:"expires_at"`
+	RevokedAt string `json:"revoked_at"`
+	CreatedAt string `json:"created_at"`
+	Members   int    `json:"members"` // membres actuels entrés par ce lien
+	Active    bool   `json:"active"`
+}
+
+type GroupInvitePreview struct {
+	GroupID     string `json:"group_id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	Visibility  string `json:"visibility"`
+	Members     int    `json:"members"`
+	IsMember    bool   `json:"is_member"`
+	Rules       string `json:"rules,omitempty"` // règlement à accepter après avoir rejoint
+}
+
+// CreateGroupInviteLink crée un lien d'invitation. expires vaut "1h", "1d", "1w", "1m" ou "" (jamais),
+// maxUses 0 pour un nombre d'utilisations illimité.
+func CreateGroupInviteLink(db *sql.DB, userId, groupId, expires string, maxUses int) (GroupInviteLink, error) {
+	var link GroupInviteLink
+
+	if _, err := checkGroupPermission(db, userId, groupId, PermManageRequests); err != nil {
+		return link, err
+	}
+	if maxUses < 0 {
+		return link, errors.New("invalid max uses")
+	}
+
+	var expiresAt sql.NullString
+	if expires != "" {
+		d, ok := inviteLinkExpiries[expires]
+		if !ok {
+			return link, errors.New("invalid expiry (1h, 1d, 1w or 1m)")
+		}
+		expiresAt = sql.NullString{String: time.Now().UTC().Add(d).Format("2006-01-02 15:04:05"), Valid: true}
+	}
+
+	link.ID = uuid.New().String()
+	link.Code = utils.GenerateToken(9)
+	query := `INSERT INTO GROUP_INVITE_LINKS(ID, GROUP_ID, CODE, CREATED_BY, MAX_USES, EXPIRES_AT, CREATED_AT)
+	          VALUES (?, ?, ?, ?, NULLIF(?, 0), ?, datetime('now'))`
+	_, err := db.Exec(query, link.ID, groupId, link.Code, userId, maxUses, expiresAt)
+	if err != nil {
+		return link, errors.Wrap(err, "failed to insert invite link")
+	}
+
+	return groupInviteLink(db, link.ID)
+}
+
+// ListGroupInviteLinks renvoie les liens du groupe, du plus récent au plus ancien, révoqués compris.
+func ListGroupInviteLinks(db *sql.DB, userId, groupId string) ([]GroupInviteLink, error) {
+	if _, err := checkGroupPermission(db, userId, groupId, PermManageRequests); err != nil {
+		return nil, err
+	}
+
+	var links []GroupInviteLink
+	rows, err := db.Query(inviteLinkSelect+` WHERE l.GROUP_ID = ? ORDER BY l.CREATED_AT DESC`, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link, err := scanInviteLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// RevokeGroupInviteLink désactive le lien ; les membres déjà entrés restent dans le groupe.
+func RevokeGroupInviteLink(db *sql.DB, userId, linkId string) error {
+	var groupId string
+	err := db.QueryRow(`SELECT GROUP_ID FROM GROUP_INVITE_LINKS WHERE ID = ?`, linkId).Scan(&groupId)
+	if err == sql.ErrNoRows {
+		return ErrInviteLinkNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = checkGroupPermission(db, userId, groupId, PermManageRequests); err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE GROUP_INVITE_LINKS SET REVOKED_AT = datetime('now') WHERE ID = ? AND REVOKED_AT IS NULL`, linkId)
+	if err != nil {
+		return errors.Wrap(err, "failed to revoke invite link")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("invite link already revoked")
+	}
+
+	return nil
+}
+
+// PreviewGroupInviteLink présente le groupe d'un lien valide, même secret : le lien vaut invitation.
+func PreviewGroupInviteLink(db *sql.DB, userId, code string) (GroupInvitePreview, error) {
+	var p GroupInvitePreview
+
+	_, groupId, err := usableGroupInviteLink(db, code)
+	if err != nil {
+		return p, err
+	}
+
+	var imgGroup sql.NullString
+	query := `SELECT ID, TITLE, DESCRIPTION, IMAGE, VISIBILITY,
+	                 (SELECT COUNT(*) FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1),
+	                 EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1 AND USER_ID = ?2)
+	          FROM ALL_GROUPS WHERE ID = ?1`
+	err = db.QueryRow(query, groupId, userId).Scan(&p.GroupID, &p.Title, &p.Description, &imgGroup, &p.Visibility, &p.Members, &p.IsMember)
+	if err != nil {
+		return p, err
+	}
+	if imgGroup.Valid {
+		p.Image = imgGroup.String
+	}
+
+	rules, err := currentGroupRules(db, groupId)
+	if err != nil {
+		return p, err
+	}
+	p.Rules = rules.Content
+
+	return p, nil
+}
+
+// JoinGroupByInviteLink fait entrer l'utilisateur dans le groupe du lien, sans passer par la validation.
+// Renvoie l'ID du groupe.
+func JoinGroupByInviteLink(db *sql.DB, userId, code string) (string, error) {
+	linkId, groupId, err := usableGroupInviteLink(db, code)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to check group membership")
+	}
+	if role != "" {
+		return "", errors.New("you are already a member of this group")
+	}
+
+	if err = checkNotBannedFromGroup(db, userId, groupId); err != nil {
+		return "", err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	// L'incrément conditionnel protège la limite d'utilisations contre les adhésions simultanées
+	query := `UPDATE GROUP_INVITE_LINKS AS l SET USES = USES + 1 WHERE l.ID = ? AND ` + usableInviteLink
+	res, err := tx.Exec(query, linkId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to update invite link")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return "", errors.New("this invite link is no longer valid")
+	}
+
+	if err = addJoinedMember(tx, groupId, userId, sql.NullString{String: linkId, Valid: true}); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "transaction commit failed")
+	}
+
+	return groupId, nil
+}
+
+// usableGroupInviteLink renvoie le lien et son groupe, ou une erreur si le lien n'est plus utilisable.
+func usableGroupInviteLink(db *sql.DB, code string) (string, string, error) {
+	var linkId, groupId string
+	var usable bool
+	query := `SELECT l.ID, l.GROUP_ID, ` + usableInviteLink + ` FROM GROUP_INVITE_LINKS l WHERE l.CODE = ?`
+	err := db.QueryRow(query, code).Scan(&linkId, &groupId, &usable)
+	if err == sql.ErrNoRows {
+		return "", "", ErrInviteLinkNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if !usable {
+		return "", "", errors.New("this invite link has expired or been revoked")
+	}
+	return linkId, groupId, nil
+}
+
+const inviteLinkSelect = `SELECT l.ID, l.CODE, l.CREATED_BY, IFNULL(l.MAX_USES, 0), l.USES, IFNULL(l.EXPIRES_AT, ''),
+	IFNULL(l.REVOKED_AT, ''), l.CREATED_AT,
+	(SELECT COUNT(*) FROM GROUPS_MEMBERS m WHERE m.INVITE_LINK_ID = l.ID), ` + usableInviteLink + `
+	FROM GROUP_INVITE_LINKS l`
+
+func scanInviteLink(row interface{ Scan(...any) error }) (GroupInviteLink, error) {
+	var link GroupInviteLink
+	err := row.Scan(&link.ID, &link.Code, &link.CreatedBy, &link.MaxUses, &link.Uses, &link.ExpiresAt,
+		&link.RevokedAt, &link.CreatedAt, &link.Members, &link.Active)
+	return link, err
+}
+
+func groupInviteLink(db *sql.DB, linkId string) (GroupInviteLink, error) {
+	return scanInviteLink(db.QueryRow(inviteLinkSelect+` WHERE l.ID = ?`, linkId))
+}
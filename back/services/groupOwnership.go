@@ -0,0 +1,368 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// LeaveGroup retire l'utilisateur du groupe. Si c'est le propriétaire, la propriété passe
+// automatiquement à son successeur (voir nextGroupOwner) ; le dernier membre doit supprimer le groupe.
+func LeaveGroup(db *sql.DB, userId, groupId string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	var role string
+	query := `SELECT ROLE FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?`
+	err = tx.QueryRow(query, userId, groupId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return errors.New("user is not a member of the group")
+	}
+	if err != nil {
+		return err
+	}
+
+	if role == RoleOwner {
+		successor, err := nextGroupOwner(tx, groupId, userId)
+		if err != nil {
+			return err
+		}
+		if successor == "" {
+			return errors.New("you are the last member of this group: delete it instead")
+		}
+		if err = setGroupOwner(tx, groupId, successor); err != nil {
+			return err
+		}
+	}
+
+	if err = removeGroupMember(tx, userId, groupId); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// TransferGroupOwnership propose la propriété du groupe à un membre existant.
+// Le transfert n'a lieu qu'après acceptation ; une nouvelle proposition remplace la précédente.
+func TransferGroupOwnership(db *sql.DB, ownerId, groupId, targetId string) error {
+	if ownerId == targetId {
+		return errors.New("you already own this group")
+	}
+
+	role, err := groupRole(db, ownerId, groupId)
+	if err != nil {
+		return err
+	}
+	if role != RoleOwner {
+		return ErrGroupPermission
+	}
+
+	targetRole, err := groupRole(db, targetId, groupId)
+	if err != nil {
+		return err
+	}
+	if targetRole == "" {
+		return errors.New("user is not a member of the group")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	if err = deleteGroupTransfer(tx, groupId); err != nil {
+		return err
+	}
+
+	transferId := uuid.New().String()
+	query := `INSERT INTO GROUP_TRANSFERS(ID, GROUP_ID, FROM_ID, TO_ID, CREATED_AT) VALUES (?, ?, ?, ?, datetime('now'))`
+	_, err = tx.Exec(query, transferId, groupId, ownerId, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert group transfer")
+	}
+
+	query = `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE) VALUES (?, 'TRANSFER_GROUP', ?, ?)`
+	_, err = tx.Exec(query, uuid.New().String(), targetId, transferId)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert notification")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// AcceptGroupTransfer rend l'utilisateur propriétaire ; l'ancien propriétaire devient admin.
+func AcceptGroupTransfer(db *sql.DB, userId, groupId string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	var fromId string
+	query := `SELECT FROM_ID FROM GROUP_TRANSFERS WHERE GROUP_ID = ? AND TO_ID = ?`
+	err = tx.QueryRow(query, groupId, userId).Scan(&fromId)
+	if err == sql.ErrNoRows {
+		return errors.New("no pending ownership transfer for this group")
+	}
+	if err != nil {
+		return err
+	}
+
+	// Le propriétaire a pu changer ou le membre quitter le groupe depuis la proposition
+	var valid bool
+	query = `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1 AND USER_ID = ?2 AND ROLE = 'owner')
+	         AND EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1 AND USER_ID = ?3)`
+	err = tx.QueryRow(query, groupId, fromId, userId).Scan(&valid)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		if err = deleteGroupTransfer(tx, groupId); err != nil {
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return errors.Wrap(err, "transaction commit failed")
+		}
+		return errors.New("this ownership transfer is no longer valid")
+	}
+
+	_, err = tx.Exec(`UPDATE GROUPS_MEMBERS SET ROLE = ? WHERE GROUP_ID = ? AND USER_ID = ?`, RoleAdmin, groupId, fromId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update group role")
+	}
+	if err = setGroupOwner(tx, groupId, userId); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// CancelGroupTransfer annule le transfert en attente : refus du destinataire ou retrait du propriétaire.
+func CancelGroupTransfer(db *sql.DB, userId, groupId string) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUP_TRANSFERS WHERE GROUP_ID = ? AND (FROM_ID = ?2 OR TO_ID = ?2))`
+	err := db.QueryRow(query, groupId, userId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("no pending ownership transfer for this group")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	if err = deleteGroupTransfer(tx, groupId); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
+
+// ReassignOwnedGroups applique la succession sur tous les groupes dont l'utilisateur est propriétaire
+// et l'en retire ; à appeler avant la suppression de son compte (pas encore de route pour cela).
+// Un groupe sans autre membre passe par DeleteGroup, pour être archivé et purgé comme les autres.
+func ReassignOwnedGroups(db *sql.DB, userId string) error {
+	rows, err := db.Query(`SELECT GROUP_ID FROM GROUPS_MEMBERS WHERE USER_ID = ? AND ROLE = ?`, userId, RoleOwner)
+	if err != nil {
+		return err
+	}
+	var groups []string
+	for rows.Next() {
+		var groupId string
+		if err = rows.Scan(&groupId); err != nil {
+			rows.Close()
+			return err
+		}
+		groups = append(groups, groupId)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, groupId := range groups {
+		deleted, err := handOverGroup(db, userId, groupId)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			if err = DeleteGroup(db, userId, groupId); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// handOverGroup donne le groupe au successeur et en retire le propriétaire, en une transaction.
+// Renvoie true, sans rien modifier, s'il n'y a pas de successeur : le groupe est alors à supprimer.
+func handOverGroup(db *sql.DB, ownerId, groupId string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	successor, err := nextGroupOwner(tx, groupId, ownerId)
+	if err != nil {
+		return false, err
+	}
+	if successor == "" {
+		return true, nil
+	}
+
+	if err = setGroupOwner(tx, groupId, successor); err != nil {
+		return false, err
+	}
+	if err = removeGroupMember(tx, ownerId, groupId); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, errors.Wrap(err, "transaction commit failed")
+	}
+
+	return false, nil
+}
+
+// nextGroupOwner choisit le successeur : l'admin le plus ancien, sinon le membre le plus ancien.
+// Renvoie "" s'il ne reste aucun autre membre.
+func nextGroupOwner(tx *sql.Tx, groupId, ownerId string) (string, error) {
+	var successor string
+	query := `SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ? AND USER_ID != ?
+	          ORDER BY CASE ROLE WHEN 'admin' THEN 0 ELSE 1 END, CREATED_AT ASC, ID ASC LIMIT 1`
+	err := tx.QueryRow(query, groupId, ownerId).Scan(&successor)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find group successor")
+	}
+	return successor, nil
+}
+
+// setGroupOwner donne le rôle owner au membre et met ALL_GROUPS.OWNER à jour.
+// Le transfert en attente éventuel devient caduc.
+func setGroupOwner(tx *sql.Tx, groupId, userId string) error {
+	var previousOwner string
+	err := tx.QueryRow(`SELECT OWNER FROM ALL_GROUPS WHERE ID = ?`, groupId).Scan(&previousOwner)
+	if err != nil {
+		return errors.Wrap(err, "failed to get group owner")
+	}
+	_, err = tx.Exec(`UPDATE GROUPS_MEMBERS SET ROLE = ? WHERE GROUP_ID = ? AND USER_ID = ?`, RoleOwner, groupId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update group role")
+	}
+	_, err = tx.Exec(`UPDATE ALL_GROUPS SET OWNER = ? WHERE ID = ?`, userId, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update group owner")
+	}
+	if err = reassignJoinRequests(tx, groupId, previousOwner); err != nil {
+		return err
+	}
+	return deleteGroupTransfer(tx, groupId)
+}
+
+// reassignJoinRequests adresse au propriétaire actuel les demandes d'adhésion en attente reçues
+// par un ancien propriétaire. Une demande se distingue d'une invitation par son ASKER, qui n'est pas membre.
+func reassignJoinRequests(tx *sql.Tx, groupId, fromId string) error {
+	query := `UPDATE ASK_GROUP SET RECEIVER = (SELECT OWNER FROM ALL_GROUPS WHERE ID = ?1)
+	          WHERE GROUP_ID = ?1 AND RECEIVER = ?2 AND ACCEPTED = 0
+	            AND ASKER NOT IN (SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1)`
+	if _, err := tx.Exec(query, groupId, fromId); err != nil {
+		return errors.Wrap(err, "failed to reassign join requests")
+	}
+	return nil
+}
+
+// removeGroupMember retire le membre ainsi que ses demandes et invitations pour ce groupe,
+// pour qu'il puisse à nouveau demander à rejoindre ou être invité. Les demandes d'adhésion
+// qu'il a reçues en tant qu'ancien propriétaire passent au propriétaire actuel.
+func removeGroupMember(tx *sql.Tx, userId, groupId string) error {
+	_, err := tx.Exec(`DELETE FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?`, userId, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group member")
+	}
+
+	if err = reassignJoinRequests(tx, groupId, userId); err != nil {
+		return err
+	}
+
+	// Ses propres demandes, et les invitations qui lui ont été adressées
+	mine := `SELECT ID FROM ASK_GROUP WHERE GROUP_ID = ?1 AND (ASKER = ?2 OR (RECEIVER = ?2 AND ASKER <> ?2
+	           AND RECEIVER <> (SELECT OWNER FROM ALL_GROUPS WHERE ID = ?1)))`
+
+	query := `DELETE FROM NOTIFICATIONS WHERE TYPE IN ('INVITE_GROUP', 'ASK_GROUP') AND ID_TYPE IN (` + mine + `)`
+	_, err = tx.Exec(query, groupId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group invite notifications")
+	}
+	query = `DELETE FROM GROUP_ANSWERS WHERE ASK_ID IN (` + mine + `)`
+	_, err = tx.Exec(query, groupId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete answers")
+	}
+	_, err = tx.Exec(`DELETE FROM ASK_GROUP WHERE ID IN (`+mine+`)`, groupId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group invites")
+	}
+
+	if err = deleteMemberRSVPs(tx, userId, groupId); err != nil {
+		return err
+	}
+
+	query = `DELETE FROM GROUP_CHANNEL_UNSUBSCRIBED WHERE USER_ID = ? AND CHANNEL_ID IN (SELECT ID FROM GROUP_CHANNELS WHERE GROUP_ID = ?)`
+	if _, err = tx.Exec(query, userId, groupId); err != nil {
+		return errors.Wrap(err, "failed to delete channel subscriptions")
+	}
+
+	// Un transfert proposé au membre qui part n'a plus lieu d'être
+	var pendingTo bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM GROUP_TRANSFERS WHERE GROUP_ID = ? AND TO_ID = ?)`, groupId, userId).Scan(&pendingTo)
+	if err != nil {
+		return err
+	}
+	if pendingTo {
+		return deleteGroupTransfer(tx, groupId)
+	}
+	return nil
+}
+
+// deleteGroupTransfer supprime le transfert en attente du groupe et sa notification.
+func deleteGroupTransfer(tx *sql.Tx, groupId string) error {
+	query := `DELETE FROM NOTIFICATIONS WHERE TYPE = 'TRANSFER_GROUP' AND ID_TYPE IN (SELECT ID FROM GROUP_TRANSFERS WHERE GROUP_ID = ?)`
+	_, err := tx.Exec(query, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group transfer notification")
+	}
+	_, err = tx.Exec(`DELETE FROM GROUP_TRANSFERS WHERE GROUP_ID = ?`, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group transfer")
+	}
+	return nil
+}
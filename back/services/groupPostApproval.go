@@ -0,0 +1,218 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const (
+	PostStatusPublished = "published"
+	PostStatusPending   = "pending"  // en attente de validation dans un groupe modéré
+	PostStatusRejected  = "rejected" // refusé, visible de son seul auteur avec le motif
+)
+
+const maxRejectReasonLength = 500
+
+var ErrPostNotFound = errors.New("post not found")
+
+type GroupPostDecision struct {
+	PostID     string `json:"post_id"`
+	GroupID    string `json:"group_id"`
+	GroupName  string `json:"group_name"`
+	GroupPic   string `json:"group_pic"`
+	Content    string `json:"content"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason"`
+	ReviewedAt string `json:"reviewed_at"`
+}
+
+// newGroupPostStatus renvoie le statut d'un nouveau post du groupe : en attente si le groupe valide
+// les posts, sauf pour les modérateurs et les membres de confiance.
+func newGroupPostStatus(db *sql.DB, userId, groupId string) (string, error) {
+	var role string
+	var approval, trusted bool
+	query := `SELECT m.ROLE, g.POST_APPROVAL, m.TRUSTED FROM GROUPS_MEMBERS m JOIN ALL_GROUPS g ON g.ID = m.GROUP_ID
+	          WHERE m.USER_ID = ? AND m.GROUP_ID = ?`
+	err := db.QueryRow(query, userId, groupId).Scan(&role, &approval, &trusted)
+	if err == sql.ErrNoRows {
+		return "", errors.New("user is not member of group")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = checkGroupRulesAccepted(db, userId, groupId); err != nil {
+		return "", err
+	}
+
+	if approval && !trusted && !hasGroupPermission(role, PermModerateContent) {
+		return PostStatusPending, nil
+	}
+	return PostStatusPublished, nil
+}
+
+// canSeePost indique si un post non publié reste visible de l'utilisateur : son auteur,
+// et les modérateurs du groupe tant qu'il est en attente. Un post publié ne change rien aux autres règles.
+func canSeePost(db *sql.DB, userId, postId string) (bool, error) {
+	var authorId, status string
+	var groupId sql.NullString
+	err := db.QueryRow(`SELECT USER_ID, GROUP_ID, STATUS FROM POSTS WHERE ID = ?`, postId).Scan(&authorId, &groupId, &status)
+	if err != nil {
+		return false, err
+	}
+	if status == PostStatusPublished || authorId == userId {
+		return true, nil
+	}
+	if status == PostStatusPending && groupId.Valid {
+		return canModerateGroupContent(db, userId, groupId.String)
+	}
+	return false, nil
+}
+
+// checkPostPublished refuse les réactions et commentaires sur un post qui n'est pas publié.
+func checkPostPublished(db *sql.DB, postId string) error {
+	var status string
+	err := db.QueryRow(`SELECT STATUS FROM POSTS WHERE ID = ?`, postId).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != PostStatusPublished {
+		return errors.New("this post is awaiting approval")
+	}
+	return nil
+}
+
+// SetGroupPostApproval active ou désactive la validation des posts. Les posts déjà en attente
+// restent dans la file jusqu'à leur validation ou leur refus.
+func SetGroupPostApproval(db *sql.DB, userId, groupId string, enabled bool) error {
+	if _, err := checkGroupPermission(db, userId, groupId, PermEditGroup); err != nil {
+		return err
+	}
+
+	_, err := db.Exec(`UPDATE ALL_GROUPS SET POST_APPROVAL = ? WHERE ID = ?`, enabled, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update group")
+	}
+	return nil
+}
+
+// SetGroupMemberTrusted marque un membre de confiance : ses posts sont publiés sans validation.
+func SetGroupMemberTrusted(db *sql.DB, actorId, groupId, targetId string, trusted bool) error {
+	if _, err := checkGroupPermission(db, actorId, groupId, PermManageRoles); err != nil {
+		return err
+	}
+
+	res, err := db.Exec(`UPDATE GROUPS_MEMBERS SET TRUSTED = ? WHERE USER_ID = ? AND GROUP_ID = ?`, trusted, targetId, groupId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update group member")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("no matching group member found")
+	}
+	return nil
+}
+
+// ListPendingGroupPosts renvoie la file de validation du groupe, du plus ancien au plus récent.
+func ListPendingGroupPosts(db *sql.DB, userId, groupId string) ([]PostProfile, error) {
+	if _, err := checkGroupPermission(db, userId, groupId, PermModerateContent); err != nil {
+		return nil, err
+	}
+	return groupPosts(db, userId, groupId, `STATUS = 'pending' ORDER BY CREATED_AT ASC`)
+}
+
+// ApproveGroupPost publie un post en attente et en avertit l'auteur.
+func ApproveGroupPost(db *sql.DB, userId, postId string) error {
+	return reviewGroupPost(db, userId, postId, PostStatusPublished, "")
+}
+
+// RejectGroupPost refuse un post en attente avec un motif, transmis à l'auteur.
+func RejectGroupPost(db *sql.DB, userId, postId, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("missing reject reason")
+	}
+	if len([]rune(reason)) > maxRejectReasonLength {
+		return errors.Errorf("reject reason too long (max %d characters)", maxRejectReasonLength)
+	}
+	return reviewGroupPost(db, userId, postId, PostStatusRejected, reason)
+}
+
+func reviewGroupPost(db *sql.DB, userId, postId, status, reason string) error {
+	var authorId, content, current string
+	var groupId sql.NullString
+	query := `SELECT USER_ID, GROUP_ID, CONTENT, STATUS FROM POSTS WHERE ID = ?`
+	err := db.QueryRow(query, postId).Scan(&authorId, &groupId, &content, &current)
+	if err == sql.ErrNoRows || (err == nil && !groupId.Valid) {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = checkGroupPermission(db, userId, groupId.String, PermModerateContent); err != nil {
+		return err
+	}
+	if current != PostStatusPending {
+		return errors.New("this post is not awaiting approval")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	// Le statut attendu protège contre deux décisions simultanées
+	query = `UPDATE POSTS SET STATUS = ?, REVIEWED_BY = ?, REVIEWED_AT = datetime('now'), REJECT_REASON = ?
+	         WHERE ID = ? AND STATUS = 'pending'`
+	res, err := tx.Exec(query, status, userId, toNullString(reason), postId)
+	if err != nil {
+		return errors.Wrap(err, "failed to update post")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("this post is not awaiting approval")
+	}
+
+	notifType := "GROUP_POST_APPROVED"
+	if status == PostStatusRejected {
+		notifType = "GROUP_POST_REJECTED"
+	}
+	if authorId != userId {
+		query = `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE) VALUES (?, ?, ?, ?)`
+		if _, err = tx.Exec(query, uuid.New().String(), notifType, authorId, postId); err != nil {
+			return errors.Wrap(err, "failed to insert notification")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	// Les mentions ne comptent qu'une fois le post publié
+	if status == PostStatusPublished {
+		return savePostMentions(db, postId, authorId, content)
+	}
+	return nil
+}
+
+func groupPostDecision(db *sql.DB, postId string) (GroupPostDecision, error) {
+	var d GroupPostDecision
+	var groupPic sql.NullString
+
+	query := `SELECT p.ID, p.GROUP_ID, g.TITLE, g.IMAGE, p.CONTENT, p.STATUS, IFNULL(p.REJECT_REASON, ''), IFNULL(p.REVIEWED_AT, '')
+	          FROM POSTS p JOIN ALL_GROUPS g ON g.ID = p.GROUP_ID WHERE p.ID = ?`
+	err := db.QueryRow(query, postId).Scan(&d.PostID, &d.GroupID, &d.GroupName, &groupPic, &d.Content, &d.Status, &d.Reason, &d.ReviewedAt)
+	if err != nil {
+		return d, err
+	}
+	if groupPic.Valid {
+		d.GroupPic = groupPic.String
+	}
+
+	return d, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+)
+
+const (
+	RoleOwner     = "owner"
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleMember    = "member"
+)
+
+const (
+	PermEditGroup       = "edit_group"
+	PermDeleteGroup     = "delete_group"
+	PermManageRequests  = "manage_requests"  // voir et accepter les demandes d'adhésion
+	PermRemoveMember    = "remove_member"    // retirer un membre de rang inférieur
+	PermManageRoles     = "manage_roles"     // promouvoir / rétrograder un membre de rang inférieur
+	PermModerateContent = "moderate_content" // supprimer posts, commentaires et messages du groupe
+	PermInvite          = "invite"
+	PermAnnounce        = "announce"   // publier dans les annonces du groupe
+	PermViewStats       = "view_stats" // consulter et exporter les statistiques d'activité
+)
+
+// Matrice des permissions par rôle
+var groupPermissions = map[string]map[string]bool{
+	RoleOwner: {
+		PermEditGroup: true, PermDeleteGroup: true, PermManageRequests: true, PermRemoveMember: true,
+		PermManageRoles: true, PermModerateContent: true, PermInvite: true, PermAnnounce: true, PermViewStats: true,
+	},
+	RoleAdmin: {
+		PermEditGroup: true, PermManageRequests: true, PermRemoveMember: true,
+		PermManageRoles: true, PermModerateContent: true, PermInvite: true, PermAnnounce: true, PermViewStats: true,
+	},
+	RoleModerator: {
+		PermModerateContent: true, PermInvite: true,
+	},
+	RoleMember: {
+		PermInvite: true,
+	},
+}
+
+// Ordre des rôles : on n'agit que sur les membres de rang strictement inférieur
+var roleRank = map[string]int{
+	RoleMember:    1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+	RoleOwner:     4,
+}
+
+// Rôles attribuables via promotion / rétrogradation, du plus bas au plus haut.
+// Le rôle owner ne s'obtient que par transfert de propriété.
+var assignableRoles = []string{RoleMember, RoleModerator, RoleAdmin}
+
+var ErrGroupPermission = errors.New("you do not have permission to do this in this group")
+
+// groupRole renvoie le rôle de l'utilisateur dans le groupe, ou "" s'il n'est pas membre.
+func groupRole(db *sql.DB, userId, groupId string) (string, error) {
+	var role string
+	query := `SELECT ROLE FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?`
+	err := db.QueryRow(query, userId, groupId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+func hasGroupPermission(role, perm string) bool {
+	return groupPermissions[role][perm]
+}
+
+// groupRolePermissions renvoie la liste des permissions d'un rôle, pour le front.
+func groupRolePermissions(role string) []string {
+	var perms []string
+	for _, perm := range []string{PermEditGroup, PermDeleteGroup, PermManageRequests, PermRemoveMember,
+		PermManageRoles, PermModerateContent, PermInvite, PermAnnounce, PermViewStats} {
+		if hasGroupPermission(role, perm) {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// checkGroupMember renvoie le rôle de l'utilisateur, ou une erreur s'il n'est pas membre du groupe.
+func checkGroupMember(db *sql.DB, userId, groupId string) (string, error) {
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get group role")
+	}
+	if role == "" {
+		return "", errors.New("user is not a member of the group")
+	}
+	return role, nil
+}
+
+// checkGroupPermission renvoie le rôle de l'utilisateur s'il dispose de la permission dans le groupe.
+func checkGroupPermission(db *sql.DB, userId, groupId, perm string) (string, error) {
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get group role")
+	}
+	if role == "" {
+		return "", errors.New("user is not a member of the group")
+	}
+	if !hasGroupPermission(role, perm) {
+		return "", ErrGroupPermission
+	}
+	return role, nil
+}
+
+// checkOutranks vérifie que l'acteur a la permission donnée et un rang supérieur à celui de la cible.
+// Renvoie les rôles de l'acteur et de la cible.
+func checkOutranks(db *sql.DB, actorId, targetId, groupId, perm string) (string, string, error) {
+	if actorId == targetId {
+		return "", "", errors.New("you cannot do this on yourself")
+	}
+
+	actorRole, err := checkGroupPermission(db, actorId, groupId, perm)
+	if err != nil {
+		return "", "", err
+	}
+
+	targetRole, err := groupRole(db, targetId, groupId)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to get group role")
+	}
+	if targetRole == "" {
+		return "", "", errors.New("no matching group member found")
+	}
+	if roleRank[actorRole] <= roleRank[targetRole] {
+		return "", "", ErrGroupPermission
+	}
+
+	return actorRole, targetRole, nil
+}
+
+// PromoteGroupMember élève le rôle d'un membre. Sans rôle précisé, le membre monte d'un rang.
+func PromoteGroupMember(db *sql.DB, actorId, groupId, targetId, role string) (string, error) {
+	return changeGroupMemberRole(db, actorId, groupId, targetId, role, 1)
+}
+
+// DemoteGroupMember abaisse le rôle d'un membre. Sans rôle précisé, le membre descend d'un rang.
+func DemoteGroupMember(db *sql.DB, actorId, groupId, targetId, role string) (string, error) {
+	return changeGroupMemberRole(db, actorId, groupId, targetId, role, -1)
+}
+
+// changeGroupMemberRole applique une promotion (direction 1) ou une rétrogradation (-1).
+// Le nouveau rôle doit rester strictement inférieur à celui de l'acteur : un admin peut nommer
+// des modérateurs, seul le propriétaire peut nommer des admins.
+func changeGroupMemberRole(db *sql.DB, actorId, groupId, targetId, role string, direction int) (string, error) {
+	actorRole, targetRole, err := checkOutranks(db, actorId, targetId, groupId, PermManageRoles)
+	if err != nil {
+		return "", err
+	}
+
+	if role == "" {
+		for i, r := range assignableRoles {
+			if r == targetRole && i+direction >= 0 && i+direction < len(assignableRoles) {
+				role = assignableRoles[i+direction]
+			}
+		}
+		if role == "" {
+			return "", errors.Errorf("member cannot be moved further from role %s", targetRole)
+		}
+	}
+
+	if _, ok := roleRank[role]; !ok || role == RoleOwner {
+		return "", errors.New("invalid role (admin, moderator or member)")
+	}
+	if (roleRank[role]-roleRank[targetRole])*direction <= 0 {
+		if direction > 0 {
+			return "", errors.Errorf("role %s is not a promotion from %s", role, targetRole)
+		}
+		return "", errors.Errorf("role %s is not a demotion from %s", role, targetRole)
+	}
+	if roleRank[role] >= roleRank[actorRole] {
+		return "", ErrGroupPermission
+	}
+
+	query := `UPDATE GROUPS_MEMBERS SET ROLE = ? WHERE USER_ID = ? AND GROUP_ID = ?`
+	_, err = db.Exec(query, role, targetId, groupId)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to update group role")
+	}
+
+	return role, nil
+}
+
+// canModerateGroupContent indique si l'utilisateur peut supprimer le contenu des autres membres du groupe.
+func canModerateGroupContent(db *sql.DB, userId, groupId string) (bool, error) {
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return false, err
+	}
+	return hasGroupPermission(role, PermModerateContent), nil
+}
+
+// DeleteGroupMessage supprime un message de groupe : par son auteur ou par un modérateur du groupe.
+func DeleteGroupMessage(db *sql.DB, userId, messageId string) error {
+	var senderId string
+	var groupId sql.NullString
+	query := `SELECT SENDER_ID, GROUP_ID FROM MESSAGES WHERE ID = ?`
+	err := db.QueryRow(query, messageId).Scan(&senderId, &groupId)
+	if err == sql.ErrNoRows || (err == nil && !groupId.Valid) {
+		return errors.New("group message not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if senderId != userId {
+		canModerate, err := canModerateGroupContent(db, userId, groupId.String)
+		if err != nil {
+			return err
+		}
+		if !canModerate {
+			return ErrGroupPermission
+		}
+	}
+
+	_, err = db.Exec(`DELETE FROM MESSAGES WHERE ID = ?`, messageId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete group message")
+	}
+
+	return nil
+}
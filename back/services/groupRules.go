@@ -0,0 +1,266 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const maxGroupRulesLength = 5000
+
+var (
+	ErrGroupRulesNotAccepted = errors.New("you must accept the group rules before posting")
+	ErrGroupRulesChanged     = errors.New("the group rules have changed, please read them again")
+)
+
+type GroupRules struct {
+	GroupID   string `json:"group_id"`
+	Version   int    `json:"version"` // 0 : le groupe n'a jamais eu de règlement
+	Content   string `json:"content"` // vide : pas de règlement en vigueur
+	UpdatedBy User   `json:"updated_by"`
+	UpdatedAt string `json:"updated_at"`
+	Accepted  bool   `json:"accepted"` // faux tant que le membre n'a pas accepté la version en vigueur
+}
+
+// mustAccept indique si un membre ayant accepté la version accepted doit encore accepter le règlement.
+func (r GroupRules) mustAccept(accepted int) bool {
+	return r.Content != "" && accepted < r.Version
+}
+
+// currentGroupRules renvoie la dernière version du règlement, version 0 si le groupe n'en a jamais eu.
+func currentGroupRules(db *sql.DB, groupId string) (GroupRules, error) {
+	r := GroupRules{GroupID: groupId}
+	var createdBy string
+	query := `SELECT VERSION, CONTENT, CREATED_BY, CREATED_AT FROM GROUP_RULES WHERE GROUP_ID = ? ORDER BY VERSION DESC LIMIT 1`
+	err := db.QueryRow(query, groupId).Scan(&r.Version, &r.Content, &createdBy, &r.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return r, nil
+	}
+	if err != nil {
+		return r, err
+	}
+
+	r.UpdatedBy, err = getUserByID(db, createdBy)
+	return r, err
+}
+
+// acceptedRulesVersion renvoie la version du règlement acceptée par le membre, 0 pour un non-membre.
+func acceptedRulesVersion(db *sql.DB, userId, groupId string) (int, error) {
+	var version int
+	err := db.QueryRow(`SELECT RULES_VERSION FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?`, userId, groupId).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+// checkGroupRulesAccepted refuse de publier à un membre qui n'a pas accepté le règlement en vigueur.
+func checkGroupRulesAccepted(db *sql.DB, userId, groupId string) error {
+	rules, err := currentGroupRules(db, groupId)
+	if err != nil {
+		return err
+	}
+	accepted, err := acceptedRulesVersion(db, userId, groupId)
+	if err != nil {
+		return err
+	}
+	if rules.mustAccept(accepted) {
+		return ErrGroupRulesNotAccepted
+	}
+	return nil
+}
+
+// GetGroupRules renvoie le règlement en vigueur, lisible de tous ceux qui voient le groupe :
+// un futur membre le découvre avant de rejoindre.
+func GetGroupRules(db *sql.DB, userId, groupId string) (GroupRules, error) {
+	if err := canSeeGroup(db, userId, groupId); err != nil {
+		return GroupRules{}, err
+	}
+
+	rules, err := currentGroupRules(db, groupId)
+	if err != nil {
+		return rules, err
+	}
+	accepted, err := acceptedRulesVersion(db, userId, groupId)
+	if err != nil {
+		return rules, err
+	}
+	rules.Accepted = !rules.mustAccept(accepted)
+
+	return rules, nil
+}
+
+// GroupRulesHistory renvoie toutes les versions du règlement, de la plus récente à la plus ancienne.
+func GroupRulesHistory(db *sql.DB, userId, groupId string) ([]GroupRules, error) {
+	if err := canSeeGroup(db, userId, groupId); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT VERSION, CONTENT, CREATED_BY, CREATED_AT FROM GROUP_RULES WHERE GROUP_ID = ? ORDER BY VERSION DESC`
+	rows, err := db.Query(query, groupId)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []GroupRules
+	var authors []string
+	for rows.Next() {
+		r := GroupRules{GroupID: groupId}
+		var createdBy string
+		if err = rows.Scan(&r.Version, &r.Content, &createdBy, &r.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		history = append(history, r)
+		authors = append(authors, createdBy)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range history {
+		if history[i].UpdatedBy, err = getUserByID(db, authors[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return history, nil
+}
+
+// SetGroupRules publie une nouvelle version du règlement ; un contenu vide retire le règlement.
+// Les membres doivent accepter la nouvelle version avant de publier à nouveau, son auteur l'accepte d'office.
+func SetGroupRules(db *sql.DB, userId, groupId, content string) (GroupRules, error) {
+	if _, err := checkGroupPermission(db, userId, groupId, PermEditGroup); err != nil {
+		return GroupRules{}, err
+	}
+
+	content = strings.TrimSpace(content)
+	if len([]rune(content)) > maxGroupRulesLength {
+		return GroupRules{}, errors.Errorf("rules too long (max %d characters)", maxGroupRulesLength)
+	}
+
+	current, err := currentGroupRules(db, groupId)
+	if err != nil {
+		return GroupRules{}, err
+	}
+	if content == current.Content {
+		return GroupRules{}, errors.New("rules unchanged")
+	}
+	version := current.Version + 1
+
+	tx, err := db.Begin()
+	if err != nil {
+		return GroupRules{}, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	id := uuid.New().String()
+	query := `INSERT INTO GROUP_RULES(ID, GROUP_ID, VERSION, CONTENT, CREATED_BY, CREATED_AT) VALUES (?, ?, ?, ?, ?, datetime('now'))`
+	if _, err = tx.Exec(query, id, groupId, version, content, userId); err != nil {
+		return GroupRules{}, errors.Wrap(err, "failed to insert group rules")
+	}
+
+	query = `UPDATE GROUPS_MEMBERS SET RULES_VERSION = ? WHERE USER_ID = ? AND GROUP_ID = ?`
+	if _, err = tx.Exec(query, version, userId, groupId); err != nil {
+		return GroupRules{}, errors.Wrap(err, "failed to update group member")
+	}
+
+	// Règlement retiré : plus rien à accepter, donc rien à notifier
+	if content != "" {
+		if err = notifyAllGroupMembers(tx, groupId, userId, "GROUP_RULES_UPDATED", id); err != nil {
+			return GroupRules{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return GroupRules{}, errors.Wrap(err, "transaction commit failed")
+	}
+
+	rules, err := currentGroupRules(db, groupId)
+	rules.Accepted = true
+	return rules, err
+}
+
+// AcceptGroupRules enregistre l'acceptation du règlement. version est celle que le membre a lue :
+// si le règlement a changé entre-temps, il doit relire la nouvelle version.
+func AcceptGroupRules(db *sql.DB, userId, groupId string, version int) error {
+	role, err := groupRole(db, userId, groupId)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return errors.New("user is not member of group")
+	}
+
+	current, err := currentGroupRules(db, groupId)
+	if err != nil {
+		return err
+	}
+	if current.Content == "" {
+		return errors.New("this group has no rules")
+	}
+	if version != current.Version {
+		return ErrGroupRulesChanged
+	}
+
+	query := `UPDATE GROUPS_MEMBERS SET RULES_VERSION = ? WHERE USER_ID = ? AND GROUP_ID = ? AND RULES_VERSION < ?`
+	if _, err = db.Exec(query, version, userId, groupId, version); err != nil {
+		return errors.Wrap(err, "failed to update group member")
+	}
+	return nil
+}
+
+// notifyAllGroupMembers notifie tous les membres sauf l'auteur, sans tenir compte des sourdines du groupe :
+// annonces et changements de règlement doivent parvenir à tous.
+func notifyAllGroupMembers(tx *sql.Tx, groupId, senderId, notifType, idType string) error {
+	rows, err := tx.Query(`SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ? AND USER_ID <> ?`, groupId, senderId)
+	if err != nil {
+		return err
+	}
+	var members []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		members = append(members, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO NOTIFICATIONS(ID, TYPE, USER_ID, ID_TYPE) VALUES (?, ?, ?, ?)`
+	for _, member := range members {
+		if _, err = tx.Exec(query, uuid.New().String(), notifType, member, idType); err != nil {
+			return errors.Wrap(err, "failed to insert notification")
+		}
+	}
+	return nil
+}
+
+type GroupRulesNotification struct {
+	GroupID   string `json:"group_id"`
+	GroupName string `json:"group_name"`
+	GroupPic  string `json:"group_pic"`
+	Version   int    `json:"version"`
+}
+
+// groupRulesNotification : données de la notification GROUP_RULES_UPDATED (idType = ID de la version).
+func groupRulesNotification(db *sql.DB, rulesId string) (GroupRulesNotification, error) {
+	var n GroupRulesNotification
+	var groupPic sql.NullString
+	query := `SELECT r.GROUP_ID, g.TITLE, g.IMAGE, r.VERSION FROM GROUP_RULES r JOIN ALL_GROUPS g ON g.ID = r.GROUP_ID WHERE r.ID = ?`
+	err := db.QueryRow(query, rulesId).Scan(&n.GroupID, &n.GroupName, &groupPic, &n.Version)
+	if err != nil {
+		return n, err
+	}
+	if groupPic.Valid {
+		n.GroupPic = groupPic.String
+	}
+
+	return n, nil
+}
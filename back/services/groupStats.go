@@ -0,0 +1,199 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	DefaultGroupStatsDays = 30
+	MaxGroupStatsDays     = 366
+)
+
+type GroupStatsDay struct {
+	Date             string `json:"date"`
+	ActiveMembers    int    `json:"active_members"` // auteurs d'un post, commentaire, réaction, message ou RSVP
+	Posts            int    `json:"posts"`
+	Comments         int    `json:"comments"`
+	Messages         int    `json:"messages"`
+	RSVPs            int    `json:"rsvps"`
+	RequestsAccepted int    `json:"requests_accepted"`
+	RequestsDeclined int    `json:"requests_declined"`
+	NewMembers       int    `json:"new_members"`
+	Members          int    `json:"members"` // membres actuels ayant rejoint au plus tard ce jour
+}
+
+type GroupStatsTotals struct {
+	ActiveMembers    int `json:"active_members"` // distincts sur la période
+	Posts            int `json:"posts"`
+	Comments         int `json:"comments"`
+	Messages         int `json:"messages"`
+	RSVPs            int `json:"rsvps"`
+	RequestsAccepted int `json:"requests_accepted"`
+	RequestsDeclined int `json:"requests_declined"`
+	NewMembers       int `json:"new_members"`
+}
+
+type GroupStats struct {
+	GroupID string           `json:"group_id"`
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Members int              `json:"members"`
+	Days    []GroupStatsDay  `json:"days"`
+	Totals  GroupStatsTotals `json:"totals"`
+}
+
+// Activité des membres dans le groupe (?1 = groupe) : une ligne par action, avec son auteur et son jour
+const groupActivity = `
+	SELECT USER_ID, date(CREATED_AT) AS DAY FROM POSTS WHERE GROUP_ID = ?1 AND STATUS = 'published'
+	UNION ALL SELECT c.USER_ID, date(c.CREATED) FROM COMMENT c JOIN POSTS p ON p.ID = c.POST_ID WHERE p.GROUP_ID = ?1
+	UNION ALL SELECT e.USER_ID, date(COALESCE(e.UPDATE_AT, e.CREATED_AT)) FROM POST_EVENT e JOIN POSTS p ON p.ID = e.POST_ID
+	          WHERE p.GROUP_ID = ?1 AND e.LIKED IS NOT NULL
+	UNION ALL SELECT SENDER_ID, date(CREATED_AT) FROM MESSAGES WHERE GROUP_ID = ?1
+	UNION ALL SELECT r.USER_ID, date(r.UPDATED_AT) FROM EVENT_RSVPS r JOIN GROUPS_EVENT ev ON ev.ID = r.EVENT_ID
+	          WHERE ev.GROUP_ID = ?1`
+
+// SendGroupStats renvoie aux propriétaires et admins l'activité du groupe jour par jour, du from au to
+// inclus (AAAA-MM-JJ, UTC). Par défaut : les 30 derniers jours.
+func SendGroupStats(db *sql.DB, userId, groupId, from, to string) (GroupStats, error) {
+	stats := GroupStats{GroupID: groupId}
+
+	if _, err := checkGroupPermission(db, userId, groupId, PermViewStats); err != nil {
+		return stats, err
+	}
+
+	now := time.Now().UTC()
+	toDate, fromDate := now, now.AddDate(0, 0, -(DefaultGroupStatsDays-1))
+	var err error
+	if to != "" {
+		if toDate, err = time.Parse("2006-01-02", to); err != nil {
+			return stats, errors.New("invalid to date (YYYY-MM-DD)")
+		}
+		fromDate = toDate.AddDate(0, 0, -(DefaultGroupStatsDays - 1))
+	}
+	if from != "" {
+		if fromDate, err = time.Parse("2006-01-02", from); err != nil {
+			return stats, errors.New("invalid from date (YYYY-MM-DD)")
+		}
+	}
+	if fromDate.After(toDate) {
+		return stats, errors.New("from must be before to")
+	}
+
+	stats.From = fromDate.Format("2006-01-02")
+	stats.To = toDate.Format("2006-01-02")
+
+	index := make(map[string]int)
+	for d := fromDate; !d.After(toDate); d = d.AddDate(0, 0, 1) {
+		if len(stats.Days) == MaxGroupStatsDays {
+			return stats, errors.Errorf("date range too long (max %d days)", MaxGroupStatsDays)
+		}
+		date := d.Format("2006-01-02")
+		index[date] = len(stats.Days)
+		stats.Days = append(stats.Days, GroupStatsDay{Date: date})
+	}
+
+	err = db.QueryRow(`SELECT COUNT(*) FROM GROUPS_MEMBERS WHERE GROUP_ID = ?`, groupId).Scan(&stats.Members)
+	if err != nil {
+		return stats, err
+	}
+
+	// Chaque série renvoie (jour, nombre) entre ?2 et ?3
+	series := []struct {
+		query string
+		set   func(day *GroupStatsDay, count int)
+	}{
+		{
+			query: `SELECT DAY, COUNT(DISTINCT USER_ID) FROM (` + groupActivity + `)
+			        WHERE DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.ActiveMembers = count },
+		},
+		{
+			query: `SELECT date(CREATED_AT) AS DAY, COUNT(*) FROM POSTS
+			        WHERE GROUP_ID = ?1 AND STATUS = 'published' AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.Posts = count },
+		},
+		{
+			query: `SELECT date(c.CREATED) AS DAY, COUNT(*) FROM COMMENT c JOIN POSTS p ON p.ID = c.POST_ID
+			        WHERE p.GROUP_ID = ?1 AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.Comments = count },
+		},
+		{
+			query: `SELECT date(CREATED_AT) AS DAY, COUNT(*) FROM MESSAGES
+			        WHERE GROUP_ID = ?1 AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.Messages = count },
+		},
+		{
+			query: `SELECT date(r.CREATED_AT) AS DAY, COUNT(*) FROM EVENT_RSVPS r JOIN GROUPS_EVENT ev ON ev.ID = r.EVENT_ID
+			        WHERE ev.GROUP_ID = ?1 AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.RSVPs = count },
+		},
+		{
+			query: `SELECT date(CREATED_AT) AS DAY, COUNT(*) FROM GROUP_JOIN_DECISIONS
+			        WHERE GROUP_ID = ?1 AND ACCEPTED = 1 AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.RequestsAccepted = count },
+		},
+		{
+			query: `SELECT date(CREATED_AT) AS DAY, COUNT(*) FROM GROUP_JOIN_DECISIONS
+			        WHERE GROUP_ID = ?1 AND ACCEPTED = 0 AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.RequestsDeclined = count },
+		},
+		{
+			query: `SELECT date(CREATED_AT) AS DAY, COUNT(*) FROM GROUPS_MEMBERS
+			        WHERE GROUP_ID = ?1 AND DAY BETWEEN ?2 AND ?3 GROUP BY DAY`,
+			set: func(day *GroupStatsDay, count int) { day.NewMembers = count },
+		},
+	}
+
+	for _, s := range series {
+		rows, err := db.Query(s.query, groupId, stats.From, stats.To)
+		if err != nil {
+			return stats, err
+		}
+		for rows.Next() {
+			var date string
+			var count int
+			if err = rows.Scan(&date, &count); err != nil {
+				rows.Close()
+				return stats, err
+			}
+			if i, ok := index[date]; ok {
+				s.set(&stats.Days[i], count)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return stats, err
+		}
+	}
+
+	// Les départs ne sont pas historisés : la croissance est celle des membres actuels, par date d'adhésion
+	var members int
+	query := `SELECT COUNT(*) FROM GROUPS_MEMBERS WHERE GROUP_ID = ? AND date(CREATED_AT) < ?`
+	if err = db.QueryRow(query, groupId, stats.From).Scan(&members); err != nil {
+		return stats, err
+	}
+
+	for i := range stats.Days {
+		d := &stats.Days[i]
+		members += d.NewMembers
+		d.Members = members
+
+		stats.Totals.Posts += d.Posts
+		stats.Totals.Comments += d.Comments
+		stats.Totals.Messages += d.Messages
+		stats.Totals.RSVPs += d.RSVPs
+		stats.Totals.RequestsAccepted += d.RequestsAccepted
+		stats.Totals.RequestsDeclined += d.RequestsDeclined
+		stats.Totals.NewMembers += d.NewMembers
+	}
+
+	query = `SELECT COUNT(DISTINCT USER_ID) FROM (` + groupActivity + `) WHERE DAY BETWEEN ?2 AND ?3`
+	err = db.QueryRow(query, groupId, stats.From, stats.To).Scan(&stats.Totals.ActiveMembers)
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
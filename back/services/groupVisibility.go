@@ -0,0 +1,74 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+)
+
+const (
+	GroupPublic = "public" // adhésion immédiate, posts lisibles par les non-membres
+	GroupClosed = "closed" // visible, adhésion sur demande
+	GroupSecret = "secret" // invisible hors membres et invités, adhésion sur invitation
+)
+
+// Un groupe secret inaccessible est présenté comme inexistant
+var ErrGroupNotFound = errors.New("group not found")
+
+func isValidGroupVisibility(visibility string) bool {
+	switch visibility {
+	case GroupPublic, GroupClosed, GroupSecret:
+		return true
+	}
+	return false
+}
+
+// Condition SQL : le groupe ag est visible par ?1 (non secret, membre ou invitation en attente)
+const visibleGroup = `(ag.VISIBILITY != 'secret'
+	OR EXISTS(SELECT 1 FROM GROUPS_MEMBERS vm WHERE vm.GROUP_ID = ag.ID AND vm.USER_ID = ?1)
+	OR EXISTS(SELECT 1 FROM ASK_GROUP vi WHERE vi.GROUP_ID = ag.ID AND vi.RECEIVER = ?1 AND vi.ASKER != ?1 AND vi.ACCEPTED = 0))`
+
+func groupVisibility(db *sql.DB, groupId string) (string, error) {
+	var visibility string
+	err := db.QueryRow(`SELECT VISIBILITY FROM ALL_GROUPS WHERE ID = ?`, groupId).Scan(&visibility)
+	if err == sql.ErrNoRows {
+		return "", ErrGroupNotFound
+	}
+	return visibility, err
+}
+
+// canSeeGroup vérifie que le groupe existe et n'est pas caché à l'utilisateur.
+func canSeeGroup(db *sql.DB, userId, groupId string) error {
+	var visible bool
+	query := `SELECT EXISTS(SELECT 1 FROM ALL_GROUPS ag WHERE ag.ID = ?2 AND ` + visibleGroup + `)`
+	err := db.QueryRow(query, userId, groupId).Scan(&visible)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return ErrGroupNotFound
+	}
+	return nil
+}
+
+// canReadGroupContent indique si l'utilisateur peut lire les posts du groupe : membre, ou groupe public.
+func canReadGroupContent(db *sql.DB, userId, groupId string) (bool, error) {
+	var canRead bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?2)
+	          OR EXISTS(SELECT 1 FROM ALL_GROUPS WHERE ID = ?2 AND VISIBILITY = 'public')`
+	err := db.QueryRow(query, userId, groupId).Scan(&canRead)
+	return canRead, err
+}
+
+// CanPassGroupImage refuse l'image d'un groupe secret à ceux qui ne peuvent pas le voir.
+func CanPassGroupImage(db *sql.DB, userId, imgID string) error {
+	var groupId string
+	err := db.QueryRow(`SELECT ID FROM ALL_GROUPS WHERE IMAGE = ? LIMIT 1`, imgID).Scan(&groupId)
+	if err == sql.ErrNoRows {
+		// Image d'un groupe supprimé ou remplacée : rien à protéger
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "CanPassGroupImage")
+	}
+	return canSeeGroup(db, userId, groupId)
+}
@@ -6,22 +6,17 @@ import (
 	"github.com/google/uuid"
 )
 
-func CreateComment(userId, postId, content, img string, db *sql.DB) error {
+func CreateComment(userId, postId, content, img string, flags ContentFlags, db *sql.DB) error {
 	// Vérifie que le commentaire contient soit du texte, soit une image
 	if content == "" && img == "" {
 		return errors.New("le commentaire doit contenir du texte ou une image")
 	}
 
-	// Vérifie que le post existe
-	var existingPostID bool
-	query1 := `SELECT EXISTS(SELECT 1 FROM POSTS WHERE ID = ?)`
-	err := db.QueryRow(query1, postId).Scan(&existingPostID)
+	// Vérifie que le post existe et que ses réglages autorisent le commentaire
+	err := canCommentOnPost(db, userId, postId)
 	if err != nil {
 		return err
 	}
-	if !existingPostID {
-		return errors.New("post non trouvé")
-	}
 
 	var ownerId string
 	ownerQuery := `SELECT USER_ID FROM POSTS WHERE ID = ?`
@@ -38,10 +33,10 @@ func CreateComment(userId, postId, content, img string, db *sql.DB) error {
 
 	id := uuid.New().String()
 
-	query := `INSERT INTO COMMENT (ID, POST_ID, USER_ID, CONTENT, IMAGE, CREATED, UPDATED_AT)
-	          VALUES (?, ?, ?, ?, ?, datetime('now'), datetime('now'))`
+	query := `INSERT INTO COMMENT (ID, POST_ID, USER_ID, CONTENT, IMAGE, CREATED, UPDATED_AT, CONTENT_WARNING, SENSITIVE)
+	          VALUES (?, ?, ?, ?, ?, datetime('now'), datetime('now'), ?, ?)`
 
-	_, err = db.Exec(query, id, postId, userId, content, postImg)
+	_, err = db.Exec(query, id, postId, userId, content, postImg, toNullString(flags.Warning), flags.Sensitive)
 	if err != nil {
 		return err
 	}
@@ -56,4 +51,4 @@ func CreateComment(userId, postId, content, img string, db *sql.DB) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
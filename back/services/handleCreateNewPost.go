@@ -18,21 +18,38 @@ func toNullString(value string) sql.NullString {
 	return sql.NullString{String: value, Valid: value != ""}
 }
 
-func CreatePost(content, userId, image, tag, groupId, privacy string, users []string, db *sql.DB) error {
+// CreatePost crée le post et renvoie son statut : pending dans un groupe qui valide les posts.
+func CreatePost(content, userId, image, tag, groupId, privacy, commentPolicy string, flags ContentFlags, users, lists []string, db *sql.DB) (string, error) {
 	fmt.Printf("[CreatePost] Starting post creation - userId: %s, privacy: %s, groupId: %s\n", userId, privacy, groupId)
 
+	if strings.TrimSpace(commentPolicy) == "" {
+		commentPolicy = CommentPolicyEveryone
+	}
+	if !isValidCommentPolicy(commentPolicy) {
+		return "", errors.New("invalid comment policy")
+	}
+
 	id := uuid.New().String()
 	fmt.Printf("[CreatePost] Generated Post ID: %s\n", id)
 
 	imageNull := toNullString(image)
 	groupIdNull := toNullString(groupId)
 
+	status := PostStatusPublished
+	if groupId != "" {
+		var err error
+		status, err = newGroupPostStatus(db, userId, groupId)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	privacyPost := PrivacyFriends
-	if privacy == "public" && len(users) == 0 {
+	if privacy == "public" && len(users) == 0 && len(lists) == 0 {
 		privacyPost = PrivacyPublic
 		fmt.Printf("[CreatePost] Privacy set to PUBLIC\n")
 	}
-	if len(users) > 0 {
+	if len(users) > 0 || len(lists) > 0 {
 		privacyPost = PrivacyPrivate
 		fmt.Printf("[CreatePost] Privacy set to PRIVATE, validating %d users...\n", len(users))
 
@@ -43,29 +60,45 @@ func CreatePost(content, userId, image, tag, groupId, privacy string, users []st
 			err := db.QueryRow(query, user, userId).Scan(&isFollowing)
 			if err != nil {
 				fmt.Printf("[CreatePost][ERROR] Failed checking follower status for user %s: %v\n", user, err)
-				return err
+				return "", err
 			}
 			if !isFollowing {
 				fmt.Printf("[CreatePost][ERROR] User %s is not a follower of %s\n", user, userId)
-				return errors.New("One or more users are not your followers")
+				return "", errors.New("One or more users are not your followers")
 			}
 			fmt.Printf("[CreatePost] User %s is a valid follower\n", user)
 		}
+		for _, list := range lists {
+			err := checkAudienceListOwner(db, userId, list)
+			if err != nil {
+				fmt.Printf("[CreatePost][ERROR] Invalid audience list %s: %v\n", list, err)
+				return "", err
+			}
+		}
 	}
 
 	// INSERT dans POSTS
 	fmt.Printf("[CreatePost] Inserting POST into database...\n")
 	postQuery := `
-		INSERT INTO POSTS(ID, CONTENT, USER_ID, CREATED_AT, UPDATED_AT, IMAGE, GROUP_ID, PRIVACY)
-		VALUES (?, ?, ?, datetime('now'), datetime('now'), ?, ?, ?)
+		INSERT INTO POSTS(ID, CONTENT, USER_ID, CREATED_AT, UPDATED_AT, IMAGE, GROUP_ID, PRIVACY, COMMENT_POLICY, CONTENT_WARNING, SENSITIVE, STATUS)
+		VALUES (?, ?, ?, datetime('now'), datetime('now'), ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := db.Exec(postQuery, id, content, userId, imageNull, groupIdNull, privacyPost)
+	_, err := db.Exec(postQuery, id, content, userId, imageNull, groupIdNull, privacyPost, commentPolicy, toNullString(flags.Warning), flags.Sensitive, status)
 	if err != nil {
 		fmt.Printf("[CreatePost][ERROR] Failed inserting POST: %v\n", err)
-		return err
+		return "", err
 	}
 	fmt.Printf("[CreatePost] POST inserted successfully.\n")
 
+	// Les mentions d'un post en attente sont enregistrées à sa validation
+	if status == PostStatusPublished {
+		err = savePostMentions(db, id, userId, content)
+		if err != nil {
+			fmt.Printf("[CreatePost][ERROR] Failed inserting POST_MENTIONS: %v\n", err)
+			return "", err
+		}
+	}
+
 	// Insertion TAGS
 	if strings.TrimSpace(tag) != "" {
 		tabTag := strings.Fields(tag)
@@ -76,7 +109,7 @@ func CreatePost(content, userId, image, tag, groupId, privacy string, users []st
 			_, err = db.Exec(query, tagId, id, t)
 			if err != nil {
 				fmt.Printf("[CreatePost][ERROR] Failed inserting TAG (%s): %v\n", t, err)
-				return err
+				return "", err
 			}
 			fmt.Printf("[CreatePost] TAG inserted: %s\n", t)
 		}
@@ -94,12 +127,27 @@ func CreatePost(content, userId, image, tag, groupId, privacy string, users []st
 			_, err = db.Exec(privateQuery, idPrivate, id, user)
 			if err != nil {
 				fmt.Printf("[CreatePost][ERROR] Failed inserting LIST_PRIVATE_POST for user %s: %v\n", user, err)
-				return err
+				return "", err
 			}
 			fmt.Printf("[CreatePost] LIST_PRIVATE_POST inserted for user: %s\n", user)
 		}
 	}
 
+	// Insertion POST_AUDIENCE_LISTS (membres évalués à la lecture)
+	if privacyPost == PrivacyPrivate {
+		for _, list := range lists {
+			query := `
+				INSERT OR IGNORE INTO POST_AUDIENCE_LISTS(ID, POST_ID, LIST_ID, CREATED_AT)
+				VALUES (?, ?, ?, datetime('now'))
+			`
+			_, err = db.Exec(query, uuid.New().String(), id, list)
+			if err != nil {
+				fmt.Printf("[CreatePost][ERROR] Failed inserting POST_AUDIENCE_LISTS for list %s: %v\n", list, err)
+				return "", err
+			}
+		}
+	}
+
 	fmt.Printf("[CreatePost] Post creation completed successfully (PostID: %s)\n", id)
-	return nil
+	return status, nil
 }
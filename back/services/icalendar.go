@@ -0,0 +1,248 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Domaine des UID iCalendar : un événement garde le même UID d'un export à l'autre
+const icsUIDDomain = "social-network"
+
+const (
+	icsUTCLayout   = "20060102T150405Z"
+	icsLocalLayout = "20060102T150405"
+)
+
+// icsEvent : un VEVENT. recurrenceId non nul pour une occurrence modifiée d'une série.
+type icsEvent struct {
+	uid          string
+	recurrenceId time.Time
+	start        time.Time
+	end          time.Time // zéro sans heure de fin
+	loc          *time.Location
+	summary      string
+	description  string
+	location     string
+	categories   string
+	rrule        string
+	exdates      []time.Time
+	cancelled    bool
+	created      string // format SQL, UTC
+	updated      string
+}
+
+// icsWriter écrit un calendrier RFC 5545 : lignes terminées par CRLF et repliées à 75 octets.
+type icsWriter struct {
+	b     strings.Builder
+	stamp string
+}
+
+func newICSWriter(name string) *icsWriter {
+	w := &icsWriter{stamp: time.Now().UTC().Format(icsUTCLayout)}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//social-network//events//FR")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", icsText(name))
+	return w
+}
+
+func (w *icsWriter) line(name, value string) {
+	w.b.WriteString(FoldICSLine(name, value))
+}
+
+// FoldICSLine renvoie la ligne de contenu name:value repliée à 75 octets (suite précédée d'une espace),
+// chaque ligne physique terminée par CRLF.
+func FoldICSLine(name, value string) string {
+	var b strings.Builder
+	l := name + ":" + value
+	for len(l) > 75 {
+		// On ne coupe pas au milieu d'un caractère UTF-8
+		cut := 75
+		for cut > 0 && !utf8.RuneStart(l[cut]) {
+			cut--
+		}
+		b.WriteString(l[:cut] + "\r\n")
+		l = " " + l[cut:]
+	}
+	b.WriteString(l + "\r\n")
+	return b.String()
+}
+
+// timeLine écrit une date : en UTC pour le fuseau UTC, sinon en heure locale avec TZID.
+func (w *icsWriter) timeLine(name string, t time.Time, loc *time.Location) {
+	if loc == time.UTC {
+		w.line(name, t.UTC().Format(icsUTCLayout))
+		return
+	}
+	w.line(name+";TZID="+loc.String(), t.In(loc).Format(icsLocalLayout))
+}
+
+// timeZones écrit un VTIMEZONE par fuseau utilisé, avec les changements d'heure de la période des événements.
+func (w *icsWriter) timeZones(events []icsEvent) {
+	type span struct {
+		loc      *time.Location
+		from, to time.Time
+	}
+	spans := map[string]*span{}
+	for _, e := range events {
+		if e.loc == time.UTC {
+			continue
+		}
+		last := e.start
+		if e.rrule != "" {
+			// Les occurrences d'une série durent au plus maxRecurrenceYears ans
+			last = e.start.AddDate(maxRecurrenceYears, 0, 0)
+		}
+		sp, ok := spans[e.loc.String()]
+		if !ok {
+			spans[e.loc.String()] = &span{loc: e.loc, from: e.start, to: last}
+			continue
+		}
+		if e.start.Before(sp.from) {
+			sp.from = e.start
+		}
+		if last.After(sp.to) {
+			sp.to = last
+		}
+	}
+
+	var names []string
+	for name := range spans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		sp := spans[name]
+		w.line("BEGIN", "VTIMEZONE")
+		w.line("TZID", name)
+
+		from := sp.from.AddDate(-1, 0, 0)
+		zone, offset := from.In(sp.loc).Zone()
+		kind := "STANDARD"
+		if from.In(sp.loc).IsDST() {
+			kind = "DAYLIGHT"
+		}
+		w.line("BEGIN", kind)
+		w.line("DTSTART", "19700101T000000")
+		w.line("TZOFFSETFROM", icsOffset(offset))
+		w.line("TZOFFSETTO", icsOffset(offset))
+		w.line("TZNAME", icsText(zone))
+		w.line("END", kind)
+
+		for _, t := range zoneTransitions(sp.loc, from, sp.to.AddDate(0, 0, 2)) {
+			before := t.Add(-time.Second)
+			_, prev := before.In(sp.loc).Zone()
+			zone, next := t.In(sp.loc).Zone()
+			kind := "STANDARD"
+			if t.In(sp.loc).IsDST() {
+				kind = "DAYLIGHT"
+			}
+			w.line("BEGIN", kind)
+			// DTSTART en heure locale d'avant le changement
+			w.line("DTSTART", t.In(time.FixedZone("", prev)).Format(icsLocalLayout))
+			w.line("TZOFFSETFROM", icsOffset(prev))
+			w.line("TZOFFSETTO", icsOffset(next))
+			w.line("TZNAME", icsText(zone))
+			w.line("END", kind)
+		}
+
+		w.line("END", "VTIMEZONE")
+	}
+}
+
+func (w *icsWriter) event(e icsEvent) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", e.uid)
+	w.line("DTSTAMP", w.stamp)
+	if !e.recurrenceId.IsZero() {
+		w.timeLine("RECURRENCE-ID", e.recurrenceId, e.loc)
+	}
+	w.timeLine("DTSTART", e.start, e.loc)
+	if !e.end.IsZero() {
+		w.timeLine("DTEND", e.end, e.loc)
+	}
+	if e.rrule != "" {
+		w.line("RRULE", e.rrule)
+	}
+	for _, t := range e.exdates {
+		w.timeLine("EXDATE", t, e.loc)
+	}
+	w.line("SUMMARY", icsText(e.summary))
+	if e.description != "" {
+		w.line("DESCRIPTION", icsText(e.description))
+	}
+	if e.location != "" {
+		w.line("LOCATION", icsText(e.location))
+	}
+	if e.categories != "" {
+		w.line("CATEGORIES", icsText(e.categories))
+	}
+	if t, err := time.Parse(eventSQLTimeLayout, e.created); err == nil {
+		w.line("CREATED", t.Format(icsUTCLayout))
+	}
+	if t, err := time.Parse(eventSQLTimeLayout, e.updated); err == nil {
+		w.line("LAST-MODIFIED", t.Format(icsUTCLayout))
+	}
+	if e.cancelled {
+		w.line("STATUS", "CANCELLED")
+	} else {
+		w.line("STATUS", "CONFIRMED")
+	}
+	w.line("END", "VEVENT")
+}
+
+func (w *icsWriter) bytes() []byte {
+	w.line("END", "VCALENDAR")
+	return []byte(w.b.String())
+}
+
+// icsText échappe une valeur TEXT (RFC 5545, 3.3.11).
+func icsText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+func icsOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
+
+// zoneTransitions renvoie les instants de changement de décalage du fuseau entre from et to.
+func zoneTransitions(loc *time.Location, from, to time.Time) []time.Time {
+	var out []time.Time
+	_, prev := from.In(loc).Zone()
+	for t := from; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		_, offset := next.In(loc).Zone()
+		if offset == prev {
+			continue
+		}
+		// Recherche de la seconde exacte du changement
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if _, o := mid.In(loc).Zone(); o == prev {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		out = append(out, hi)
+		prev = offset
+	}
+	return out
+}
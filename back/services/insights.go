@@ -0,0 +1,192 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"time"
+)
+
+const (
+	DefaultInsightsDays = 30
+	MaxInsightsDays     = 90
+	maxInsightsPosts    = 50
+)
+
+type InsightsDay struct {
+	Date         string `json:"date"`
+	Impressions  int    `json:"impressions"`
+	Reactions    int    `json:"reactions"`
+	Comments     int    `json:"comments"`
+	NewFollowers int    `json:"new_followers"`
+}
+
+type PostInsights struct {
+	PostId        string `json:"post_id"`
+	Content       string `json:"content"`
+	CreatedAt     string `json:"created_at"`
+	Impressions   int    `json:"impressions"`
+	UniqueViewers int    `json:"unique_viewers"`
+	Likes         int    `json:"likes"`
+	Dislikes      int    `json:"dislikes"`
+	Comments      int    `json:"comments"`
+}
+
+type Insights struct {
+	From      string         `json:"from"`
+	To        string         `json:"to"`
+	Followers int            `json:"followers"`
+	Days      []InsightsDay  `json:"days"`
+	Posts     []PostInsights `json:"posts"`
+}
+
+// SendInsights renvoie à l'auteur les statistiques de ses posts sur les derniers jours, par jour.
+// Si postId est renseigné, les séries journalières ne concernent que ce post
+// (les nouveaux abonnés restent ceux du compte).
+func SendInsights(db *sql.DB, userId, postId string, days int) (Insights, error) {
+	var insights Insights
+
+	if days <= 0 || days > MaxInsightsDays {
+		return insights, errors.New("days must be between 1 and 90")
+	}
+
+	if postId != "" {
+		var owner string
+		err := db.QueryRow(`SELECT USER_ID FROM POSTS WHERE ID = ?`, postId).Scan(&owner)
+		if err == sql.ErrNoRows {
+			return insights, errors.New("post not found")
+		}
+		if err != nil {
+			return insights, err
+		}
+		if owner != userId {
+			return insights, errors.New("you are not the owner of this post")
+		}
+	}
+
+	// Les vues encore en mémoire doivent être prises en compte
+	if err := FlushPostViews(db); err != nil {
+		return insights, err
+	}
+
+	now := time.Now().UTC()
+	from := now.AddDate(0, 0, -(days - 1)).Format("2006-01-02")
+	insights.From = from
+	insights.To = now.Format("2006-01-02")
+
+	index := make(map[string]int)
+	for d := 0; d < days; d++ {
+		date := now.AddDate(0, 0, d-(days-1)).Format("2006-01-02")
+		index[date] = d
+		insights.Days = append(insights.Days, InsightsDay{Date: date})
+	}
+
+	err := db.QueryRow(`SELECT COUNT(*) FROM FOLLOWERS WHERE USER_ID = ?`, userId).Scan(&insights.Followers)
+	if err != nil {
+		return insights, err
+	}
+
+	// Filtre optionnel sur un post
+	postFilter := ""
+	args := []interface{}{userId, from}
+	if postId != "" {
+		postFilter = " AND p.ID = ?"
+		args = append(args, postId)
+	}
+
+	series := []struct {
+		query string
+		args  []interface{}
+		set   func(day *InsightsDay, count int)
+	}{
+		{
+			query: `SELECT v.DAY, COUNT(*) FROM POST_VIEWS v JOIN POSTS p ON p.ID = v.POST_ID
+			        WHERE p.USER_ID = ? AND v.DAY >= ?` + postFilter + ` GROUP BY v.DAY`,
+			args: args,
+			set:  func(day *InsightsDay, count int) { day.Impressions = count },
+		},
+		{
+			query: `SELECT date(COALESCE(e.UPDATE_AT, e.CREATED_AT)) AS DAY, COUNT(*) FROM POST_EVENT e JOIN POSTS p ON p.ID = e.POST_ID
+			        WHERE p.USER_ID = ? AND e.LIKED IS NOT NULL AND e.USER_ID != p.USER_ID
+			          AND date(COALESCE(e.UPDATE_AT, e.CREATED_AT)) >= ?` + postFilter + ` GROUP BY DAY`,
+			args: args,
+			set:  func(day *InsightsDay, count int) { day.Reactions = count },
+		},
+		{
+			query: `SELECT date(c.CREATED) AS DAY, COUNT(*) FROM COMMENT c JOIN POSTS p ON p.ID = c.POST_ID
+			        WHERE p.USER_ID = ? AND c.USER_ID != p.USER_ID AND date(c.CREATED) >= ?` + postFilter + ` GROUP BY DAY`,
+			args: args,
+			set:  func(day *InsightsDay, count int) { day.Comments = count },
+		},
+		{
+			query: `SELECT date(CREATED_AT) AS DAY, COUNT(*) FROM FOLLOWERS
+			        WHERE USER_ID = ? AND date(CREATED_AT) >= ? GROUP BY DAY`,
+			args: []interface{}{userId, from},
+			set:  func(day *InsightsDay, count int) { day.NewFollowers = count },
+		},
+	}
+
+	for _, s := range series {
+		rows, err := db.Query(s.query, s.args...)
+		if err != nil {
+			return insights, err
+		}
+		for rows.Next() {
+			var date string
+			var count int
+			if err = rows.Scan(&date, &count); err != nil {
+				rows.Close()
+				return insights, err
+			}
+			if i, ok := index[date]; ok {
+				s.set(&insights.Days[i], count)
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return insights, err
+		}
+	}
+
+	insights.Posts, err = sendPostInsights(db, userId, postId, from)
+	if err != nil {
+		return insights, err
+	}
+
+	return insights, nil
+}
+
+// sendPostInsights renvoie les totaux par post sur la période, posts les plus récents d'abord.
+func sendPostInsights(db *sql.DB, userId, postId, from string) ([]PostInsights, error) {
+	var posts []PostInsights
+
+	query := `
+		SELECT p.ID, p.CONTENT, p.CREATED_AT,
+		       (SELECT COUNT(*) FROM POST_VIEWS v WHERE v.POST_ID = p.ID AND v.DAY >= ?1),
+		       (SELECT COUNT(DISTINCT v.VIEWER_ID) FROM POST_VIEWS v WHERE v.POST_ID = p.ID AND v.DAY >= ?1),
+		       (SELECT COUNT(*) FROM POST_EVENT e WHERE e.POST_ID = p.ID AND e.LIKED = 'liked' AND e.USER_ID != p.USER_ID
+		          AND date(COALESCE(e.UPDATE_AT, e.CREATED_AT)) >= ?1),
+		       (SELECT COUNT(*) FROM POST_EVENT e WHERE e.POST_ID = p.ID AND e.LIKED = 'disliked' AND e.USER_ID != p.USER_ID
+		          AND date(COALESCE(e.UPDATE_AT, e.CREATED_AT)) >= ?1),
+		       (SELECT COUNT(*) FROM COMMENT c WHERE c.POST_ID = p.ID AND c.USER_ID != p.USER_ID AND date(c.CREATED) >= ?1)
+		FROM POSTS p
+		WHERE p.USER_ID = ?2 AND (?3 = '' OR p.ID = ?3)
+		ORDER BY p.CREATED_AT DESC
+		LIMIT ?4
+	`
+	rows, err := db.Query(query, from, userId, postId, maxInsightsPosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p PostInsights
+		err = rows.Scan(&p.PostId, &p.Content, &p.CreatedAt, &p.Impressions, &p.UniqueViewers, &p.Likes, &p.Dislikes, &p.Comments)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+
+	return posts, rows.Err()
+}
@@ -7,12 +7,12 @@ import (
 	"strings"
 )
 
-func ModifyGroup(db *sql.DB, userID, title, desc, groupID, img string) error {
-	var groupTitle, groupDesc, groupOwner string
+func ModifyGroup(db *sql.DB, userID, title, desc, groupID, img, visibility string) error {
+	var groupTitle, groupDesc, currentVisibility string
 	var image sql.NullString
 
-	query := `SELECT TITLE, DESCRIPTION, OWNER, IMAGE FROM ALL_GROUPS WHERE ID = ?`
-	err := db.QueryRow(query, groupID).Scan(&groupTitle, &groupDesc, &groupOwner, &image)
+	query := `SELECT TITLE, DESCRIPTION, IMAGE, VISIBILITY FROM ALL_GROUPS WHERE ID = ?`
+	err := db.QueryRow(query, groupID).Scan(&groupTitle, &groupDesc, &image, &currentVisibility)
 	if err != nil {
 		return err
 	}
@@ -25,8 +25,8 @@ func ModifyGroup(db *sql.DB, userID, title, desc, groupID, img string) error {
 		}
 	}
 
-	if groupOwner != userID {
-		return errors.New("You are not the owner")
+	if _, err = checkGroupPermission(db, userID, groupID, PermEditGroup); err != nil {
+		return err
 	}
 
 	if strings.TrimSpace(title) == "" {
@@ -35,15 +35,21 @@ func ModifyGroup(db *sql.DB, userID, title, desc, groupID, img string) error {
 	if strings.TrimSpace(desc) == "" {
 		desc = groupDesc
 	}
+	if visibility == "" {
+		visibility = currentVisibility
+	}
+	if !isValidGroupVisibility(visibility) {
+		return errors.New("invalid visibility (public, closed or secret)")
+	}
 
 	// Vérifie si un changement a été réellement effectué
-	if title == groupTitle && desc == groupDesc && img == image.String {
+	if title == groupTitle && desc == groupDesc && img == image.String && visibility == currentVisibility {
 		log.Println("Aucun changement détecté, pas de mise à jour nécessaire.")
 		return nil
 	}
 
-	updateQuery := `UPDATE ALL_GROUPS SET TITLE = ?, DESC = ?, IMAGE = ? WHERE ID = ?`
-	_, err = db.Exec(updateQuery, title, desc, img, groupID)
+	updateQuery := `UPDATE ALL_GROUPS SET TITLE = ?, DESCRIPTION = ?, IMAGE = ?, VISIBILITY = ? WHERE ID = ?`
+	_, err = db.Exec(updateQuery, title, desc, img, visibility, groupID)
 	if err != nil {
 		return errors.Wrap(err, "failed to update group")
 	}
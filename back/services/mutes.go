@@ -0,0 +1,216 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"log"
+	"time"
+)
+
+const (
+	MuteUser         = "user"
+	MuteConversation = "conversation"
+	MuteGroup        = "group"
+)
+
+// Durées proposées ; "forever" correspond à une sourdine sans expiration
+var muteDurations = map[string]time.Duration{
+	"1h":      time.Hour,
+	"8h":      8 * time.Hour,
+	"24h":     24 * time.Hour,
+	"1w":      7 * 24 * time.Hour,
+	"forever": 0,
+}
+
+type Mute struct {
+	TargetType string `json:"target_type"`
+	TargetId   string `json:"target_id"`
+	ExpiresAt  string `json:"expires_at"` // vide : pour toujours
+	CreatedAt  string `json:"created_at"`
+}
+
+// Une sourdine est active tant qu'elle n'a pas expiré, même si le nettoyage n'est pas encore passé
+const activeMute = `(EXPIRES_AT IS NULL OR EXPIRES_AT > datetime('now'))`
+
+// isMuted indique si userId a mis la cible en sourdine.
+func isMuted(db *sql.DB, userId, targetType, targetId string) (bool, error) {
+	var muted bool
+	query := `SELECT EXISTS(SELECT 1 FROM MUTES WHERE USER_ID = ? AND TARGET_TYPE = ? AND TARGET_ID = ? AND ` + activeMute + `)`
+	err := db.QueryRow(query, userId, targetType, targetId).Scan(&muted)
+	return muted, err
+}
+
+// mutedUserIds renvoie les utilisateurs que userId a mis en sourdine.
+func mutedUserIds(db *sql.DB, userId string) (map[string]bool, error) {
+	muted := make(map[string]bool)
+
+	query := `SELECT TARGET_ID FROM MUTES WHERE USER_ID = ? AND TARGET_TYPE = 'user' AND ` + activeMute
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		muted[id] = true
+	}
+
+	return muted, rows.Err()
+}
+
+// UnmutedMembers retire de members ceux qui ont mis la conversation ou le groupe en sourdine.
+// Utilisé avant les envois WebSocket et les notifications : les messages restent enregistrés.
+// L'expéditeur est toujours conservé, pour l'écho de ses propres messages sur ses autres onglets.
+func UnmutedMembers(db *sql.DB, targetType, targetId, senderId string, members []string) ([]string, error) {
+	muted := make(map[string]bool)
+
+	query := `SELECT USER_ID FROM MUTES WHERE TARGET_TYPE = ? AND TARGET_ID = ? AND ` + activeMute
+	rows, err := db.Query(query, targetType, targetId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		muted[id] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var unmuted []string
+	for _, member := range members {
+		if member == senderId || !muted[member] {
+			unmuted = append(unmuted, member)
+		}
+	}
+
+	return unmuted, nil
+}
+
+// checkMuteTarget vérifie que la cible existe et que l'utilisateur peut la mettre en sourdine.
+func checkMuteTarget(db *sql.DB, userId, targetType, targetId string) error {
+	var ok bool
+	var err error
+
+	switch targetType {
+	case MuteUser:
+		if targetId == userId {
+			return errors.New("you cannot mute yourself")
+		}
+		err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM USER WHERE ID = ?)`, targetId).Scan(&ok)
+		if err == nil && !ok {
+			return errors.New("user not found")
+		}
+	case MuteConversation:
+		query := `SELECT EXISTS(SELECT 1 FROM CONVERSATION_MEMBERS WHERE CONVERSATION_ID = ? AND USER_ID = ?)`
+		err = db.QueryRow(query, targetId, userId).Scan(&ok)
+		if err == nil && !ok {
+			return errors.New("you are not a member of this conversation")
+		}
+	case MuteGroup:
+		query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE GROUP_ID = ? AND USER_ID = ?)`
+		err = db.QueryRow(query, targetId, userId).Scan(&ok)
+		if err == nil && !ok {
+			return errors.New("user is not a member of the group")
+		}
+	default:
+		return errors.New("invalid mute type (user, conversation or group)")
+	}
+
+	return err
+}
+
+// MuteTarget met en sourdine un utilisateur, une conversation ou un groupe pour la durée donnée.
+// Mettre à nouveau en sourdine une cible remplace la durée précédente.
+func MuteTarget(db *sql.DB, userId, targetType, targetId, duration string) error {
+	if duration == "" {
+		duration = "forever"
+	}
+	d, ok := muteDurations[duration]
+	if !ok {
+		return errors.New("invalid duration (1h, 8h, 24h, 1w or forever)")
+	}
+
+	if err := checkMuteTarget(db, userId, targetType, targetId); err != nil {
+		return err
+	}
+
+	var expiresAt sql.NullString
+	if d > 0 {
+		expiresAt = sql.NullString{String: time.Now().UTC().Add(d).Format("2006-01-02 15:04:05"), Valid: true}
+	}
+
+	query := `INSERT INTO MUTES(ID, USER_ID, TARGET_TYPE, TARGET_ID, EXPIRES_AT, CREATED_AT)
+	          VALUES (?, ?, ?, ?, ?, datetime('now'))
+	          ON CONFLICT(USER_ID, TARGET_TYPE, TARGET_ID) DO UPDATE SET EXPIRES_AT = excluded.EXPIRES_AT, CREATED_AT = excluded.CREATED_AT`
+	_, err := db.Exec(query, uuid.New().String(), userId, targetType, targetId, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert mute")
+	}
+
+	return nil
+}
+
+// UnmuteTarget lève la sourdine.
+func UnmuteTarget(db *sql.DB, userId, targetType, targetId string) error {
+	query := `DELETE FROM MUTES WHERE USER_ID = ? AND TARGET_TYPE = ? AND TARGET_ID = ? AND ` + activeMute
+	res, err := db.Exec(query, userId, targetType, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete mute")
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("target is not muted")
+	}
+	return nil
+}
+
+// SendMutes renvoie les sourdines actives de l'utilisateur.
+func SendMutes(db *sql.DB, userId string) ([]Mute, error) {
+	var mutes []Mute
+
+	query := `SELECT TARGET_TYPE, TARGET_ID, IFNULL(EXPIRES_AT, ''), CREATED_AT FROM MUTES
+	          WHERE USER_ID = ? AND ` + activeMute + ` ORDER BY CREATED_AT DESC`
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m Mute
+		if err = rows.Scan(&m.TargetType, &m.TargetId, &m.ExpiresAt, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		mutes = append(mutes, m)
+	}
+
+	return mutes, rows.Err()
+}
+
+// StartMuteCleanup lance en tâche de fond la suppression périodique des sourdines expirées.
+func StartMuteCleanup(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			res, err := db.Exec(`DELETE FROM MUTES WHERE EXPIRES_AT IS NOT NULL AND EXPIRES_AT <= datetime('now')`)
+			if err != nil {
+				log.Println("Erreur lors du nettoyage des sourdines :", err)
+			} else if n, _ := res.RowsAffected(); n > 0 {
+				log.Printf("%d sourdines expirées supprimées", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
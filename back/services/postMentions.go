@@ -0,0 +1,51 @@
+package services
+
+import (
+	"database/sql"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+var mentionRegex = regexp.MustCompile(`@([\w.-]+)`)
+
+// savePostMentions enregistre les utilisateurs mentionnés (@username) dans le contenu d'un post.
+// Les mentions existantes sont remplacées, ce qui permet de l'appeler aussi à la mise à jour du post.
+func savePostMentions(db *sql.DB, postId, authorId, content string) error {
+	_, err := db.Exec(`DELETE FROM POST_MENTIONS WHERE POST_ID = ?`, postId)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		var mentionedId string
+		err = db.QueryRow(`SELECT ID FROM USER WHERE USERNAME = ?`, match[1]).Scan(&mentionedId)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if mentionedId == authorId || seen[mentionedId] {
+			continue
+		}
+		// Pas de mention entre utilisateurs qui se sont bloqués
+		blocked, err := isBlocked(db, authorId, mentionedId)
+		if err != nil {
+			return err
+		}
+		if blocked {
+			continue
+		}
+		seen[mentionedId] = true
+
+		query := `INSERT INTO POST_MENTIONS(ID, POST_ID, USER_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+		_, err = db.Exec(query, uuid.New().String(), postId, mentionedId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
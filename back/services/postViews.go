@@ -0,0 +1,119 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// Au-delà de ce nombre de vues en attente, on déclenche un flush sans attendre le ticker
+const maxPendingViews = 5000
+
+type postViewKey struct {
+	postId   string
+	viewerId string
+	day      string
+}
+
+// Les vues sont accumulées en mémoire (déjà dédupliquées) puis écrites par lots :
+// afficher un fil ne coûte aucune écriture SQLite.
+var postViews = struct {
+	sync.Mutex
+	pending map[postViewKey]struct{}
+	flush   chan struct{}
+}{
+	pending: make(map[postViewKey]struct{}),
+	flush:   make(chan struct{}, 1),
+}
+
+// RecordPostView enregistre en mémoire la vue d'un post. Les vues de l'auteur ne sont pas comptées.
+func RecordPostView(viewerId, postId, authorId string) {
+	if viewerId == "" || viewerId == authorId {
+		return
+	}
+
+	key := postViewKey{postId: postId, viewerId: viewerId, day: time.Now().UTC().Format("2006-01-02")}
+
+	postViews.Lock()
+	postViews.pending[key] = struct{}{}
+	full := len(postViews.pending) >= maxPendingViews
+	postViews.Unlock()
+
+	if full {
+		select {
+		case postViews.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// StartPostViewFlusher lance en tâche de fond l'écriture périodique des vues en attente.
+func StartPostViewFlusher(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-postViews.flush:
+			}
+			if err := FlushPostViews(db); err != nil {
+				log.Println("Erreur lors de l'écriture des vues :", err)
+			}
+		}
+	}()
+}
+
+// FlushPostViews écrit en une transaction toutes les vues en attente.
+// En cas d'échec, les vues sont remises dans le tampon pour le prochain flush.
+func FlushPostViews(db *sql.DB) error {
+	postViews.Lock()
+	batch := postViews.pending
+	postViews.pending = make(map[postViewKey]struct{})
+	postViews.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := writePostViews(db, batch)
+	if err != nil {
+		postViews.Lock()
+		for key := range batch {
+			postViews.pending[key] = struct{}{}
+		}
+		postViews.Unlock()
+	}
+
+	return err
+}
+
+func writePostViews(db *sql.DB, batch map[postViewKey]struct{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO POST_VIEWS(POST_ID, VIEWER_ID, DAY, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`)
+	if err != nil {
+		return errors.Wrap(err, "failed to prepare post views insert")
+	}
+	defer stmt.Close()
+
+	for key := range batch {
+		_, err = stmt.Exec(key.postId, key.viewerId, key.day)
+		if err != nil {
+			return errors.Wrap(err, "failed to insert post view")
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "transaction commit failed")
+	}
+
+	return nil
+}
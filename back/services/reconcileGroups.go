@@ -0,0 +1,89 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+)
+
+// Groupes encore référencés par des données alors que leur ligne ALL_GROUPS n'existe plus
+const orphanGroupIds = `
+	SELECT GROUP_ID FROM GROUPS_MEMBERS
+	UNION SELECT GROUP_ID FROM POSTS
+	UNION SELECT GROUP_ID FROM MESSAGES
+	UNION SELECT GROUP_ID FROM GROUPS_EVENT
+	UNION SELECT GROUP_ID FROM ASK_GROUP
+	UNION SELECT GROUP_ID FROM GROUP_JOIN_DECISIONS
+	UNION SELECT GROUP_ID FROM GROUP_QUESTIONS
+	UNION SELECT GROUP_ID FROM GROUP_TRANSFERS
+	UNION SELECT GROUP_ID FROM GROUP_INVITE_LINKS
+	UNION SELECT GROUP_ID FROM GROUP_RULES
+	UNION SELECT GROUP_ID FROM GROUP_ANNOUNCEMENTS
+	UNION SELECT GROUP_ID FROM GROUP_BANS
+	UNION SELECT GROUP_ID FROM GROUP_FILES
+	UNION SELECT GROUP_ID FROM GROUP_FILE_FOLDERS
+	UNION SELECT GROUP_ID FROM GROUP_CHANNELS
+	UNION SELECT TARGET_ID FROM MUTES WHERE TARGET_TYPE = 'group'`
+
+type GroupReconcileReport struct {
+	Groups int   // groupes orphelins nettoyés
+	Rows   int64 // lignes supprimées
+	Files  int   // images supprimées ou déjà absentes
+}
+
+// ReconcileGroupOrphans supprime les données laissées par des groupes effacés avant la suppression
+// en cascade, avec leurs images. Chaque groupe est nettoyé dans sa propre transaction.
+func ReconcileGroupOrphans(db *sql.DB) (GroupReconcileReport, error) {
+	var report GroupReconcileReport
+
+	query := `SELECT GROUP_ID FROM (` + orphanGroupIds + `)
+	          WHERE IFNULL(GROUP_ID, '') <> '' AND GROUP_ID NOT IN (SELECT ID FROM ALL_GROUPS)`
+	rows, err := db.Query(query)
+	if err != nil {
+		return report, err
+	}
+	var groupIds []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return report, err
+		}
+		groupIds = append(groupIds, id)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return report, err
+	}
+
+	for _, groupId := range groupIds {
+		n, files, err := reconcileGroup(db, groupId)
+		if err != nil {
+			return report, errors.Wrapf(err, "failed to clean up group %s", groupId)
+		}
+		report.Groups++
+		report.Rows += n
+		report.Files += len(files)
+	}
+
+	return report, nil
+}
+
+func reconcileGroup(db *sql.DB, groupId string) (int64, []groupFile, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "transaction begin failed")
+	}
+	defer tx.Rollback()
+
+	n, files, err := purgeGroup(tx, groupId)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, nil, errors.Wrap(err, "transaction commit failed")
+	}
+
+	removeGroupFiles(files)
+	return n, files, nil
+}
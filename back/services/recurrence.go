@@ -0,0 +1,191 @@
+package services
+
+import (
+	"github.com/pkg/errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxEventOccurrences = 500
+	maxRecurrenceYears  = 2 // UNTIL au plus 2 ans après le début
+)
+
+// Jours RRULE, indexés par time.Weekday
+var rruleWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// recurrence : sous-ensemble de RRULE (RFC 5545) — FREQ DAILY, WEEKLY ou MONTHLY, INTERVAL,
+// BYDAY (WEEKLY seulement, sans préfixe numérique) et obligatoirement UNTIL ou COUNT.
+type recurrence struct {
+	freq     string
+	interval int
+	byDay    []time.Weekday
+	count    int
+	until    time.Time // UTC, zéro avec COUNT
+}
+
+// parseRecurrence lit une règle ("FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10", préfixe "RRULE:" accepté).
+// Une UNTIL sans heure compte jusqu'à la fin de ce jour dans le fuseau loc. Renvoie nil pour une règle vide.
+func parseRecurrence(rule string, loc *time.Location) (*recurrence, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return nil, nil
+	}
+
+	r := &recurrence{interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, errors.Errorf("invalid recurrence rule part %q", part)
+		}
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" {
+				return nil, errors.New("recurrence frequency must be DAILY, WEEKLY or MONTHLY")
+			}
+			r.freq = value
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 99 {
+				return nil, errors.New("invalid recurrence interval")
+			}
+			r.interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxEventOccurrences {
+				return nil, errors.Errorf("recurrence count must be between 1 and %d", maxEventOccurrences)
+			}
+			r.count = n
+		case "UNTIL":
+			if t, err := time.Parse("20060102T150405Z", value); err == nil {
+				r.until = t
+			} else if d, err := time.ParseInLocation("20060102", value, loc); err == nil {
+				r.until = d.AddDate(0, 0, 1).Add(-time.Second).UTC()
+			} else {
+				return nil, errors.New("invalid recurrence until date")
+			}
+		case "BYDAY":
+			seen := map[time.Weekday]bool{}
+			for _, d := range strings.Split(value, ",") {
+				i := slices.Index(rruleWeekdays, d)
+				if i < 0 {
+					return nil, errors.Errorf("invalid recurrence day %q", d)
+				}
+				wd := time.Weekday(i)
+				if !seen[wd] {
+					seen[wd] = true
+					r.byDay = append(r.byDay, wd)
+				}
+			}
+		default:
+			return nil, errors.Errorf("unsupported recurrence rule part %s", key)
+		}
+	}
+
+	if r.freq == "" {
+		return nil, errors.New("missing recurrence frequency")
+	}
+	if len(r.byDay) > 0 && r.freq != "WEEKLY" {
+		return nil, errors.New("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if (r.count == 0) == r.until.IsZero() {
+		return nil, errors.New("recurrence needs either UNTIL or COUNT")
+	}
+
+	// Semaine commençant le lundi (WKST=MO)
+	sort.Slice(r.byDay, func(i, j int) bool { return (r.byDay[i]+6)%7 < (r.byDay[j]+6)%7 })
+
+	return r, nil
+}
+
+// String renvoie la règle normalisée, telle qu'enregistrée et exportée en iCalendar.
+func (r *recurrence) String() string {
+	parts := []string{"FREQ=" + r.freq}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if len(r.byDay) > 0 {
+		var days []string
+		for _, wd := range r.byDay {
+			days = append(days, rruleWeekdays[wd])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	} else {
+		parts = append(parts, "UNTIL="+r.until.Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// expand renvoie les débuts de toutes les occurrences, en gardant l'heure locale de start
+// (changements d'heure compris). Les mois sans le jour de start sont sautés, comme dans la RFC 5545.
+func (r *recurrence) expand(start time.Time) ([]time.Time, error) {
+	limit := start.AddDate(maxRecurrenceYears, 0, 0)
+	if !r.until.IsZero() && r.until.After(limit) {
+		return nil, errors.Errorf("recurrence cannot last more than %d years", maxRecurrenceYears)
+	}
+
+	var out []time.Time
+	done := false
+	add := func(t time.Time) {
+		if (r.count > 0 && len(out) >= r.count) || (!r.until.IsZero() && t.After(r.until)) {
+			done = true
+			return
+		}
+		out = append(out, t)
+	}
+
+	// Borne de sécurité : au plus maxRecurrenceYears ans de pas journaliers
+	for i := 0; !done && i <= maxRecurrenceYears*366; i++ {
+		switch r.freq {
+		case "DAILY":
+			add(start.AddDate(0, 0, i*r.interval))
+		case "MONTHLY":
+			t := start.AddDate(0, i*r.interval, 0)
+			if t.Day() == start.Day() {
+				add(t)
+			}
+		case "WEEKLY":
+			days := r.byDay
+			if len(days) == 0 {
+				days = []time.Weekday{start.Weekday()}
+			}
+			monday := start.AddDate(0, 0, -int((start.Weekday()+6)%7)+7*i*r.interval)
+			for _, wd := range days {
+				t := monday.AddDate(0, 0, int((wd+6)%7))
+				if t.Before(start) {
+					continue
+				}
+				if add(t); done {
+					break
+				}
+			}
+		}
+		if len(out) > maxEventOccurrences {
+			return nil, errors.Errorf("recurrence has too many occurrences (max %d)", maxEventOccurrences)
+		}
+		if len(out) > 0 && out[len(out)-1].After(limit) {
+			return nil, errors.Errorf("recurrence cannot last more than %d years", maxRecurrenceYears)
+		}
+	}
+
+	return out, nil
+}
+
+// ExpandRecurrence renvoie les débuts des occurrences de la règle à partir de start, dans le fuseau
+// de start. Une règle vide donne la seule occurrence start.
+func ExpandRecurrence(rule string, start time.Time) ([]time.Time, error) {
+	r, err := parseRecurrence(rule, start.Location())
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return []time.Time{start}, nil
+	}
+	return r.expand(start)
+}
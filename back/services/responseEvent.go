@@ -3,52 +3,97 @@ package services
 import (
 	"database/sql"
 	"errors"
-	"github.com/google/uuid"
 	"strings"
+	"time"
 )
 
-func ResponseEvent(db *sql.DB, userId, groupID, eventId, response string) error {
-	response = strings.ToUpper(response)
-
-	var responseInt int
-	if response == "A" {
-		responseInt = 1
-	} else if response == "B" {
-		responseInt = 2
-	} else {
-		return errors.New("invalid response")
-	}
-
-	// Vérifier si une réponse existe déjà
-	var existingID string
-	var existingResponse int
-	err := db.QueryRow(`
-		SELECT ID, RESPONSE FROM RESPONSE_EVENT 
-		WHERE USER_ID = ? AND EVENT_ID = ? AND GROUP_ID = ?
-	`, userId, eventId, groupID).Scan(&existingID, &existingResponse)
-
-	if err != nil && err != sql.ErrNoRows {
-		return err
-	}
-
-	if existingID != "" {
-		if existingResponse == responseInt {
-			// ✅ Même réponse → toggle (supprime la réponse)
-			_, err = db.Exec(`DELETE FROM RESPONSE_EVENT WHERE ID = ?`, existingID)
-			return err
-		} else {
-			// ✅ Réponse différente → mise à jour
-			_, err = db.Exec(`UPDATE RESPONSE_EVENT SET RESPONSE = ? WHERE ID = ?`, responseInt, existingID)
-			return err
+// ResponseEvent enregistre la réponse du membre (going, maybe ou not_going) à une occurrence
+// (vide pour un événement unique) et renvoie son statut : going devient waitlist quand l'occurrence
+// est complète. Quitter going libère la place pour la liste d'attente.
+func ResponseEvent(db *sql.DB, userId, groupID, eventId, occurrence, response string) (string, error) {
+	response = strings.ToLower(response)
+	if response != RSVPGoing && response != RSVPMaybe && response != RSVPNotGoing {
+		return "", errors.New("invalid response")
+	}
+
+	// Vérifie que l'utilisateur est bien membre du groupe
+	var isMember bool
+	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
+	if err := db.QueryRow(query, userId, groupID).Scan(&isMember); err != nil {
+		return "", err
+	}
+	if !isMember {
+		return "", errors.New("user is not member of group")
+	}
+
+	s, err := loadEventSeries(db, eventId)
+	if err != nil {
+		return "", err
+	}
+	if s.groupId != groupID {
+		return "", errors.New("event not found")
+	}
+	key, err := parseOccurrenceKey(occurrence)
+	if err != nil {
+		return "", err
+	}
+	overrides, err := eventOverrides(db, eventId)
+	if err != nil {
+		return "", err
+	}
+	o, err := s.occurrence(key, overrides)
+	if err != nil {
+		return "", err
+	}
+	if o.cancelled {
+		return "", errors.New("this event has been cancelled")
+	}
+	if !o.start.After(time.Now()) {
+		return "", errors.New("this event has already started")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Places prises et réponse actuelle pour cette occurrence
+	var full bool
+	var current sql.NullString
+	err = tx.QueryRow(`
+		SELECT e.CAPACITY IS NOT NULL AND (SELECT COUNT(*) FROM EVENT_RSVPS
+		                                   WHERE EVENT_ID = e.ID AND OCCURRENCE_AT = ?2 AND STATUS = 'going') >= e.CAPACITY,
+		       (SELECT STATUS FROM EVENT_RSVPS WHERE EVENT_ID = e.ID AND OCCURRENCE_AT = ?2 AND USER_ID = ?3)
+		FROM GROUPS_EVENT e WHERE e.ID = ?1
+	`, eventId, key, userId).Scan(&full, &current)
+	if err != nil {
+		return "", err
+	}
+
+	status := response
+	if response == RSVPGoing {
+		if current.String == RSVPGoing || current.String == RSVPWaitlist {
+			// Déjà inscrit : on garde sa place (ou son rang dans la liste d'attente)
+			return current.String, nil
+		}
+		if full {
+			status = RSVPWaitlist
 		}
 	}
 
-	// ✅ Aucune réponse encore → insertion
-	id := uuid.New().String()
-	_, err = db.Exec(`
-		INSERT INTO RESPONSE_EVENT (ID, USER_ID, RESPONSE, EVENT_ID, GROUP_ID)
-		VALUES (?, ?, ?, ?, ?)
-	`, id, userId, responseInt, eventId, groupID)
+	if err = setEventRSVP(tx, eventId, key, userId, status); err != nil {
+		return "", err
+	}
+	if current.String == RSVPGoing {
+		if err = promoteWaitlist(tx, eventId, key); err != nil {
+			return "", err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
 
-	return err
+	return status, nil
 }
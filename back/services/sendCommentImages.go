@@ -9,20 +9,46 @@ func CanPassCommentImages(db *sql.DB, userID, imgID string) error {
 	var privacy int
 	var userPostId string
 	var postId string
+	var groupId sql.NullString
 
-	queryCom := `SELECT POST_ID FROM COMMENT WHERE IMAGE = ? LIMIT 1`
-	err := db.QueryRow(queryCom, imgID).Scan(&postId)
+	var userCommentId string
+	queryCom := `SELECT POST_ID, USER_ID FROM COMMENT WHERE IMAGE = ? LIMIT 1`
+	err := db.QueryRow(queryCom, imgID).Scan(&postId, &userCommentId)
+	if err != nil {
+		return err
+	}
+
+	query := `SELECT PRIVACY, USER_ID, GROUP_ID FROM POSTS WHERE ID = ? LIMIT 1`
+	err = db.QueryRow(query, postId).Scan(&privacy, &userPostId, &groupId)
+	if err != nil {
+		return err
+	}
 
-	query := `SELECT PRIVACY, USER_ID FROM POSTS WHERE ID = ? LIMIT 1`
-	err = db.QueryRow(query, postId).Scan(&privacy, &userPostId)
+	// Ni l'auteur du post ni celui du commentaire ne doivent être bloqués
+	blocked, err := blockedUserIds(db, userID)
 	if err != nil {
 		return err
 	}
+	if blocked[userPostId] || blocked[userCommentId] {
+		return ErrBlocked
+	}
 
 	if userID == userPostId {
 		return nil
 	}
 
+	// Post de groupe : même règle que pour les posts (membre, ou groupe public)
+	if groupId.Valid {
+		canRead, err := canReadGroupContent(db, userID, groupId.String)
+		if err != nil {
+			return err
+		}
+		if !canRead {
+			return errors.New("User is not a member of the group")
+		}
+		return nil
+	}
+
 	if privacy == 2 {
 		return nil
 	} else if privacy == 1 {
@@ -37,8 +63,7 @@ func CanPassCommentImages(db *sql.DB, userID, imgID string) error {
 		}
 	} else if privacy == 0 {
 		var private bool
-		query = `SELECT EXISTS (SELECT 1 FROM LIST_PRIVATE_POST WHERE POST_ID = ? AND USER_ID = ?)`
-		err = db.QueryRow(query, postId, userID).Scan(&private)
+		private, err = hasPrivatePostAccess(db, userID, postId)
 		if err != nil {
 			return err
 		}
@@ -7,10 +7,21 @@ import (
 	"time"
 )
 
-func SendEventGroupNotif(eventID, groupID, senderUserID string, db *sql.DB) {
-	// On récupère les membres du groupe
-	queryMembers := `SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ?`
-	rows, err := db.Query(queryMembers, groupID)
+// SendEventGroupNotif notifie un événement : sa création (EVENT_GROUP) aux membres du groupe,
+// ses modifications (EVENT_UPDATED, EVENT_CANCELLED) à tous ceux qui y ont répondu, ou seulement
+// à ceux qui ont répondu à l'occurrence quand occurrence n'est pas vide. Les membres qui ont mis
+// le groupe en sourdine ne sont pas notifiés.
+func SendEventGroupNotif(eventID, occurrence, groupID, senderUserID, notifType string, db *sql.DB) {
+	// On récupère les membres du groupe, hors ceux qui l'ont mis en sourdine
+	queryMembers := `SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ?1
+		AND USER_ID NOT IN (SELECT USER_ID FROM MUTES WHERE TARGET_TYPE = 'group' AND TARGET_ID = ?1 AND ` + activeMute + `)`
+	if notifType != "EVENT_GROUP" {
+		// Participants toujours membres, quelle que soit leur réponse, hors sourdine du groupe
+		queryMembers = `SELECT DISTINCT r.USER_ID FROM EVENT_RSVPS r JOIN GROUPS_MEMBERS m ON m.USER_ID = r.USER_ID AND m.GROUP_ID = ?1
+			WHERE r.EVENT_ID = ?2 AND (?3 = '' OR r.OCCURRENCE_AT = ?3)
+			AND r.USER_ID NOT IN (SELECT USER_ID FROM MUTES WHERE TARGET_TYPE = 'group' AND TARGET_ID = ?1 AND ` + activeMute + `)`
+	}
+	rows, err := db.Query(queryMembers, groupID, eventID, occurrence)
 	if err != nil {
 		log.Println("Error querying group members:", err)
 		return
@@ -36,6 +47,16 @@ func SendEventGroupNotif(eventID, groupID, senderUserID string, db *sql.DB) {
 		}
 	}()
 
+	idType := eventNotifID(eventID, occurrence)
+
+	// Seule la dernière modification non lue est gardée
+	if notifType == "EVENT_UPDATED" {
+		_, err = tx.Exec(`DELETE FROM NOTIFICATIONS WHERE TYPE = 'EVENT_UPDATED' AND ID_TYPE = ? AND READ = 0`, idType)
+		if err != nil {
+			log.Println("Error deleting previous event notifications:", err)
+		}
+	}
+
 	for rows.Next() {
 		var userID string
 		err = rows.Scan(&userID)
@@ -49,15 +70,15 @@ func SendEventGroupNotif(eventID, groupID, senderUserID string, db *sql.DB) {
 			continue
 		}
 
-		// On génère une notif EVENT_GROUP
+		// On génère une notif du type demandé
 		idNotif := uuid.New().String()
 		insertNotifQuery := `
 			INSERT INTO NOTIFICATIONS (ID, TYPE, USER_ID, ID_TYPE) 
-			VALUES (?, 'EVENT_GROUP', ?, ?)
+			VALUES (?, ?, ?, ?)
 		`
 
 		for i := 0; i < 3; i++ { // on ajoute un petit retry sur database is locked
-			_, err = tx.Exec(insertNotifQuery, idNotif, userID, eventID)
+			_, err = tx.Exec(insertNotifQuery, idNotif, notifType, userID, idType)
 			if err == nil {
 				break
 			}
@@ -9,20 +9,31 @@ type GroupInfo struct {
 	TotalMembers int              `json:"total_members"`
 	IsMember     bool             `json:"is_member"`
 	IsAdmin      bool             `json:"is_admin"`
+	Role         string           `json:"role"` // vide si non membre
+	Permissions  []string         `json:"permissions"`
 	JoinStatus   int              `json:"join_status"` // 0: pas de demande, 1: demande en cours, 2: membre
+	Muted        bool             `json:"muted"`
+	Rules        *GroupRules      `json:"rules,omitempty"` // règlement en vigueur, à accepter avant de publier
 }
 
 type GroupInformation struct {
-	Id          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	GroupPicUrl string `json:"group_pic_url"`
-	CreatedAt   string `json:"created_at"`
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	GroupPicUrl  string `json:"group_pic_url"`
+	CreatedAt    string `json:"created_at"`
+	Visibility   string `json:"visibility"`
+	PostApproval bool   `json:"post_approval"` // posts des membres soumis à validation
 }
 
 func SendGroupInfos(db *sql.DB, userId, groupId string) (GroupInfo, error) {
 	var g GroupInfo
 
+	// Un groupe secret n'existe pas pour les non-membres non invités
+	if err := canSeeGroup(db, userId, groupId); err != nil {
+		return g, err
+	}
+
 	// Vérifier si l'utilisateur est membre
 	queryM := `SELECT EXISTS (SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
 	err := db.QueryRow(queryM, userId, groupId).Scan(&g.IsMember)
@@ -58,9 +69,15 @@ func SendGroupInfos(db *sql.DB, userId, groupId string) (GroupInfo, error) {
 		}
 	}
 
-	// Vérifier si l'utilisateur est admin
-	queryO := `SELECT EXISTS(SELECT 1 FROM ALL_GROUPS WHERE OWNER = ? AND ID = ?)`
-	err = db.QueryRow(queryO, userId, groupId).Scan(&g.IsAdmin)
+	// Rôle de l'utilisateur ; is_admin reste vrai pour le propriétaire et les admins
+	g.Role, err = groupRole(db, userId, groupId)
+	if err != nil {
+		return g, err
+	}
+	g.Permissions = groupRolePermissions(g.Role)
+	g.IsAdmin = g.Role == RoleOwner || g.Role == RoleAdmin
+
+	g.Muted, err = isMuted(db, userId, MuteGroup, groupId)
 	if err != nil {
 		return g, err
 	}
@@ -74,13 +91,15 @@ func SendGroupInfos(db *sql.DB, userId, groupId string) (GroupInfo, error) {
 
 	// Infos du groupe
 	var imgGroup sql.NullString
-	queryInfo := `SELECT ID, TITLE, DESCRIPTION, IMAGE, CREATED_AT FROM ALL_GROUPS WHERE ID = ?`
+	queryInfo := `SELECT ID, TITLE, DESCRIPTION, IMAGE, CREATED_AT, VISIBILITY, POST_APPROVAL FROM ALL_GROUPS WHERE ID = ?`
 	err = db.QueryRow(queryInfo, groupId).Scan(
 		&g.GroupInfos.Id,
 		&g.GroupInfos.Name,
 		&g.GroupInfos.Description,
 		&imgGroup,
 		&g.GroupInfos.CreatedAt,
+		&g.GroupInfos.Visibility,
+		&g.GroupInfos.PostApproval,
 	)
 	if err != nil {
 		return g, err
@@ -90,5 +109,13 @@ func SendGroupInfos(db *sql.DB, userId, groupId string) (GroupInfo, error) {
 		g.GroupInfos.GroupPicUrl = imgGroup.String
 	}
 
+	rules, err := GetGroupRules(db, userId, groupId)
+	if err != nil {
+		return g, err
+	}
+	if rules.Content != "" {
+		g.Rules = &rules
+	}
+
 	return g, nil
 }
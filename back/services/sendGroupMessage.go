@@ -6,17 +6,13 @@ import (
 	"github.com/pkg/errors"
 )
 
-func SendGroupMessage(db *sql.DB, userID, groupID, content string, typeMessage int) (string, string, error) {
-	var isMember bool
-
-	// Vérification si l'utilisateur est membre du groupe
-	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-	err := db.QueryRow(query, userID, groupID).Scan(&isMember)
+// SendGroupMessage enregistre un message dans un salon du groupe ("" : le salon par défaut).
+// Renvoie l'ID de conversation, l'ID du message et celui du salon.
+func SendGroupMessage(db *sql.DB, userID, groupID, channelID, content string, typeMessage int, flags ContentFlags) (string, string, string, error) {
+	// Vérification si l'utilisateur est membre du groupe et a accès au salon
+	channel, err := resolveGroupChannel(db, userID, groupID, channelID)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to check group membership")
-	}
-	if !isMember {
-		return "", "", errors.New("user is not a member of the group")
+		return "", "", "", err
 	}
 
 	// Création d'un ID pour le message
@@ -24,14 +20,14 @@ func SendGroupMessage(db *sql.DB, userID, groupID, content string, typeMessage i
 	convID := groupID // ici, tu utilises le groupID comme conversation ID logique
 
 	// Insertion du message
-	query = `
-		INSERT INTO MESSAGES(ID, SENDER_ID, CONVERSATION_ID, CONTENT, TYPE, GROUP_ID, CREATED_AT)
-		VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
+	query := `
+		INSERT INTO MESSAGES(ID, SENDER_ID, CONVERSATION_ID, CONTENT, TYPE, GROUP_ID, CHANNEL_ID, CONTENT_WARNING, SENSITIVE, CREATED_AT)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
 	`
-	_, err = db.Exec(query, msgID, userID, convID, content, typeMessage, groupID)
+	_, err = db.Exec(query, msgID, userID, convID, content, typeMessage, groupID, channel.ID, toNullString(flags.Warning), flags.Sensitive)
 	if err != nil {
-		return "", "", errors.Wrap(err, "failed to insert group message")
+		return "", "", "", errors.Wrap(err, "failed to insert group message")
 	}
 
-	return convID, msgID, nil
+	return convID, msgID, channel.ID, nil
 }
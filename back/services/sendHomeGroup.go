@@ -11,6 +11,7 @@ type GroupHome struct {
 	Description string `json:"description"`
 	Image       string `json:"image"` // null
 	CreatedAt   string `json:"created_at"`
+	Visibility  string `json:"visibility"`
 }
 
 func SendGroupHome(db *sql.DB, userID string) ([]GroupHome, []GroupHome, error) {
@@ -25,7 +26,7 @@ func SendGroupHome(db *sql.DB, userID string) ([]GroupHome, []GroupHome, error)
 func listGroupHome(db *sql.DB, userID string) ([]GroupHome, []GroupHome, error) {
 	var listGroup []GroupHome
 	query := `
-	SELECT ag.ID, ag.OWNER, ag.TITLE,ag.DESCRIPTION, ag.IMAGE,ag.CREATED_AT
+	SELECT ag.ID, ag.OWNER, ag.TITLE,ag.DESCRIPTION, ag.IMAGE,ag.CREATED_AT, ag.VISIBILITY
 	FROM GROUPS_MEMBERS gm
 	JOIN ALL_GROUPS ag ON gm.GROUP_ID = ag.ID
 	WHERE gm.USER_ID = ?
@@ -37,18 +38,19 @@ func listGroupHome(db *sql.DB, userID string) ([]GroupHome, []GroupHome, error)
 	defer rows.Close()
 	for rows.Next() {
 		var g GroupHome
-		if err := rows.Scan(&g.Id, &g.Owner, &g.Title, &g.Description, &g.Image, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.Id, &g.Owner, &g.Title, &g.Description, &g.Image, &g.CreatedAt, &g.Visibility); err != nil {
 			return nil, nil, err
 		}
 
 		listGroup = append(listGroup, g)
 	}
 
+	// Les groupes secrets n'apparaissent jamais dans la découverte
 	var Discovery []GroupHome
 	query = `
-	SELECT ag.ID, ag.OWNER, ag.TITLE, ag.DESCRIPTION, ag.IMAGE, ag.CREATED_AT
+	SELECT ag.ID, ag.OWNER, ag.TITLE, ag.DESCRIPTION, ag.IMAGE, ag.CREATED_AT, ag.VISIBILITY
 	FROM ALL_GROUPS ag
-	WHERE ag.ID NOT IN (
+	WHERE ag.VISIBILITY != 'secret' AND ag.ID NOT IN (
 		SELECT gm.GROUP_ID
 		FROM GROUPS_MEMBERS gm
 		WHERE gm.USER_ID = ?
@@ -61,7 +63,7 @@ func listGroupHome(db *sql.DB, userID string) ([]GroupHome, []GroupHome, error)
 
 	for rows.Next() {
 		var g GroupHome
-		if err := rows.Scan(&g.Id, &g.Owner, &g.Title, &g.Description, &g.Image, &g.CreatedAt); err != nil {
+		if err := rows.Scan(&g.Id, &g.Owner, &g.Title, &g.Description, &g.Image, &g.CreatedAt, &g.Visibility); err != nil {
 			return nil, nil, err
 		}
 		Discovery = append(Discovery, g)
@@ -17,8 +17,18 @@ func SendHomePost(db *sql.DB, userId string) ([]PostProfile, error) {
 func structHomePost(db *sql.DB, userId string, offset int) ([]PostProfile, error) {
 	var postProfile []PostProfile
 
-	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE,GROUP_ID, PRIVACY 
-	          FROM POSTS ORDER BY CREATED_AT DESC LIMIT 100 OFFSET ?`
+	blocked, err := blockedUserIds(db, userId)
+	if err != nil {
+		return postProfile, err
+	}
+	// Les utilisateurs en sourdine restent suivis mais disparaissent du fil d'accueil
+	muted, err := mutedUserIds(db, userId)
+	if err != nil {
+		return postProfile, err
+	}
+
+	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE,GROUP_ID, PRIVACY, COMMENT_POLICY, COMMENTS_LOCKED, IFNULL(CONTENT_WARNING, ''), SENSITIVE
+	          FROM POSTS WHERE STATUS = 'published' ORDER BY CREATED_AT DESC LIMIT 100 OFFSET ?`
 
 	rows, err := db.Query(query, offset)
 	if err != nil {
@@ -32,11 +42,15 @@ func structHomePost(db *sql.DB, userId string, offset int) ([]PostProfile, error
 		var privacy int
 		var accessPrivate, accessGroup bool
 
-		err = rows.Scan(&p.Id, &p.Content, &p.UserId, &p.CreatedAt, &imageContent, &groupId, &privacy)
+		err = rows.Scan(&p.Id, &p.Content, &p.UserId, &p.CreatedAt, &imageContent, &groupId, &privacy, &p.CommentPolicy, &p.CommentsLocked, &p.ContentWarning, &p.Sensitive)
 		if err != nil {
 			return postProfile, err
 		}
 
+		if blocked[p.UserId] || muted[p.UserId] {
+			continue
+		}
+
 		if groupId.Valid {
 			p.GroupId.Id = groupId.String
 			query = `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
@@ -58,8 +72,7 @@ func structHomePost(db *sql.DB, userId string, offset int) ([]PostProfile, error
 				continue
 			}
 		} else if privacy == 0 && p.UserId != userId {
-			query = `SELECT EXISTS(SELECT 1 FROM LIST_PRIVATE_POST WHERE USER_ID = ? AND POST_ID = ?)`
-			err = db.QueryRow(query, userId, p.Id).Scan(&accessPrivate)
+			accessPrivate, err = hasPrivatePostAccess(db, userId, p.Id)
 			if err != nil || (!accessPrivate && userId != p.UserId) {
 				log.Printf("Post %s ignoré (accès privé refusé pour l'utilisateur %s)", p.Id, userId)
 				continue
@@ -102,7 +115,7 @@ func structHomePost(db *sql.DB, userId string, offset int) ([]PostProfile, error
 
 		db.QueryRow(`SELECT COUNT(*) FROM POST_EVENT WHERE POST_ID = ? AND LIKED = 'liked'`, p.Id).Scan(&p.LikeCount)
 		db.QueryRow(`SELECT COUNT(*) FROM POST_EVENT WHERE POST_ID = ? AND LIKED = 'disliked'`, p.Id).Scan(&p.DislikeCount)
-		db.QueryRow(`SELECT COUNT(*) FROM COMMENT WHERE POST_ID = ?`, p.Id).Scan(&p.CommentCount)
+		db.QueryRow(visibleCommentCount, p.Id, userId).Scan(&p.CommentCount)
 
 		var firstName, lastName string
 		var imageProfile, username sql.NullString
@@ -135,6 +148,7 @@ func structHomePost(db *sql.DB, userId string, offset int) ([]PostProfile, error
 			p.Privacy = "ListPrivate"
 		}
 
+		RecordPostView(userId, p.Id, p.UserId)
 		postProfile = append(postProfile, p)
 	}
 
@@ -7,18 +7,13 @@ import (
 )
 
 func SendInvitationGroup(db *sql.DB, userID, receiverId, groupId string) error {
-	var isMember bool
-	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-	err := db.QueryRow(query, userID, groupId).Scan(&isMember)
+	_, err := checkGroupPermission(db, userID, groupId, PermInvite)
 	if err != nil {
 		return err
 	}
-	if !isMember {
-		return errors.New("user is not a member of the group")
-	}
 
 	var receiverIsMember bool
-	query = `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
+	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
 	err = db.QueryRow(query, receiverId, groupId).Scan(&receiverIsMember)
 	if err != nil {
 		return err
@@ -27,6 +22,18 @@ func SendInvitationGroup(db *sql.DB, userID, receiverId, groupId string) error {
 		return errors.New("receiver is already a member of the group")
 	}
 
+	if err = checkNotBlocked(db, userID, receiverId); err != nil {
+		return err
+	}
+
+	banned, err := isBannedFromGroup(db, receiverId, groupId)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return errors.New("this user is banned from the group")
+	}
+
 	var isFollower bool
 	query = `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
 	err = db.QueryRow(query, receiverId, userID).Scan(&isFollower)
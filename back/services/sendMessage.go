@@ -8,7 +8,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func AddMessage(db *sql.DB, members []string, senderID, conversationID, content string, typeMsg int) (string, string, error) {
+func AddMessage(db *sql.DB, members []string, senderID, conversationID, content string, typeMsg int, flags ContentFlags) (string, string, error) {
 	if conversationID != "" {
 		// Vérifie que le sender est bien membre de la conversation
 		var isMember bool
@@ -26,12 +26,29 @@ func AddMessage(db *sql.DB, members []string, senderID, conversationID, content
 			return "", "", errors.New("you are not a member of this conversation")
 		}
 
+		// Un blocage entre l'expéditeur et un autre membre interdit l'envoi
+		var blocked bool
+		err = db.QueryRow(`
+			SELECT EXISTS(
+				SELECT 1 FROM CONVERSATION_MEMBERS cm
+				JOIN BLOCKS b ON (b.BLOCKER_ID = ?1 AND b.BLOCKED_ID = cm.USER_ID)
+				              OR (b.BLOCKER_ID = cm.USER_ID AND b.BLOCKED_ID = ?1)
+				WHERE cm.CONVERSATION_ID = ?2 AND cm.USER_ID != ?1
+			)
+		`, senderID, conversationID).Scan(&blocked)
+		if err != nil {
+			return "", "", errors.Wrap(err, "failed to check blocks")
+		}
+		if blocked {
+			return "", "", ErrBlocked
+		}
+
 		// Ajoute le message
 		msgID := uuid.New().String()
 		_, err = db.Exec(`
-			INSERT INTO MESSAGES (ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CREATED_AT)
-			VALUES (?, ?, ?, ?, 0, ?, datetime('now'))
-		`, msgID, senderID, conversationID, content, typeMsg)
+			INSERT INTO MESSAGES (ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CONTENT_WARNING, SENSITIVE, CREATED_AT)
+			VALUES (?, ?, ?, ?, 0, ?, ?, ?, datetime('now'))
+		`, msgID, senderID, conversationID, content, typeMsg, toNullString(flags.Warning), flags.Sensitive)
 		if err != nil {
 			return "", "", errors.Wrap(err, "failed to insert message in existing conversation")
 		}
@@ -56,6 +73,9 @@ func AddMessage(db *sql.DB, members []string, senderID, conversationID, content
 		if !follows {
 			return "", "", errors.Errorf("user %s does not follow you", userID)
 		}
+		if err = checkNotBlocked(db, senderID, userID); err != nil {
+			return "", "", err
+		}
 		unique[userID] = true
 	}
 
@@ -90,9 +110,9 @@ func AddMessage(db *sql.DB, members []string, senderID, conversationID, content
 			// Ajoute le message à la conversation existante
 			msgID := uuid.New().String()
 			_, err = db.Exec(`
-				INSERT INTO MESSAGES (ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CREATED_AT)
-				VALUES (?, ?, ?, ?, 0, ?, datetime('now'))
-			`, msgID, senderID, existingConvID, content, typeMsg)
+				INSERT INTO MESSAGES (ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CONTENT_WARNING, SENSITIVE, CREATED_AT)
+				VALUES (?, ?, ?, ?, 0, ?, ?, ?, datetime('now'))
+			`, msgID, senderID, existingConvID, content, typeMsg, toNullString(flags.Warning), flags.Sensitive)
 			if err != nil {
 				return "", "", errors.Wrap(err, "failed to insert message in existing private conversation")
 			}
@@ -130,9 +150,9 @@ func AddMessage(db *sql.DB, members []string, senderID, conversationID, content
 	// Ajoute le message
 	msgID := uuid.New().String()
 	_, err = db.Exec(`
-		INSERT INTO MESSAGES (ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CREATED_AT)
-		VALUES (?, ?, ?, ?, 0, ?, datetime('now'))
-	`, msgID, senderID, convID, content, typeMsg)
+		INSERT INTO MESSAGES (ID, SENDER_ID, CONVERSATION_ID, CONTENT, SEEN, TYPE, CONTENT_WARNING, SENSITIVE, CREATED_AT)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?, datetime('now'))
+	`, msgID, senderID, convID, content, typeMsg, toNullString(flags.Warning), flags.Sensitive)
 	if err != nil {
 		return "", "", errors.Wrap(err, "failed to insert message")
 	}
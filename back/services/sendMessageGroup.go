@@ -2,37 +2,34 @@ package services
 
 import (
 	"database/sql"
-	"github.com/google/uuid"
-	"github.com/pkg/errors"
 )
 
 type MessageGroup struct {
 	ID          string `json:"id"`
 	GroupID     string `json:"group_id"`
+	ChannelID   string `json:"channel_id"`
 	SenderID    string `json:"sender_id"`
 	Content     string `json:"content"`
 	Type        int    `json:"type"` // 0 = text, 1 = image
 	CreatedAt   string `json:"created_at"`
 	Sender      User   `json:"sender"`
+	Warning     string `json:"content_warning"`
+	Sensitive   bool   `json:"sensitive"`
 }
 
-func SendMessageGroup(db *sql.DB, userId, groupID string) ([]MessageGroup, error) {
+// SendMessageGroup renvoie les messages d'un salon du groupe ("" : le salon par défaut)
+func SendMessageGroup(db *sql.DB, userId, groupID, channelID string) ([]MessageGroup, error) {
 	var messages []MessageGroup
 
-	// Vérifier que l'utilisateur est membre du groupe
-	var isMember bool
-	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-	err := db.QueryRow(query, userId, groupID).Scan(&isMember)
+	// Vérifier que l'utilisateur est membre du groupe et a accès au salon
+	channel, err := resolveGroupChannel(db, userId, groupID, channelID)
 	if err != nil {
 		return nil, err
 	}
-	if !isMember {
-		return nil, errors.New("User is not member of group")
-	}
 
-	// Récupérer tous les messages du groupe
-	query = `SELECT ID, SENDER_ID, CONTENT, TYPE, CREATED_AT FROM MESSAGES WHERE GROUP_ID = ? ORDER BY CREATED_AT ASC`
-	rows, err := db.Query(query, groupID)
+	// Récupérer tous les messages du salon
+	query := `SELECT ID, SENDER_ID, CONTENT, TYPE, CREATED_AT, IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM MESSAGES WHERE CHANNEL_ID = ? ORDER BY CREATED_AT ASC`
+	rows, err := db.Query(query, channel.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -42,12 +39,13 @@ func SendMessageGroup(db *sql.DB, userId, groupID string) ([]MessageGroup, error
 		var msg MessageGroup
 		var typeMessage int
 
-		err = rows.Scan(&msg.ID, &msg.SenderID, &msg.Content, &typeMessage, &msg.CreatedAt)
+		err = rows.Scan(&msg.ID, &msg.SenderID, &msg.Content, &typeMessage, &msg.CreatedAt, &msg.Warning, &msg.Sensitive)
 		if err != nil {
 			continue
 		}
 
 		msg.GroupID = groupID
+		msg.ChannelID = channel.ID
 		msg.Type = typeMessage
 
 		// Récupérer les infos de l'expéditeur
@@ -75,32 +73,7 @@ func SendMessageGroup(db *sql.DB, userId, groupID string) ([]MessageGroup, error
 	return messages, nil
 }
 
-// Fonction pour envoyer un message dans un groupe
-func CreateGroupMessage(db *sql.DB, userID, groupID, content string, typeMessage int) (string, string, error) {
-	// Vérifier que l'utilisateur est membre du groupe
-	var isMember bool
-	query := `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID = ? AND GROUP_ID = ?)`
-	err := db.QueryRow(query, userID, groupID).Scan(&isMember)
-	if err != nil {
-		return "", "", errors.Wrap(err, "failed to check group membership")
-	}
-	if !isMember {
-		return "", "", errors.New("user is not a member of the group")
-	}
-
-	// Créer un ID pour le message
-	msgID := uuid.New().String()
-	convID := groupID // Utiliser le groupID comme conversation ID
-
-	// Insérer le message
-	query = `
-		INSERT INTO MESSAGES(ID, SENDER_ID, CONVERSATION_ID, CONTENT, TYPE, GROUP_ID, CREATED_AT)
-		VALUES (?, ?, ?, ?, ?, ?, datetime('now'))
-	`
-	_, err = db.Exec(query, msgID, userID, convID, content, typeMessage, groupID)
-	if err != nil {
-		return "", "", errors.Wrap(err, "failed to insert group message")
-	}
-
-	return convID, msgID, nil
+// Fonction pour envoyer un message dans un salon du groupe ("" : le salon par défaut)
+func CreateGroupMessage(db *sql.DB, userID, groupID, channelID, content string, typeMessage int) (string, string, string, error) {
+	return SendGroupMessage(db, userID, groupID, channelID, content, typeMessage, ContentFlags{})
 }
\ No newline at end of file
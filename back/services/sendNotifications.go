@@ -3,6 +3,7 @@ package services
 import (
 	"database/sql"
 	"github.com/pkg/errors"
+	"time"
 )
 
 type Notification struct {
@@ -45,14 +46,18 @@ type GroupInviteData struct {
 
 type EventGroupNotification struct {
 	EventID     string `json:"event_id"`
+	Occurrence  string `json:"occurrence"` // vide pour un événement unique ou toute la série
+	Recurrence  string `json:"recurrence"`
 	GroupID     string `json:"group_id"`
 	GroupName   string `json:"group_name"`
 	GroupPic    string `json:"group_pic"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
-	OptionA     string `json:"option_a"`
-	OptionB     string `json:"option_b"`
-	DateTime    string `json:"date_time"`
+	StartAt     string `json:"start_at"`
+	EndAt       string `json:"end_at"`
+	TimeZone    string `json:"time_zone"`
+	Location    string `json:"location"`
+	Cancelled   bool   `json:"cancelled"`
 	CreatedAt   string `json:"created_at"`
 	User        User   `json:"user"`
 }
@@ -93,16 +98,51 @@ func SendNotifications(db *sql.DB, userID string) ([]Notification, error) {
 			if err != nil {
 				continue
 			}
+		case "FOLLOW_ACCEPTED":
+			n.Data, err = followAccepted(db, idType)
+			if err != nil {
+				continue
+			}
 		case "INVITE_GROUP":
 			n.Data, err = askGroupAndInviteGroup(db, idType)
 			if err != nil {
 				continue
 			}
-		case "EVENT_GROUP":
+		case "TRANSFER_GROUP":
+			n.Data, err = groupTransfer(db, idType)
+			if err != nil {
+				continue
+			}
+		case "GROUP_REQUEST_ACCEPTED", "GROUP_REQUEST_DECLINED":
+			n.Data, err = groupDecision(db, idType)
+			if err != nil {
+				continue
+			}
+		case "EVENT_GROUP", "EVENT_UPDATED", "EVENT_CANCELLED", "EVENT_REMINDER", "EVENT_WAITLIST_PROMOTED":
 			n.Data, err = getEventGroupNotificationData(db, idType)
 			if err != nil {
 				continue
 			}
+		case "GROUP_POST_APPROVED", "GROUP_POST_REJECTED":
+			n.Data, err = groupPostDecision(db, idType)
+			if err != nil {
+				continue
+			}
+		case "GROUP_ANNOUNCEMENT":
+			n.Data, err = groupAnnouncementNotification(db, idType)
+			if err != nil {
+				continue
+			}
+		case "GROUP_RULES_UPDATED":
+			n.Data, err = groupRulesNotification(db, idType)
+			if err != nil {
+				continue
+			}
+		case "GROUP_DELETED":
+			n.Data, err = groupDeletedNotification(db, idType)
+			if err != nil {
+				continue
+			}
 		default:
 			continue
 		}
@@ -222,6 +262,56 @@ func askFollow(db *sql.DB, idFollow string) (FollowRequestData, error) {
 	return f, nil
 }
 
+// followAccepted renvoie la demande acceptée ; Sender est l'utilisateur qui l'a acceptée.
+func followAccepted(db *sql.DB, idFollow string) (FollowRequestData, error) {
+	var f FollowRequestData
+	var receiverID string
+	f.FollowerID = idFollow
+
+	query := `SELECT RECEIVER_ID, CREATED_AT, STATUS FROM REQUEST_FOLLOW WHERE ID = ?`
+	err := db.QueryRow(query, idFollow).Scan(&receiverID, &f.CreatedAt, &f.Status)
+	if err != nil {
+		return f, err
+	}
+
+	f.Sender, err = getUserByID(db, receiverID)
+	if err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+// groupTransfer renvoie le groupe proposé et l'utilisateur qui en cède la propriété.
+func groupTransfer(db *sql.DB, idTransfer string) (GroupInviteData, error) {
+	var g GroupInviteData
+	var fromID string
+
+	query := `SELECT FROM_ID, GROUP_ID FROM GROUP_TRANSFERS WHERE ID = ?`
+	err := db.QueryRow(query, idTransfer).Scan(&fromID, &g.GroupID)
+	if err != nil {
+		return g, err
+	}
+
+	var imgGroup sql.NullString
+	query = `SELECT TITLE, DESCRIPTION, CREATED_AT, IMAGE FROM ALL_GROUPS WHERE ID = ?`
+	err = db.QueryRow(query, g.GroupID).Scan(&g.GroupName, &g.GroupBio, &g.CreatedAt, &imgGroup)
+	if err != nil {
+		return g, err
+	}
+
+	if imgGroup.Valid {
+		g.GroupPic = imgGroup.String
+	}
+
+	g.User, err = getUserByID(db, fromID)
+	if err != nil {
+		return g, err
+	}
+
+	return g, nil
+}
+
 func askGroupAndInviteGroup(db *sql.DB, askGroup string) (GroupInviteData, error) {
 	var g GroupInviteData
 	var askerID string
@@ -271,22 +361,48 @@ func getUserByID(db *sql.DB, userID string) (User, error) {
 	return u, nil
 }
 
-func getEventGroupNotificationData(db *sql.DB, groupEventID string) (EventGroupNotification, error) {
+// getEventGroupNotificationData : idType vaut l'ID de l'événement, suivi de "|<occurrence>"
+// quand la notification concerne une seule occurrence (voir eventNotifID).
+func getEventGroupNotificationData(db *sql.DB, idType string) (EventGroupNotification, error) {
 	var e EventGroupNotification
-	var senderID string
 	var groupPic sql.NullString
 
-	// 1. On récupère les infos de l'event dans GROUPS_EVENT
-	query := `SELECT GROUP_ID, SENDER, TITLE, DESCRIPTION, OPTION_A, OPTION_B, DATE_TIME, CREATED_AT FROM GROUPS_EVENT WHERE ID = ?`
-	err := db.QueryRow(query, groupEventID).Scan(&e.GroupID, &senderID, &e.Title, &e.Description, &e.OptionA, &e.OptionB, &e.DateTime, &e.CreatedAt)
+	// 1. On récupère l'event (la série) et, le cas échéant, l'occurrence concernée
+	eventID, key := splitEventNotifID(idType)
+	s, err := loadEventSeries(db, eventID)
+	if err != nil {
+		return e, err
+	}
+	overrides, err := eventOverrides(db, eventID)
 	if err != nil {
 		return e, err
 	}
+	o := s.apply(s.start, overrides)
+	if key != "" {
+		if o, err = s.occurrence(key, overrides); err != nil {
+			return e, err
+		}
+	}
 
-	e.EventID = groupEventID
+	e.EventID = eventID
+	e.Occurrence = formatOccurrenceKey(key)
+	if s.rule != nil {
+		e.Recurrence = s.rule.String()
+	}
+	e.GroupID = s.groupId
+	e.Title = o.title
+	e.Description = o.description
+	e.TimeZone = s.timeZone
+	e.StartAt = o.start.In(s.loc).Format(time.RFC3339)
+	if !o.end.IsZero() {
+		e.EndAt = o.end.In(s.loc).Format(time.RFC3339)
+	}
+	e.Location = o.location
+	e.Cancelled = o.cancelled
+	e.CreatedAt = s.created
 
 	// 2. On récupère les infos du groupe
-	query = `SELECT TITLE, IMAGE FROM ALL_GROUPS WHERE ID = ?`
+	query := `SELECT TITLE, IMAGE FROM ALL_GROUPS WHERE ID = ?`
 	err = db.QueryRow(query, e.GroupID).Scan(&e.GroupName, &groupPic)
 	if err != nil {
 		return e, err
@@ -296,7 +412,7 @@ func getEventGroupNotificationData(db *sql.DB, groupEventID string) (EventGroupN
 	}
 
 	// 3. On récupère l'utilisateur qui a créé l'event
-	e.User, err = getUserByID(db, senderID)
+	e.User, err = getUserByID(db, s.sender)
 	if err != nil {
 		return e, err
 	}
@@ -30,10 +30,20 @@ func CanPassPostImage(db *sql.DB, userID, imgID string) error {
 		return nil
 	}
 
+	if err = checkNotBlocked(db, userID, userPostId); err != nil {
+		return err
+	}
+
+	visible, err := canSeePost(db, userID, postId)
+	if err != nil {
+		return errors.Wrap(err, "CanPassPostImage")
+	}
+	if !visible {
+		return ErrPostNotFound
+	}
+
 	if groupId.Valid {
-		var isExist bool
-		query = `SELECT EXISTS(SELECT 1 FROM GROUPS_MEMBERS WHERE USER_ID= ? AND GROUP_ID= ? LIMIT 1)`
-		err = db.QueryRow(query, userID, groupId).Scan(&isExist)
+		isExist, err := canReadGroupContent(db, userID, groupId.String)
 		if err != nil {
 			return errors.Wrap(err, "CanPassPostImage")
 		}
@@ -59,8 +69,7 @@ func CanPassPostImage(db *sql.DB, userID, imgID string) error {
 		}
 	} else if privacy == 0 {
 		var private bool
-		query = `SELECT EXISTS (SELECT 1 FROM LIST_PRIVATE_POST WHERE POST_ID = ? AND USER_ID = ?)`
-		err = db.QueryRow(query, postId, userID).Scan(&private)
+		private, err = hasPrivatePostAccess(db, userID, postId)
 		if err != nil {
 			return err
 		}
@@ -6,25 +6,31 @@ import (
 )
 
 type PostProfile struct {
-	Id           string   `json:"id"`                // x
-	UserId       string   `json:"userId"`            // x
-	FirstName    string   `json:"first_name"`        // x
-	LastName     string   `json:"last_name"`         // x
-	Username     string   `json:"username"`          // null x
-	ImageProfile string   `json:"image_profile_url"` // null x
-	Content      string   `json:"content"`           // x
-	Tags         []string `json:"tags"`              // null x
-	ImageContent string   `json:"image_content_url"` // null x
-	CreatedAt    string   `json:"created_at"`        // x
-	Liked        bool     `json:"liked"`             // x
-	Disliked     bool     `json:"disliked"`
-	LikeCount    int      `json:"like_count"`    // x
-	DislikeCount int      `json:"dislike_count"` // x
-	CommentCount int      `json:"comment_count"`
-	Followed     bool     `json:"followed"` // x
-	GroupId      GroupId  `json:"group_id"` // null x
-	OwnerUserId  bool     `json:"owner_user_id"`
-	Privacy      string   `json:"privacy"`
+	Id             string   `json:"id"`                // x
+	UserId         string   `json:"userId"`            // x
+	FirstName      string   `json:"first_name"`        // x
+	LastName       string   `json:"last_name"`         // x
+	Username       string   `json:"username"`          // null x
+	ImageProfile   string   `json:"image_profile_url"` // null x
+	Content        string   `json:"content"`           // x
+	Tags           []string `json:"tags"`              // null x
+	ImageContent   string   `json:"image_content_url"` // null x
+	CreatedAt      string   `json:"created_at"`        // x
+	Liked          bool     `json:"liked"`             // x
+	Disliked       bool     `json:"disliked"`
+	LikeCount      int      `json:"like_count"`    // x
+	DislikeCount   int      `json:"dislike_count"` // x
+	CommentCount   int      `json:"comment_count"`
+	Followed       bool     `json:"followed"` // x
+	GroupId        GroupId  `json:"group_id"` // null x
+	OwnerUserId    bool     `json:"owner_user_id"`
+	Privacy        string   `json:"privacy"`
+	CommentPolicy  string   `json:"comment_policy"`
+	CommentsLocked bool     `json:"comments_locked"`
+	ContentWarning string   `json:"content_warning"` // null
+	Sensitive      bool     `json:"sensitive"`
+	Status         string   `json:"status,omitempty"`        // posts de groupe : published, pending ou rejected
+	RejectReason   string   `json:"reject_reason,omitempty"` // motif du refus, pour l'auteur
 }
 type GroupId struct {
 	Id          string `json:"id"`            // x
@@ -45,8 +51,14 @@ func SendPostProfile(db *sql.DB, userId, targetId string) ([]PostProfile, error)
 func structData(db *sql.DB, userId, targetId string, offset int) ([]PostProfile, error) {
 	var postProfile []PostProfile
 
-	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE,GROUP_ID, PRIVACY FROM POSTS WHERE USER_ID = ? ORDER BY CREATED_AT DESC LIMIT 20 OFFSET ? `
-	rows, err := db.Query(query, targetId, offset)
+	// Aucun post n'est visible entre deux utilisateurs qui se sont bloqués
+	blocked, err := isBlocked(db, userId, targetId)
+	if err != nil || blocked {
+		return postProfile, err
+	}
+
+	query := `SELECT ID, CONTENT, USER_ID, CREATED_AT, IMAGE,GROUP_ID, PRIVACY, COMMENT_POLICY, COMMENTS_LOCKED, IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM POSTS WHERE USER_ID = ? AND (STATUS = 'published' OR USER_ID = ?) ORDER BY CREATED_AT DESC LIMIT 20 OFFSET ? `
+	rows, err := db.Query(query, targetId, userId, offset)
 	if err != nil {
 		return postProfile, err
 	}
@@ -75,6 +87,10 @@ func structData(db *sql.DB, userId, targetId string, offset int) ([]PostProfile,
 			&imageContent,
 			&groupId,
 			&privacy,
+			&p.CommentPolicy,
+			&p.CommentsLocked,
+			&p.ContentWarning,
+			&p.Sensitive,
 		)
 		if err != nil {
 			return postProfile, err
@@ -113,8 +129,7 @@ func structData(db *sql.DB, userId, targetId string, offset int) ([]PostProfile,
 				continue
 			}
 		} else if privacy == 0 && targetId != userId {
-			query = `SELECT EXISTS(SELECT ID FROM LIST_PRIVATE_POST WHERE USER_ID = ? AND POST_ID = ?)`
-			err = db.QueryRow(query, userId, p.Id).Scan(&accessPrivate)
+			accessPrivate, err = hasPrivatePostAccess(db, userId, p.Id)
 			if err != nil {
 				log.Printf("Erreur lors de la vérification d'accès privé : %v", err)
 				continue
@@ -171,8 +186,7 @@ func structData(db *sql.DB, userId, targetId string, offset int) ([]PostProfile,
 			continue
 		}
 
-		query = `SELECT COUNT(*) FROM COMMENT WHERE POST_ID = ?`
-		err = db.QueryRow(query, p.Id).Scan(&p.CommentCount)
+		err = db.QueryRow(visibleCommentCount, p.Id, userId).Scan(&p.CommentCount)
 		if err != nil {
 			continue
 		}
@@ -192,6 +206,7 @@ func structData(db *sql.DB, userId, targetId string, offset int) ([]PostProfile,
 			p.ImageContent = imageContent.String
 		}
 
+		RecordPostView(userId, p.Id, p.UserId)
 		postProfile = append(postProfile, p)
 
 	}
@@ -13,7 +13,13 @@ func SendPostWithTags(db *sql.DB, userID, tag string) (PostTag, error) {
 	log.Println("SendPostWithTags - start")
 	log.Printf("Tag demandé : %s | UserID : %s\n", tag, userID)
 
-	query := `SELECT POST_ID FROM TAGS WHERE TAG = ?`
+	blocked, err := blockedUserIds(db, userID)
+	if err != nil {
+		return PostTag{}, err
+	}
+
+	// Les posts de groupe en attente de validation ne sont pas encore visibles
+	query := `SELECT t.POST_ID FROM TAGS t JOIN POSTS p ON p.ID = t.POST_ID WHERE t.TAG = ? AND p.STATUS = 'published'`
 	log.Println("Exécution requête : récupération des POST_ID liés au tag")
 	rows, err := db.Query(query, tag)
 	if err != nil {
@@ -36,13 +42,17 @@ func SendPostWithTags(db *sql.DB, userID, tag string) (PostTag, error) {
 		var imgUser, imgContent, imgGroup, username, groupID sql.NullString
 		var private int
 
-		query = `SELECT CONTENT, USER_ID, CREATED_AT, IMAGE, GROUP_ID, PRIVACY FROM POSTS WHERE ID = ? `
-		err = db.QueryRow(query, p.Id).Scan(&p.Content, &p.UserId, &p.CreatedAt, &imgContent, &groupID, &private)
+		query = `SELECT CONTENT, USER_ID, CREATED_AT, IMAGE, GROUP_ID, PRIVACY, COMMENT_POLICY, COMMENTS_LOCKED, IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM POSTS WHERE ID = ? `
+		err = db.QueryRow(query, p.Id).Scan(&p.Content, &p.UserId, &p.CreatedAt, &imgContent, &groupID, &private, &p.CommentPolicy, &p.CommentsLocked, &p.ContentWarning, &p.Sensitive)
 		if err != nil {
 			log.Printf("Erreur récupération post %s : %v", p.Id, err)
 			return PostTag{}, err
 		}
 
+		if blocked[p.UserId] {
+			continue
+		}
+
 		if imgContent.Valid {
 			p.ImageContent = imgContent.String
 		}
@@ -79,8 +89,7 @@ func SendPostWithTags(db *sql.DB, userID, tag string) (PostTag, error) {
 			}
 		} else if private == 0 {
 			var isInPrivateList bool
-			query = `SELECT EXISTS( SELECT 1 FROM  LIST_PRIVATE_POST WHERE USER_ID = ? AND POST_ID = ?)`
-			err = db.QueryRow(query, userID, p.Id).Scan(&isInPrivateList)
+			isInPrivateList, err = hasPrivatePostAccess(db, userID, p.Id)
 			if err != nil {
 				return PostTag{}, err
 			}
@@ -147,8 +156,7 @@ func SendPostWithTags(db *sql.DB, userID, tag string) (PostTag, error) {
 			return PostTag{}, err
 		}
 
-		query = `SELECT COUNT(*) FROM COMMENT WHERE POST_ID = ?`
-		err = db.QueryRow(query, p.Id).Scan(&p.CommentCount)
+		err = db.QueryRow(visibleCommentCount, p.Id, userID).Scan(&p.CommentCount)
 		if err != nil {
 			log.Printf("Erreur CommentCount pour post %s : %v", p.Id, err)
 			return PostTag{}, err
@@ -157,6 +165,7 @@ func SendPostWithTags(db *sql.DB, userID, tag string) (PostTag, error) {
 		p.OwnerUserId = userID == p.UserId
 		log.Printf("Post %s ajouté à la liste (Owner: %v)", p.Id, p.OwnerUserId)
 
+		RecordPostView(userID, p.Id, p.UserId)
 		posts = append(posts, p)
 	}
 
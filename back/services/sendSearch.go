@@ -9,16 +9,22 @@ type SearchStruct struct {
 	Data interface{} `json:"data"`
 }
 
-func SendSearch(db *sql.DB, param string) ([]SearchStruct, error) {
+// SendSearch cherche les utilisateurs et les groupes. Les utilisateurs bloqués dans un sens
+// ou dans l'autre n'apparaissent pas.
+func SendSearch(db *sql.DB, userId, param string) ([]SearchStruct, error) {
 	query := `
 		SELECT ID, LASTNAME, FIRSTNAME, IMAGE, USERNAME
-		FROM USER
-		WHERE USERNAME LIKE ? OR LASTNAME LIKE ? OR FIRSTNAME LIKE ?
+		FROM USER u
+		WHERE (USERNAME LIKE ?1 OR LASTNAME LIKE ?1 OR FIRSTNAME LIKE ?1)
+		  AND NOT EXISTS(
+			SELECT 1 FROM BLOCKS b
+			WHERE (b.BLOCKER_ID = ?2 AND b.BLOCKED_ID = u.ID) OR (b.BLOCKER_ID = u.ID AND b.BLOCKED_ID = ?2)
+		  )
 		LIMIT 20
 	`
 
 	likeParam := "%" + param + "%"
-	rows, err := db.Query(query, likeParam, likeParam, likeParam)
+	rows, err := db.Query(query, likeParam, userId)
 	if err != nil {
 		return nil, err
 	}
@@ -47,8 +53,9 @@ func SendSearch(db *sql.DB, param string) ([]SearchStruct, error) {
 		})
 	}
 
-	groupQuery := `SELECT ID, TITLE, IMAGE,CREATED_AT FROM ALL_GROUPS WHERE TITLE LIKE ?`
-	rowsGroups, err := db.Query(groupQuery, likeParam)
+	// Les groupes secrets ne sont trouvables que par leurs membres et invités
+	groupQuery := `SELECT ag.ID, ag.TITLE, ag.IMAGE, ag.CREATED_AT FROM ALL_GROUPS ag WHERE ag.TITLE LIKE ?2 AND ` + visibleGroup
+	rowsGroups, err := db.Query(groupQuery, userId, likeParam)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+type Story struct {
+	Id        string `json:"id"`
+	UserId    string `json:"user_id"`
+	Content   string `json:"content"`   // null
+	Image     string `json:"image_url"` // null
+	Privacy   string `json:"privacy"`
+	CreatedAt string `json:"created_at"`
+	ExpireAt  string `json:"expire_at"`
+	Seen      bool   `json:"seen"`
+	ViewCount int    `json:"view_count"` // uniquement pour l'auteur
+}
+
+type StoryTray struct {
+	User        User   `json:"user"`
+	StoryCount  int    `json:"story_count"`
+	UnseenCount int    `json:"unseen_count"`
+	Seen        bool   `json:"seen"`
+	IsOwn       bool   `json:"is_own"`
+	LatestAt    string `json:"latest_at"`
+}
+
+type StoryViewer struct {
+	User     User   `json:"user"`
+	ViewedAt string `json:"viewed_at"`
+}
+
+// canViewStory applique à une story les mêmes règles de confidentialité que pour les posts.
+func canViewStory(db *sql.DB, viewerId, storyId, authorId string, privacy int) (bool, error) {
+	if viewerId == authorId {
+		return true, nil
+	}
+
+	blocked, err := isBlocked(db, viewerId, authorId)
+	if err != nil || blocked {
+		return false, err
+	}
+
+	var allowed bool
+	switch privacy {
+	case PrivacyPublic:
+		return true, nil
+	case PrivacyFriends:
+		query := `SELECT EXISTS(SELECT 1 FROM FOLLOWERS WHERE USER_ID = ? AND FOLLOWERS = ?)`
+		err = db.QueryRow(query, authorId, viewerId).Scan(&allowed)
+	case PrivacyPrivate:
+		query := `SELECT EXISTS(SELECT 1 FROM LIST_PRIVATE_STORY WHERE STORY_ID = ? AND USER_ID = ?)`
+		err = db.QueryRow(query, storyId, viewerId).Scan(&allowed)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return allowed, nil
+}
+
+// SendStoryTray renvoie les auteurs ayant des stories actives visibles par l'utilisateur.
+// Ses propres stories viennent en premier, puis les auteurs avec des stories non vues, puis les autres,
+// chaque groupe étant trié de la story la plus récente à la plus ancienne.
+func SendStoryTray(db *sql.DB, userId string) ([]StoryTray, error) {
+	var tray []StoryTray
+
+	query := `
+		SELECT s.ID, s.USER_ID, s.PRIVACY, s.CREATED_AT,
+		       EXISTS(SELECT 1 FROM STORY_VIEWS v WHERE v.STORY_ID = s.ID AND v.VIEWER_ID = ?)
+		FROM STORIES s
+		WHERE s.EXPIRE_AT > datetime('now')
+		ORDER BY s.CREATED_AT DESC
+	`
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	type storyRow struct {
+		id, authorId, createdAt string
+		privacy                 int
+		seen                    bool
+	}
+	var stories []storyRow
+	for rows.Next() {
+		var s storyRow
+		if err = rows.Scan(&s.id, &s.authorId, &s.privacy, &s.createdAt, &s.seen); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stories = append(stories, s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for _, s := range stories {
+		allowed, err := canViewStory(db, userId, s.id, s.authorId, s.privacy)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+
+		i, ok := index[s.authorId]
+		if !ok {
+			author, err := getUserByID(db, s.authorId)
+			if err != nil {
+				continue
+			}
+			tray = append(tray, StoryTray{User: author, IsOwn: s.authorId == userId, LatestAt: s.createdAt})
+			i = len(tray) - 1
+			index[s.authorId] = i
+		}
+
+		tray[i].StoryCount++
+		if !s.seen && s.authorId != userId {
+			tray[i].UnseenCount++
+		}
+	}
+
+	for i := range tray {
+		tray[i].Seen = tray[i].UnseenCount == 0
+	}
+
+	sort.SliceStable(tray, func(a, b int) bool {
+		if tray[a].IsOwn != tray[b].IsOwn {
+			return tray[a].IsOwn
+		}
+		if tray[a].Seen != tray[b].Seen {
+			return !tray[a].Seen
+		}
+		return tray[a].LatestAt > tray[b].LatestAt
+	})
+
+	return tray, nil
+}
+
+// SendUserStories renvoie les stories actives d'un auteur visibles par l'utilisateur, de la plus ancienne à la plus récente.
+func SendUserStories(db *sql.DB, userId, authorId string) ([]Story, error) {
+	var stories []Story
+
+	query := `
+		SELECT s.ID, s.USER_ID, s.CONTENT, s.IMAGE, s.PRIVACY, s.CREATED_AT, s.EXPIRE_AT,
+		       EXISTS(SELECT 1 FROM STORY_VIEWS v WHERE v.STORY_ID = s.ID AND v.VIEWER_ID = ?),
+		       (SELECT COUNT(*) FROM STORY_VIEWS v WHERE v.STORY_ID = s.ID)
+		FROM STORIES s
+		WHERE s.USER_ID = ? AND s.EXPIRE_AT > datetime('now')
+		ORDER BY s.CREATED_AT ASC
+	`
+	rows, err := db.Query(query, userId, authorId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s Story
+		var content, image sql.NullString
+		var privacy int
+
+		err = rows.Scan(&s.Id, &s.UserId, &content, &image, &privacy, &s.CreatedAt, &s.ExpireAt, &s.Seen, &s.ViewCount)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed, err := canViewStory(db, userId, s.Id, s.UserId, privacy)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+
+		if content.Valid {
+			s.Content = content.String
+		}
+		if image.Valid {
+			s.Image = image.String
+		}
+		if userId != s.UserId {
+			s.ViewCount = 0
+		}
+
+		switch privacy {
+		case PrivacyPrivate:
+			s.Privacy = "ListPrivate"
+		case PrivacyFriends:
+			s.Privacy = "private"
+		case PrivacyPublic:
+			s.Privacy = "public"
+		}
+
+		stories = append(stories, s)
+	}
+
+	return stories, rows.Err()
+}
+
+// ViewStory enregistre la vue d'une story. Les vues de l'auteur ne sont pas comptées.
+func ViewStory(db *sql.DB, userId, storyId string) error {
+	var authorId string
+	var privacy int
+	query := `SELECT USER_ID, PRIVACY FROM STORIES WHERE ID = ? AND EXPIRE_AT > datetime('now')`
+	err := db.QueryRow(query, storyId).Scan(&authorId, &privacy)
+	if err == sql.ErrNoRows {
+		return errors.New("story not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	allowed, err := canViewStory(db, userId, storyId, authorId, privacy)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("you are not allowed to view this story")
+	}
+	if authorId == userId {
+		return nil
+	}
+
+	query = `INSERT OR IGNORE INTO STORY_VIEWS(ID, STORY_ID, VIEWER_ID, CREATED_AT) VALUES (?, ?, ?, datetime('now'))`
+	_, err = db.Exec(query, uuid.New().String(), storyId, userId)
+	return err
+}
+
+// SendStoryViewers renvoie à l'auteur la liste des utilisateurs ayant vu sa story.
+func SendStoryViewers(db *sql.DB, userId, storyId string) ([]StoryViewer, error) {
+	var viewers []StoryViewer
+
+	var authorId string
+	err := db.QueryRow(`SELECT USER_ID FROM STORIES WHERE ID = ?`, storyId).Scan(&authorId)
+	if err == sql.ErrNoRows {
+		return nil, errors.New("story not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if authorId != userId {
+		return nil, errors.New("you are not the owner of this story")
+	}
+
+	rows, err := db.Query(`SELECT VIEWER_ID, CREATED_AT FROM STORY_VIEWS WHERE STORY_ID = ? ORDER BY CREATED_AT DESC`, storyId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v StoryViewer
+		var viewerId string
+		if err = rows.Scan(&viewerId, &v.ViewedAt); err != nil {
+			return nil, err
+		}
+		v.User, err = getUserByID(db, viewerId)
+		if err != nil {
+			return nil, err
+		}
+		viewers = append(viewers, v)
+	}
+
+	return viewers, rows.Err()
+}
+
+// CanPassStoryImage vérifie que l'utilisateur peut voir l'image d'une story active.
+func CanPassStoryImage(db *sql.DB, userID, imgID string) error {
+	var storyId, authorId string
+	var privacy int
+	query := `SELECT ID, USER_ID, PRIVACY FROM STORIES WHERE IMAGE = ? AND EXPIRE_AT > datetime('now') LIMIT 1`
+	err := db.QueryRow(query, imgID).Scan(&storyId, &authorId, &privacy)
+	if err != nil {
+		return errors.Wrap(err, "CanPassStoryImage")
+	}
+
+	allowed, err := canViewStory(db, userID, storyId, authorId, privacy)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errors.New("User is not allowed to view this story")
+	}
+
+	return nil
+}
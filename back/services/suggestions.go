@@ -0,0 +1,314 @@
+package services
+
+import (
+	"database/sql"
+	"github.com/pkg/errors"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSuggestions = 20
+	MaxSuggestions     = 50
+	// Nombre de candidats gardés en cache : de quoi compléter la liste après les exclusions
+	cachedSuggestions = 100
+)
+
+// Poids des signaux dans le score
+const (
+	weightMutual     = 3.0
+	weightGroup      = 2.0
+	weightTag        = 1.0
+	weightPopularity = 0.5
+)
+
+type Suggestion struct {
+	User          User    `json:"user"`
+	Score         float64 `json:"score"`
+	MutualFollows int     `json:"mutual_follows"`
+	SharedGroups  int     `json:"shared_groups"`
+	SharedTags    int     `json:"shared_tags"`
+	Followers     int     `json:"followers"` // renseigné pour les profils publics
+}
+
+type cachedSuggestion struct {
+	requestedAt time.Time
+	suggestions []Suggestion
+}
+
+// Les suggestions sont calculées à la demande puis gardées en mémoire ;
+// StartSuggestionsRefresh les recalcule périodiquement.
+var suggestionsCache = struct {
+	sync.Mutex
+	byUser map[string]cachedSuggestion
+}{
+	byUser: make(map[string]cachedSuggestion),
+}
+
+// SendSuggestions renvoie les utilisateurs suggérés, du meilleur score au plus faible.
+// Les exclusions (abonnements, blocages, demandes en attente, suggestions écartées)
+// sont appliquées à la lecture pour refléter l'état courant malgré le cache.
+func SendSuggestions(db *sql.DB, userId string, limit int) ([]Suggestion, error) {
+	if limit <= 0 || limit > MaxSuggestions {
+		return nil, errors.New("limit must be between 1 and 50")
+	}
+
+	suggestionsCache.Lock()
+	cached, ok := suggestionsCache.byUser[userId]
+	if ok {
+		cached.requestedAt = time.Now()
+		suggestionsCache.byUser[userId] = cached
+	}
+	suggestionsCache.Unlock()
+
+	if !ok {
+		var err error
+		cached, err = refreshSuggestions(db, userId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	excluded, err := excludedSuggestionIds(db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Suggestion
+	for _, s := range cached.suggestions {
+		if len(result) >= limit {
+			break
+		}
+		if excluded[s.User.ID] {
+			continue
+		}
+		user, err := getUserByID(db, s.User.ID)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.User = user
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+// DismissSuggestion écarte définitivement un utilisateur des suggestions.
+func DismissSuggestion(db *sql.DB, userId, targetId string) error {
+	if userId == targetId {
+		return errors.New("you cannot dismiss yourself")
+	}
+
+	var exists bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM USER WHERE ID = ?)`, targetId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("user not found")
+	}
+
+	query := `INSERT OR IGNORE INTO SUGGESTION_DISMISSALS(USER_ID, DISMISSED_ID, CREATED_AT) VALUES (?, ?, datetime('now'))`
+	_, err = db.Exec(query, userId, targetId)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert dismissal")
+	}
+
+	return nil
+}
+
+// StartSuggestionsRefresh lance en tâche de fond le recalcul des suggestions en cache.
+// Les utilisateurs qui n'ont pas consulté leurs suggestions depuis un jour sont retirés du cache.
+func StartSuggestionsRefresh(db *sql.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			suggestionsCache.Lock()
+			var users []string
+			for userId, cached := range suggestionsCache.byUser {
+				if time.Since(cached.requestedAt) > 24*time.Hour {
+					delete(suggestionsCache.byUser, userId)
+					continue
+				}
+				users = append(users, userId)
+			}
+			suggestionsCache.Unlock()
+
+			for _, userId := range users {
+				if _, err := refreshSuggestions(db, userId); err != nil {
+					log.Println("Erreur lors du calcul des suggestions :", err)
+				}
+			}
+		}
+	}()
+}
+
+// refreshSuggestions calcule les suggestions de l'utilisateur et met le cache à jour.
+func refreshSuggestions(db *sql.DB, userId string) (cachedSuggestion, error) {
+	suggestions, err := computeSuggestions(db, userId)
+	if err != nil {
+		return cachedSuggestion{}, err
+	}
+
+	cached := cachedSuggestion{requestedAt: time.Now(), suggestions: suggestions}
+
+	suggestionsCache.Lock()
+	// Un recalcul en tâche de fond ne compte pas comme une consultation
+	if previous, ok := suggestionsCache.byUser[userId]; ok {
+		cached.requestedAt = previous.requestedAt
+	}
+	suggestionsCache.byUser[userId] = cached
+	suggestionsCache.Unlock()
+
+	return cached, nil
+}
+
+// computeSuggestions agrège les signaux : amis d'amis, groupes en commun,
+// tags en commun (posts publics du candidat / posts aimés, commentés ou écrits par l'utilisateur)
+// et popularité des profils publics.
+func computeSuggestions(db *sql.DB, userId string) ([]Suggestion, error) {
+	candidates := make(map[string]*Suggestion)
+	get := func(id string) *Suggestion {
+		s, ok := candidates[id]
+		if !ok {
+			s = &Suggestion{User: User{ID: id}}
+			candidates[id] = s
+		}
+		return s
+	}
+
+	signals := []struct {
+		query string
+		args  []interface{}
+		set   func(s *Suggestion, count int)
+	}{
+		{
+			// f1 : l'utilisateur suit F ; f2 : F suit le candidat
+			query: `SELECT f2.USER_ID, COUNT(DISTINCT f1.USER_ID) FROM FOLLOWERS f1
+			        JOIN FOLLOWERS f2 ON f2.FOLLOWERS = f1.USER_ID
+			        WHERE f1.FOLLOWERS = ?1 AND f2.USER_ID != ?1
+			        GROUP BY f2.USER_ID`,
+			args: []interface{}{userId},
+			set:  func(s *Suggestion, count int) { s.MutualFollows = count },
+		},
+		{
+			query: `SELECT g2.USER_ID, COUNT(*) FROM GROUPS_MEMBERS g1
+			        JOIN GROUPS_MEMBERS g2 ON g2.GROUP_ID = g1.GROUP_ID
+			        WHERE g1.USER_ID = ?1 AND g2.USER_ID != ?1
+			        GROUP BY g2.USER_ID`,
+			args: []interface{}{userId},
+			set:  func(s *Suggestion, count int) { s.SharedGroups = count },
+		},
+		{
+			query: `SELECT p.USER_ID, COUNT(DISTINCT t.TAG) FROM POSTS p
+			        JOIN TAGS t ON t.POST_ID = p.ID
+			        WHERE p.USER_ID != ?1 AND p.GROUP_ID IS NULL AND p.PRIVACY = 2 AND p.STATUS = 'published'
+			          AND t.TAG IN (
+			            SELECT t2.TAG FROM TAGS t2 WHERE t2.POST_ID IN (
+			              SELECT POST_ID FROM POST_EVENT WHERE USER_ID = ?1 AND LIKED = 'liked'
+			              UNION SELECT POST_ID FROM COMMENT WHERE USER_ID = ?1
+			              UNION SELECT ID FROM POSTS WHERE USER_ID = ?1
+			            )
+			          )
+			        GROUP BY p.USER_ID`,
+			args: []interface{}{userId},
+			set:  func(s *Suggestion, count int) { s.SharedTags = count },
+		},
+		{
+			query: `SELECT u.ID, COUNT(f.ID) AS TOTAL FROM USER u
+			        JOIN FOLLOWERS f ON f.USER_ID = u.ID
+			        WHERE u.PUBLIC = 1 AND u.ID != ?1
+			        GROUP BY u.ID ORDER BY TOTAL DESC LIMIT ?2`,
+			args: []interface{}{userId, cachedSuggestions},
+			set:  func(s *Suggestion, count int) { s.Followers = count },
+		},
+	}
+
+	for _, signal := range signals {
+		rows, err := db.Query(signal.query, signal.args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			var count int
+			if err = rows.Scan(&id, &count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			signal.set(get(id), count)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	excluded, err := excludedSuggestionIds(db, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []Suggestion
+	for id, s := range candidates {
+		if excluded[id] {
+			continue
+		}
+		s.Score = weightMutual*float64(s.MutualFollows) +
+			weightGroup*float64(s.SharedGroups) +
+			weightTag*float64(s.SharedTags) +
+			weightPopularity*math.Log1p(float64(s.Followers))
+		s.Score = math.Round(s.Score*100) / 100
+		suggestions = append(suggestions, *s)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].User.ID < suggestions[j].User.ID
+	})
+	if len(suggestions) > cachedSuggestions {
+		suggestions = suggestions[:cachedSuggestions]
+	}
+
+	return suggestions, nil
+}
+
+// excludedSuggestionIds renvoie les utilisateurs à ne jamais suggérer : soi-même, les comptes déjà suivis,
+// les blocages dans les deux sens, les demandes d'abonnement en attente et les suggestions écartées.
+func excludedSuggestionIds(db *sql.DB, userId string) (map[string]bool, error) {
+	excluded := map[string]bool{userId: true}
+
+	query := `
+		SELECT USER_ID FROM FOLLOWERS WHERE FOLLOWERS = ?1
+		UNION SELECT BLOCKED_ID FROM BLOCKS WHERE BLOCKER_ID = ?1
+		UNION SELECT BLOCKER_ID FROM BLOCKS WHERE BLOCKED_ID = ?1
+		UNION SELECT RECEIVER_ID FROM REQUEST_FOLLOW WHERE ASKER_ID = ?1 AND STATUS = 'pending'
+		UNION SELECT ASKER_ID FROM REQUEST_FOLLOW WHERE RECEIVER_ID = ?1 AND STATUS = 'pending'
+		UNION SELECT DISMISSED_ID FROM SUGGESTION_DISMISSALS WHERE USER_ID = ?1
+	`
+	rows, err := db.Query(query, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		excluded[id] = true
+	}
+
+	return excluded, rows.Err()
+}
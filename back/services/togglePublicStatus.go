@@ -5,10 +5,18 @@ import (
 	"fmt"
 )
 
+// TogglePublicStatus bascule le compte entre public et privé, avec la visibilité de ses posts et stories.
+// Passer en public accepte les demandes en attente. Tout est fait dans une seule transaction.
 func TogglePublicStatus(db *sql.DB, userID string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("transaction begin failed: %v", err)
+	}
+	defer tx.Rollback()
+
 	// Récupérer le statut actuel (0 ou 1)
 	var currentStatus int
-	err := db.QueryRow("SELECT PUBLIC FROM USER WHERE ID = ?", userID).Scan(&currentStatus)
+	err = tx.QueryRow("SELECT PUBLIC FROM USER WHERE ID = ?", userID).Scan(&currentStatus)
 	if err != nil {
 		return fmt.Errorf("erreur lors de la récupération du statut actuel : %v", err)
 	}
@@ -20,27 +28,37 @@ func TogglePublicStatus(db *sql.DB, userID string) error {
 	}
 
 	// Mettre à jour le champ PUBLIC
-	_, err = db.Exec("UPDATE USER SET PUBLIC = ? WHERE ID = ?", newStatus, userID)
+	_, err = tx.Exec("UPDATE USER SET PUBLIC = ? WHERE ID = ?", newStatus, userID)
 	if err != nil {
 		return fmt.Errorf("erreur lors de la mise à jour du statut : %v", err)
 	}
 
 	// switch les post 2 = public, 1= followers, 0 = Liste privée
+	privacy := 1
 	if newStatus == 1 {
-		privacy := 2
-		query := `UPDATE POSTS SET PRIVACY = ? WHERE USER_ID = ? AND PRIVACY != 0`
-		_, err = db.Exec(query, privacy, userID)
-		if err != nil {
-			return err
-		}
-	} else if newStatus == 0 {
-		privacy := 1
-		query := `UPDATE POSTS SET PRIVACY = ? WHERE USER_ID = ? AND PRIVACY != 0`
-		_, err = db.Exec(query, privacy, userID)
+		privacy = 2
+	}
+	query := `UPDATE POSTS SET PRIVACY = ? WHERE USER_ID = ? AND PRIVACY != 0`
+	_, err = tx.Exec(query, privacy, userID)
+	if err != nil {
+		return err
+	}
+	query = `UPDATE STORIES SET PRIVACY = ? WHERE USER_ID = ? AND PRIVACY != 0`
+	_, err = tx.Exec(query, privacy, userID)
+	if err != nil {
+		return err
+	}
+
+	// Un compte public n'a plus de demandes en attente : elles sont acceptées et notifiées
+	if newStatus == 1 {
+		_, err = acceptPendingFollowRequests(tx, userID)
 		if err != nil {
-			return err
+			return fmt.Errorf("erreur lors de l'acceptation des demandes en attente : %v", err)
 		}
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("transaction commit failed: %v", err)
+	}
 	return nil
 }
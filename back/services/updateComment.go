@@ -6,7 +6,7 @@ import (
 	"fmt"
 )
 
-func UpdateComment(db *sql.DB, commentId, content, userId, img string) error {
+func UpdateComment(db *sql.DB, commentId, content, userId, img string, flags ContentFlags) error {
 	var ownerId string
 	err := db.QueryRow("SELECT USER_ID FROM Comment WHERE ID = ?", commentId).Scan(&ownerId)
 	if err != nil {
@@ -26,9 +26,9 @@ func UpdateComment(db *sql.DB, commentId, content, userId, img string) error {
 	}
 
 	if img != "" {
-		_, err = db.Exec(`UPDATE Comment SET Content = ?, Image = ?, UPDATED_AT = datetime('now') WHERE ID = ?`, content, img, commentId)
+		_, err = db.Exec(`UPDATE Comment SET Content = ?, Image = ?, CONTENT_WARNING = ?, SENSITIVE = ?, UPDATED_AT = datetime('now') WHERE ID = ?`, content, img, toNullString(flags.Warning), flags.Sensitive, commentId)
 	} else {
-		_, err = db.Exec(`UPDATE Comment SET Content = ?, UPDATED_AT = datetime('now') WHERE ID = ?`, content, commentId)
+		_, err = db.Exec(`UPDATE Comment SET Content = ?, CONTENT_WARNING = ?, SENSITIVE = ?, UPDATED_AT = datetime('now') WHERE ID = ?`, content, toNullString(flags.Warning), flags.Sensitive, commentId)
 	}
 	if err != nil {
 		return fmt.Errorf("erreur lors de la mise à jour du commentaire : %v", err)
@@ -8,7 +8,7 @@ import (
 	"strings"
 )
 
-func UpdatePost(db *sql.DB, userId, postId, content, tags, image string) error {
+func UpdatePost(db *sql.DB, userId, postId, content, tags, image string, flags ContentFlagsUpdate) error {
 	var dbUserID string
 
 	if strings.TrimSpace(content) == "" {
@@ -41,6 +41,17 @@ func UpdatePost(db *sql.DB, userId, postId, content, tags, image string) error {
 		values = append(values, image)
 	}
 
+	// Les signalements ne changent que s'ils sont envoyés : modifier le texte ne les efface pas
+	if flags.Warning != nil {
+		fields = append(fields, "CONTENT_WARNING")
+		values = append(values, toNullString(*flags.Warning))
+	}
+
+	if flags.Sensitive != nil {
+		fields = append(fields, "SENSITIVE")
+		values = append(values, *flags.Sensitive)
+	}
+
 	if len(fields) == 0 {
 		return errors.New("no fields")
 	}
@@ -58,6 +69,11 @@ func UpdatePost(db *sql.DB, userId, postId, content, tags, image string) error {
 		return fmt.Errorf("error updating post owner: %w", err)
 	}
 
+	err = savePostMentions(db, postId, userId, content)
+	if err != nil {
+		return fmt.Errorf("error updating post mentions: %w", err)
+	}
+
 	query = `DELETE FROM TAGS WHERE POST_ID = ?`
 	_, err = db.Exec(query, postId)
 	if err != nil {
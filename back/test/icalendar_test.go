@@ -0,0 +1,57 @@
+package test
+
+import (
+	"social-network/services"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Test du repli des lignes iCalendar (RFC 5545, 3.1)
+func TestFoldICSLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		lines int // nombre de lignes physiques attendu
+	}{
+		{"SUMMARY", "Soirée bowling", 1},
+		{"SUMMARY", strings.Repeat("a", 75-len("SUMMARY:")), 1}, // exactement 75 octets
+		{"SUMMARY", strings.Repeat("a", 76-len("SUMMARY:")), 2},
+		{"DESCRIPTION", strings.Repeat("x", 300), 5},
+		{"DESCRIPTION", strings.Repeat("é", 100), 3}, // caractères de 2 octets
+		{"LOCATION", strings.Repeat("🎳", 40), 3},     // caractères de 4 octets
+	}
+
+	for _, tt := range tests {
+		folded := services.FoldICSLine(tt.name, tt.value)
+
+		if !strings.HasSuffix(folded, "\r\n") {
+			t.Errorf("Échec: %s ne se termine pas par CRLF", tt.name)
+			continue
+		}
+		if strings.Count(folded, "\n") != strings.Count(folded, "\r\n") || strings.Count(folded, "\r") != strings.Count(folded, "\r\n") {
+			t.Errorf("Échec: %s contient un CR ou un LF isolé", tt.name)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+		if len(lines) != tt.lines {
+			t.Errorf("Échec: %s replié en %d lignes au lieu de %d", tt.name, len(lines), tt.lines)
+		}
+		for i, l := range lines {
+			if len(l) > 75 {
+				t.Errorf("Échec: %s, ligne %d de %d octets", tt.name, i, len(l))
+			}
+			if i > 0 && !strings.HasPrefix(l, " ") {
+				t.Errorf("Échec: %s, la ligne %d ne commence pas par une espace", tt.name, i)
+			}
+			if !utf8.ValidString(l) {
+				t.Errorf("Échec: %s, la ligne %d coupe un caractère UTF-8", tt.name, i)
+			}
+		}
+
+		// Le dépliage redonne la ligne d'origine
+		if unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); unfolded != tt.name+":"+tt.value {
+			t.Errorf("Échec: %s déplié en %q", tt.name, unfolded)
+		}
+	}
+}
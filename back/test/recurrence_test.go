@@ -0,0 +1,83 @@
+package test
+
+import (
+	"slices"
+	"social-network/services"
+	"testing"
+	"time"
+)
+
+// Test de l'expansion des règles de récurrence des événements
+func TestExpandRecurrence(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC) // un mercredi
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		want  []string // heures locales "2006-01-02 15:04", nil si une erreur est attendue
+		count int      // nombre d'occurrences attendu quand want n'est pas détaillé
+	}{
+		{"règle vide", "", start, []string{"2025-01-01 10:00"}, 0},
+		{"BYDAY avec INTERVAL", "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE,MO;COUNT=5", start,
+			[]string{"2025-01-01 10:00", "2025-01-13 10:00", "2025-01-15 10:00", "2025-01-27 10:00", "2025-01-29 10:00"}, 0},
+		{"COUNT", "FREQ=DAILY;COUNT=3", start,
+			[]string{"2025-01-01 10:00", "2025-01-02 10:00", "2025-01-03 10:00"}, 0},
+		{"UNTIL jour inclus", "FREQ=DAILY;UNTIL=20250103", start,
+			[]string{"2025-01-01 10:00", "2025-01-02 10:00", "2025-01-03 10:00"}, 0},
+		{"UNTIL avant l'heure", "FREQ=DAILY;UNTIL=20250103T090000Z", start,
+			[]string{"2025-01-01 10:00", "2025-01-02 10:00"}, 0},
+		{"COUNT et UNTIL", "FREQ=DAILY;COUNT=3;UNTIL=20250103", start, nil, 0},
+		{"ni COUNT ni UNTIL", "FREQ=DAILY", start, nil, 0},
+		{"mois sans le jour", "FREQ=MONTHLY;COUNT=3", time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC),
+			[]string{"2025-01-31 10:00", "2025-03-31 10:00", "2025-05-31 10:00"}, 0},
+		{"500 occurrences", "FREQ=DAILY;COUNT=500", start, []string{}, 500},
+		{"COUNT au-delà de 500", "FREQ=DAILY;COUNT=501", start, nil, 0},
+		{"plus de 500 occurrences avec UNTIL", "FREQ=DAILY;UNTIL=20260601", start, nil, 0},
+		{"2 ans pile", "FREQ=MONTHLY;COUNT=25", start, []string{}, 25},
+		{"COUNT au-delà de 2 ans", "FREQ=MONTHLY;COUNT=26", start, nil, 0},
+		{"UNTIL au-delà de 2 ans", "FREQ=MONTHLY;UNTIL=20270102", start, nil, 0},
+		{"passage à l'heure d'été", "FREQ=WEEKLY;COUNT=3", time.Date(2025, 3, 23, 10, 0, 0, 0, paris),
+			[]string{"2025-03-23 10:00", "2025-03-30 10:00", "2025-04-06 10:00"}, 0},
+	}
+
+	for _, tt := range tests {
+		got, err := services.ExpandRecurrence(tt.rule, tt.start)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("Échec: %s (%s) devrait être refusée", tt.name, tt.rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Échec: %s (%s) : %v", tt.name, tt.rule, err)
+			continue
+		}
+		if len(tt.want) == 0 {
+			if len(got) != tt.count {
+				t.Errorf("Échec: %s (%s) : %d occurrences au lieu de %d", tt.name, tt.rule, len(got), tt.count)
+			}
+			continue
+		}
+		var local []string
+		for _, o := range got {
+			local = append(local, o.In(tt.start.Location()).Format("2006-01-02 15:04"))
+		}
+		if !slices.Equal(local, tt.want) {
+			t.Errorf("Échec: %s (%s) : %v au lieu de %v", tt.name, tt.rule, local, tt.want)
+		}
+	}
+
+	// L'heure locale est conservée : le décalage UTC change avec l'heure d'été
+	got, err := services.ExpandRecurrence("FREQ=WEEKLY;COUNT=2", time.Date(2025, 3, 23, 10, 0, 0, 0, paris))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].UTC().Hour() != 9 || got[1].UTC().Hour() != 8 {
+		t.Errorf("Échec: changement d'heure mal géré : %v", got)
+	}
+}
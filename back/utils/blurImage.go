@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	blurredDir     = "Images/blurred"
+	blurSampleSize = 16  // taille du plus grand côté de la miniature floutée
+	blurOutputSize = 480 // taille max du plus grand côté de l'image servie
+)
+
+// BlurredImage renvoie le chemin d'une version floutée de Images/<typeImg>/<id>.
+// La variante est générée au premier appel puis conservée dans Images/blurred/.
+func BlurredImage(typeImg, id string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(id), filepath.Ext(id)) + ".jpg"
+	blurredPath := filepath.Join(blurredDir, filepath.Base(typeImg), name)
+
+	if _, err := os.Stat(blurredPath); err == nil {
+		return blurredPath, nil
+	}
+
+	src, err := os.Open(filepath.Join("Images", filepath.Base(typeImg), filepath.Base(id)))
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(filepath.Dir(blurredPath), os.ModePerm)
+	if err != nil {
+		return "", err
+	}
+
+	// Écriture dans un fichier temporaire pour ne jamais servir une variante incomplète
+	tmp, err := os.CreateTemp(filepath.Dir(blurredPath), "blur-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = jpeg.Encode(tmp, blur(img), &jpeg.Options{Quality: 70})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.Rename(tmp.Name(), blurredPath); err != nil {
+		return "", err
+	}
+
+	return blurredPath, nil
+}
+
+// blur réduit l'image à une miniature (moyenne par blocs) puis l'agrandit
+// par interpolation bilinéaire, ce qui donne un flou uniforme.
+func blur(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return img
+	}
+
+	sw, sh := scaledSize(w, h, blurSampleSize)
+	small := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	for y := 0; y < sh; y++ {
+		y0, y1 := bounds.Min.Y+y*h/sh, bounds.Min.Y+(y+1)*h/sh
+		for x := 0; x < sw; x++ {
+			x0, x1 := bounds.Min.X+x*w/sw, bounds.Min.X+(x+1)*w/sw
+			var r, g, b, n uint64
+			for py := y0; py < max(y1, y0+1); py++ {
+				for px := x0; px < max(x1, x0+1); px++ {
+					cr, cg, cb, _ := img.At(px, py).RGBA()
+					r, g, b, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), n+1
+				}
+			}
+			small.Set(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), 0xffff})
+		}
+	}
+
+	ow, oh := scaledSize(w, h, min(max(w, h), blurOutputSize))
+	out := image.NewRGBA(image.Rect(0, 0, ow, oh))
+	for y := 0; y < oh; y++ {
+		fy := (float64(y)+0.5)*float64(sh)/float64(oh) - 0.5
+		for x := 0; x < ow; x++ {
+			fx := (float64(x)+0.5)*float64(sw)/float64(ow) - 0.5
+			out.Set(x, y, bilinear(small, fx, fy))
+		}
+	}
+
+	return out
+}
+
+func bilinear(img *image.RGBA, fx, fy float64) color.RGBA {
+	maxX, maxY := img.Bounds().Dx()-1, img.Bounds().Dy()-1
+	x0, y0 := clamp(int(fx), maxX), clamp(int(fy), maxY)
+	x1, y1 := clamp(x0+1, maxX), clamp(y0+1, maxY)
+	tx, ty := min(max(fx-float64(x0), 0), 1), min(max(fy-float64(y0), 0), 1)
+
+	c00, c10 := img.RGBAAt(x0, y0), img.RGBAAt(x1, y0)
+	c01, c11 := img.RGBAAt(x0, y1), img.RGBAAt(x1, y1)
+	mix := func(a, b, c, d uint8) uint8 {
+		top := float64(a)*(1-tx) + float64(b)*tx
+		bottom := float64(c)*(1-tx) + float64(d)*tx
+		return uint8(top*(1-ty) + bottom*ty + 0.5)
+	}
+
+	return color.RGBA{
+		R: mix(c00.R, c10.R, c01.R, c11.R),
+		G: mix(c00.G, c10.G, c01.G, c11.G),
+		B: mix(c00.B, c10.B, c01.B, c11.B),
+		A: 0xff,
+	}
+}
+
+// scaledSize conserve les proportions en ramenant le plus grand côté à size.
+func scaledSize(w, h, size int) (int, int) {
+	if w >= h {
+		return size, max(1, h*size/w)
+	}
+	return max(1, w*size/h), size
+}
+
+func clamp(v, hi int) int {
+	return min(max(v, 0), hi)
+}
+
+// RemoveImage supprime Images/<typeImg>/<id> ainsi que sa variante floutée si elle existe.
+// Un fichier déjà absent n'est pas une erreur.
+func RemoveImage(typeImg, id string) error {
+	name := strings.TrimSuffix(filepath.Base(id), filepath.Ext(id)) + ".jpg"
+	paths := []string{
+		filepath.Join("Images", filepath.Base(typeImg), filepath.Base(id)),
+		filepath.Join(blurredDir, filepath.Base(typeImg), name),
+	}
+
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
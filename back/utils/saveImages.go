@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"io"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var allowedImages = map[string]bool{
@@ -18,31 +20,43 @@ var allowedImages = map[string]bool{
 }
 
 func SaveImage(dir string, file multipart.File, header *multipart.FileHeader) (string, error) {
+	fileName, contentType, err := SaveFile(dir, file, header, allowedImages)
+	if err == ErrUnsupportedFileType {
+		return "", fmt.Errorf("unsupported image type : %s", contentType)
+	}
+	return fileName, err
+}
+
+var ErrUnsupportedFileType = errors.New("unsupported file type")
+
+// SaveFile enregistre le fichier dans dir sous un nom aléatoire si son type, détecté sur son contenu,
+// figure dans allowed. Renvoie le nom du fichier et le type détecté (sans paramètres comme charset).
+func SaveFile(dir string, file multipart.File, header *multipart.FileHeader, allowed map[string]bool) (string, string, error) {
 	var u = uuid.New().String()
 
 	// path ou dl le l'image
 	uploadDir := fmt.Sprintf("%s/", dir)
 	err := os.MkdirAll(uploadDir, os.ModePerm) // Crée le dossier si le dossier n'existe pas
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	// lecture de l'image
+	// lecture du début du fichier
 	buffer := make([]byte, 512)
-	_, err = file.Read(buffer)
-	if err != nil {
-		return "", err
+	n, err := file.Read(buffer)
+	if err != nil && err != io.EOF {
+		return "", "", err
 	}
 
 	// Vérifie si le fichier est du bon type
-	contentType := http.DetectContentType(buffer)
-	if !allowedImages[contentType] {
-		return "", fmt.Errorf("unsupported image type : %s", contentType)
+	contentType, _, _ := strings.Cut(http.DetectContentType(buffer[:n]), ";")
+	if !allowed[contentType] {
+		return "", contentType, ErrUnsupportedFileType
 	}
 
 	_, err = file.Seek(0, io.SeekStart) // Réinitialisation du pointeur du fichier
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	fileExt := filepath.Ext(header.Filename)
 	fileName := u + fileExt
@@ -51,14 +65,14 @@ func SaveImage(dir string, file multipart.File, header *multipart.FileHeader) (s
 	// crée et enregistre le fichier
 	out, err := os.Create(filePath)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 	defer out.Close()
 
 	_, err = io.Copy(out, file)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	return fileName, nil
+	return fileName, contentType, nil
 }
@@ -5,25 +5,21 @@ import (
 	"encoding/json"
 	"github.com/gorilla/websocket"
 	"log"
+	"social-network/services"
 	"time"
 )
 
-func (h *Hub) SendGroupMessage(groupId, content, sender, convID, msgID string, isImage int, db *sql.DB) error {
-	// Récupération des membres du groupe
-	queryGroupMember := `SELECT USER_ID FROM GROUPS_MEMBERS WHERE GROUP_ID = ?`
-	rows, err := db.Query(queryGroupMember, groupId)
+func (h *Hub) SendGroupMessage(groupId, channelId, content, sender, convID, msgID string, isImage int, db *sql.DB) error {
+	// Récupération des membres ayant accès au salon et abonnés à celui-ci
+	members, err := services.GroupChannelSubscribers(db, groupId, channelId, sender)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
 
-	var members []string
-	for rows.Next() {
-		var memberID string
-		if err := rows.Scan(&memberID); err != nil {
-			continue
-		}
-		members = append(members, memberID)
+	// Les membres qui ont mis le groupe en sourdine ne reçoivent pas le push
+	members, err = services.UnmutedMembers(db, services.MuteGroup, groupId, sender, members)
+	if err != nil {
+		return err
 	}
 
 	// Récupération des infos de l'expéditeur
@@ -42,6 +38,11 @@ func (h *Hub) SendGroupMessage(groupId, content, sender, convID, msgID string, i
 		s.Sender.Username = username.String
 	}
 
+	err = s.loadContentFlags(msgID, db)
+	if err != nil {
+		log.Println(err)
+	}
+
 	s.Sender.Id = sender
 	s.Content = content
 	s.ConvId = convID
@@ -50,6 +51,7 @@ func (h *Hub) SendGroupMessage(groupId, content, sender, convID, msgID string, i
 	s.MessageId = msgID
 	s.IsImage = isImage == 1
 	s.GroupId = groupId // Ajouter le groupId
+	s.ChannelId = channelId
 
 	msgJSON, err := json.Marshal(s)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"github.com/gorilla/websocket"
 	"log"
+	"social-network/services"
 	"time"
 )
 
@@ -17,6 +18,9 @@ type SenderMessage struct {
 	IsImage   bool      `json:"isImage"`
 	Time      time.Time `json:"time"`
 	GroupId   string    `json:"groupId,omitempty"` // Nouveau champ pour les groupes
+	ChannelId string    `json:"channelId,omitempty"`
+	Warning   string    `json:"contentWarning,omitempty"`
+	Sensitive bool      `json:"sensitive,omitempty"`
 }
 
 type UserInfo struct {
@@ -28,10 +32,16 @@ type UserInfo struct {
 }
 
 func (h *Hub) SendPrivateMessage(members []string, content, sender, convID, msgID string, db *sql.DB) error {
+	// Les membres qui ont mis la conversation en sourdine ne reçoivent pas le push
+	members, err := services.UnmutedMembers(db, services.MuteConversation, convID, sender, members)
+	if err != nil {
+		return err
+	}
+
 	var s SenderMessage
 	var pp, username sql.NullString
 	query := `SELECT LASTNAME, FIRSTNAME, USERNAME, IMAGE FROM USER WHERE ID = ?`
-	err := db.QueryRow(query, sender).Scan(&s.Sender.LastName, &s.Sender.FirstName, &username, &pp)
+	err = db.QueryRow(query, sender).Scan(&s.Sender.LastName, &s.Sender.FirstName, &username, &pp)
 	if err != nil {
 		log.Println(err)
 	}
@@ -43,6 +53,11 @@ func (h *Hub) SendPrivateMessage(members []string, content, sender, convID, msgI
 		s.Sender.Username = username.String
 	}
 
+	err = s.loadContentFlags(msgID, db)
+	if err != nil {
+		log.Println(err)
+	}
+
 	s.Sender.Id = sender
 	s.Content = content
 	s.ConvId = convID
@@ -74,3 +89,9 @@ func (h *Hub) SendPrivateMessage(members []string, content, sender, convID, msgI
 
 	return nil
 }
+
+// loadContentFlags récupère l'avertissement de contenu et le marquage sensible du message
+func (s *SenderMessage) loadContentFlags(msgID string, db *sql.DB) error {
+	query := `SELECT IFNULL(CONTENT_WARNING, ''), SENSITIVE FROM MESSAGES WHERE ID = ?`
+	return db.QueryRow(query, msgID).Scan(&s.Warning, &s.Sensitive)
+}
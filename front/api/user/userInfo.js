@@ -129,7 +129,7 @@ export const getFollowing = async (userId) => {
 
 export const abortFollowRequest = async (userId) => {
     const response = await fetch(`http://localhost:80/api/user/abort?user=${userId}`, {
-        method: "GET",
+        method: "DELETE",
         credentials: "include"
     });
     return response.ok;
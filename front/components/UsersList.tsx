@@ -184,7 +184,7 @@ export default function UsersList({ title, type, initialUsers = [] }: UsersListP
   const handleCancelRequest = async (userId: string) => {
     try {
       const response = await fetch(`http://localhost:80/api/user/abort?user=${userId}`, {
-        method: 'GET',
+        method: 'DELETE',
         credentials: 'include'
       });
       
@@ -16,16 +16,31 @@ interface EventInfos {
     group_id: string;
     sender: User;
     desc: string;
-    option_a: string;
-    option_b: string;
+    occurrence: string;  // vide pour un événement unique
+    start_at: string;    // RFC 3339 dans le fuseau de l'événement
+    time_zone: string;
+    location: string;
+    capacity: number;    // 0 : illimité
+    cancelled: boolean;
     created_at: string;
-    choice: number;
-    count_a: number;
-    count_b: number;
-    date_time: string;   // format: dd/mm/yyyy
+    choice: "" | RSVPStatus;
+    waitlist_position: number;
+    count_going: number;
+    count_maybe: number;
+    count_not_going: number;
+    count_waitlist: number;
     title: string;
 }
 
+type RSVPStatus = "going" | "maybe" | "not_going" | "waitlist";
+type RSVPResponse = Exclude<RSVPStatus, "waitlist">;
+
+const RSVP_OPTIONS: { response: RSVPResponse; label: string }[] = [
+    { response: "going", label: "Je participe" },
+    { response: "maybe", label: "Peut-être" },
+    { response: "not_going", label: "Je ne participe pas" },
+];
+
 interface EventGroupProps {
     groupId: string;
 }
@@ -44,6 +59,24 @@ function formatDate(date: string | Date): string {
     return `${day}/${month}/${year}`;
 }
 
+function formatEventStart(date: string): string {
+    const d = new Date(date);
+    const hours = String(d.getHours()).padStart(2, '0');
+    const minutes = String(d.getMinutes()).padStart(2, '0');
+    return `${formatDate(d)} à ${hours}:${minutes}`;
+}
+
+function countFor(event: EventInfos, response: RSVPResponse): number {
+    switch (response) {
+        case "going":
+            return event.count_going;
+        case "maybe":
+            return event.count_maybe;
+        default:
+            return event.count_not_going;
+    }
+}
+
 function AvatarOrInitials({
                               image,
                               firstName,
@@ -76,10 +109,10 @@ function AvatarOrInitials({
 export function EventGroup({ groupId }: EventGroupProps) {
     const [events, setEvents] = useState<EventInfos[] | null>(null);
     const [desc, setDesc] = useState("");
-    const [optionA, setOptionA] = useState("");
-    const [optionB, setOptionB] = useState("");
+    const [location, setLocation] = useState("");
     const [eventTitle, setEventTitle] = useState("");
     const [eventDate, setEventDate] = useState("");
+    const [eventTime, setEventTime] = useState("");
     const [loading, setLoading] = useState(false);
     const [showForm, setShowForm] = useState(false);
 
@@ -99,15 +132,13 @@ export function EventGroup({ groupId }: EventGroupProps) {
         e.preventDefault();
         setLoading(true);
 
-        const formattedDate = formatDate(eventDate);
-
         const query = new URLSearchParams({
             groupId,
-            event: desc,
-            optionA,
-            optionB,
+            description: desc,
+            location,
             title: eventTitle,
-            date: formattedDate,
+            start: `${eventDate}T${eventTime}`,
+            timeZone: Intl.DateTimeFormat().resolvedOptions().timeZone,
         });
 
         try {
@@ -118,10 +149,10 @@ export function EventGroup({ groupId }: EventGroupProps) {
             if (!res.ok) throw new Error("Erreur lors de la création de l'événement");
 
             setDesc("");
-            setOptionA("");
-            setOptionB("");
+            setLocation("");
             setEventTitle("");
             setEventDate("");
+            setEventTime("");
             setShowForm(false);
             await fetchEvents();
         } catch (err) {
@@ -131,9 +162,16 @@ export function EventGroup({ groupId }: EventGroupProps) {
         }
     };
 
-    const handleVote = async (eventId: string, response: "A" | "B") => {
+    const handleVote = async (event: EventInfos, response: RSVPResponse) => {
+        const query = new URLSearchParams({
+            eventId: event.id,
+            groupId,
+            occurrence: event.occurrence,
+            response,
+        });
+
         try {
-            const res = await fetch(`/api/group/response?eventId=${eventId}&groupId=${groupId}&response=${response}`, {
+            const res = await fetch(`/api/group/response?${query.toString()}`, {
                 method: "POST",
             });
 
@@ -162,7 +200,7 @@ export function EventGroup({ groupId }: EventGroupProps) {
                     <div className="flex gap-4">
                         {events.map((event) => (
                             <div
-                                key={event.id}
+                                key={`${event.id}-${event.occurrence}`}
                                 className="min-w-[280px] bg-white border border-gray-300 rounded-xl shadow-sm p-4"
                             >
                                 <div className="flex items-center gap-3 mb-2">
@@ -180,48 +218,49 @@ export function EventGroup({ groupId }: EventGroupProps) {
                                 </div>
 
                                 <p className="text-black font-bold text-base mb-1">{event.title}</p>
-                                <p className="text-sm text-gray-700 mb-2">Date de l’événement : {event.date_time}</p>
+                                <p className="text-sm text-gray-700 mb-2">Date de l’événement : {formatEventStart(event.start_at)}</p>
+                                {event.location && (
+                                    <p className="text-sm text-gray-700 mb-2">Lieu : {event.location}</p>
+                                )}
                                 <p className="font-semibold text-lg text-black mb-4">{event.desc}</p>
 
-                                <div className="mb-3 flex flex-col gap-2">
-                                    <button
-                                        onClick={() => handleVote(event.id, "A")}
-                                        className={`flex items-center justify-between w-full px-4 py-2 rounded-full border text-sm font-medium transition cursor-pointer
-                                            ${event.choice === 1
-                                            ? 'bg-black text-white border-black'
-                                            : 'bg-white text-black border-gray-300 hover:bg-gray-100'}
-                                        `}
-                                    >
-                                        <div className="flex items-center gap-2">
-                                            {event.choice === 1 ? (
-                                                <span className="w-5 h-5 flex items-center justify-center rounded-full bg-white text-black text-xs font-bold">✔</span>
-                                            ) : (
-                                                <span className="w-5 h-5" />
-                                            )}
-                                            {event.option_a}
-                                        </div>
-                                        <span className={`text-xs ${event.choice === 1 ? 'text-white' : 'text-black'}`}>{event.count_a}</span>
-                                    </button>
-
-                                    <button
-                                        onClick={() => handleVote(event.id, "B")}
-                                        className={`flex items-center justify-between w-full px-4 py-2 rounded-full border text-sm font-medium transition cursor-pointer
-                                            ${event.choice === 2
-                                            ? 'bg-black text-white border-black'
-                                            : 'bg-white text-black border-gray-300 hover:bg-gray-100'}
-                                        `}
-                                    >
-                                        <div className="flex items-center gap-2">
-                                            {event.choice === 2 ? (
-                                                <span className="w-5 h-5 flex items-center justify-center rounded-full bg-white text-black text-xs font-bold">✔</span>
-                                            ) : (
-                                                <span className="w-5 h-5" />
-                                            )}
-                                            {event.option_b}
-                                        </div>
-                                        <span className={`text-xs ${event.choice === 2 ? 'text-white' : 'text-gray-500'}`}>{event.count_b}</span>
-                                    </button>
-                                </div>
+                                {event.cancelled ? (
+                                    <p className="text-sm font-semibold text-red-600">Événement annulé</p>
+                                ) : (
+                                    <div className="mb-3 flex flex-col gap-2">
+                                        {RSVP_OPTIONS.map(({ response, label }) => {
+                                            const selected = event.choice === response || (response === "going" && event.choice === "waitlist");
+                                            return (
+                                                <button
+                                                    key={response}
+                                                    onClick={() => handleVote(event, response)}
+                                                    className={`flex items-center justify-between w-full px-4 py-2 rounded-full border text-sm font-medium transition cursor-pointer
+                                                        ${selected
+                                                        ? 'bg-black text-white border-black'
+                                                        : 'bg-white text-black border-gray-300 hover:bg-gray-100'}
+                                                    `}
+                                                >
+                                                    <div className="flex items-center gap-2">
+                                                        {selected ? (
+                                                            <span className="w-5 h-5 flex items-center justify-center rounded-full bg-white text-black text-xs font-bold">✔</span>
+                                                        ) : (
+                                                            <span className="w-5 h-5" />
+                                                        )}
+                                                        {label}
+                                                    </div>
+                                                    <span className={`text-xs ${selected ? 'text-white' : 'text-gray-500'}`}>
+                                                        {countFor(event, response)}{response === "going" && event.capacity > 0 ? ` / ${event.capacity}` : ""}
+                                                    </span>
+                                                </button>
+                                            );
+                                        })}
+                                        {event.choice === "waitlist" && (
+                                            <p className="text-xs text-gray-600">
+                                                Complet : vous êtes en position {event.waitlist_position} sur la liste d’attente.
+                                            </p>
+                                        )}
+                                    </div>
+                                )}
                             </div>
                         ))}
                     </div>
@@ -260,36 +299,35 @@ export function EventGroup({ groupId }: EventGroupProps) {
                     </div>
 
                     <div>
-                        <label className="block font-medium text-black">Description</label>
+                        <label className="block font-medium text-black">Heure</label>
                         <input
                             required
-                            type="text"
-                            value={desc}
-                            onChange={(e) => setDesc(e.target.value)}
+                            type="time"
+                            value={eventTime}
+                            onChange={(e) => setEventTime(e.target.value)}
                             className="w-full border border-gray-300 p-2 rounded mt-1 bg-white text-black"
-                            placeholder="Ex: Vote pour choisir l’activité"
                         />
                     </div>
 
                     <div>
-                        <label className="block font-medium text-black">Option A</label>
+                        <label className="block font-medium text-black">Description</label>
                         <input
                             required
-                            value={optionA}
-                            onChange={(e) => setOptionA(e.target.value)}
+                            type="text"
+                            value={desc}
+                            onChange={(e) => setDesc(e.target.value)}
                             className="w-full border border-gray-300 p-2 rounded mt-1 bg-white text-black"
-                            placeholder="Ex: Film A"
+                            placeholder="Ex: Partie de bowling puis dîner"
                         />
                     </div>
 
                     <div>
-                        <label className="block font-medium text-black">Option B</label>
+                        <label className="block font-medium text-black">Lieu</label>
                         <input
-                            required
-                            value={optionB}
-                            onChange={(e) => setOptionB(e.target.value)}
+                            value={location}
+                            onChange={(e) => setLocation(e.target.value)}
                             className="w-full border border-gray-300 p-2 rounded mt-1 bg-white text-black"
-                            placeholder="Ex: Film B"
+                            placeholder="Ex: Bowling du centre-ville"
                         />
                     </div>
 
@@ -52,11 +52,15 @@ export interface EventGroupData {
   group_id: string;
   group_name: string;
   group_pic: string;
+  occurrence: string;
+  recurrence: string;
   title: string;
   description: string;
-  option_a: string;
-  option_b: string;
-  date_time: string;
+  start_at: string;
+  end_at: string;
+  time_zone: string;
+  location: string;
+  cancelled: boolean;
   created_at: string;
   user: User;
 }